@@ -0,0 +1,83 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains the audit log store for admin actions.
+//
+// Associated Frontend Files:
+//   - web/app/src/pages/AdminPage.tsx (admin audit trail)
+//
+// The gateway owns no database (ADR-0010): entries are kept in a bounded
+// in-process store for the admin endpoint and emitted on the "audit" logger
+// channel, which is the durable sink shipped by the log pipeline.
+package handlers
+
+import (
+	"sync"
+	"time"
+)
+
+// Audit outcomes
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeDenied  = "denied"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditEntry records who did what, when, from where, and how it ended
+type AuditEntry struct {
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	Action    string    `json:"action"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Outcome   string    `json:"outcome"`
+	Status    int       `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// AuditLogStore persists audit entries
+type AuditLogStore interface {
+	// Record stores an audit entry
+	Record(entry AuditEntry) error
+	// List returns a page of entries (newest first) and the total count
+	List(offset, limit int) ([]AuditEntry, int, error)
+}
+
+// MemoryAuditLogStore is an in-process AuditLogStore keeping the most recent entries
+type MemoryAuditLogStore struct {
+	mu         sync.RWMutex
+	maxEntries int
+	entries    []AuditEntry // oldest first
+}
+
+// NewMemoryAuditLogStore creates a MemoryAuditLogStore retaining maxEntries entries
+func NewMemoryAuditLogStore(maxEntries int) *MemoryAuditLogStore {
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	return &MemoryAuditLogStore{maxEntries: maxEntries}
+}
+
+// Record stores an entry, dropping the oldest beyond the retention limit
+func (s *MemoryAuditLogStore) Record(entry AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = append(s.entries, entry)
+	if len(s.entries) > s.maxEntries {
+		s.entries = s.entries[len(s.entries)-s.maxEntries:]
+	}
+	return nil
+}
+
+// List returns a page of entries, newest first
+func (s *MemoryAuditLogStore) List(offset, limit int) ([]AuditEntry, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	total := len(s.entries)
+	page := make([]AuditEntry, 0, limit)
+	for i := total - 1 - offset; i >= 0 && len(page) < limit; i-- {
+		page = append(page, s.entries[i])
+	}
+	return page, total, nil
+}
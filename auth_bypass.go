@@ -0,0 +1,79 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file implements the auth-bypass path allowlist, letting selected
+// paths under a protected route group (e.g. a backend's own health check or
+// public assets) skip token validation.
+//
+// Associated Frontend Files:
+//   - None (public endpoints are called without credentials)
+package handlers
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthBypass wraps an auth middleware so requests whose path matches one of
+// patterns skip it, while every other path still requires auth. Patterns are:
+//   - exact paths: "/api/v1/reports/health"
+//   - prefixes ending in "/**", matching the path itself and everything below:
+//     "/api/v1/reports/assets/**"
+//   - globs in path.Match syntax, where "*" stays within one segment:
+//     "/api/v1/*/health"
+//
+// Paths that are not in clean form (e.g. containing "..", "//" or a trailing
+// slash) are never bypassed, so they cannot smuggle a protected path past the
+// allowlist.
+func AuthBypass(patterns []string, auth gin.HandlerFunc) (gin.HandlerFunc, error) {
+	var exact []string
+	var prefixes []string
+	var globs []string
+	for _, pattern := range patterns {
+		switch {
+		case !strings.HasPrefix(pattern, "/"):
+			return nil, fmt.Errorf("auth bypass pattern %q must start with /", pattern)
+		case strings.HasSuffix(pattern, "/**"):
+			prefixes = append(prefixes, strings.TrimSuffix(pattern, "/**"))
+		case strings.ContainsAny(pattern, "*?["):
+			if _, err := path.Match(pattern, "/"); err != nil {
+				return nil, fmt.Errorf("auth bypass pattern %q: %w", pattern, err)
+			}
+			globs = append(globs, pattern)
+		default:
+			exact = append(exact, pattern)
+		}
+	}
+
+	bypassed := func(requestPath string) bool {
+		if path.Clean(requestPath) != requestPath {
+			return false
+		}
+		for _, p := range exact {
+			if requestPath == p {
+				return true
+			}
+		}
+		for _, prefix := range prefixes {
+			if requestPath == prefix || strings.HasPrefix(requestPath, prefix+"/") {
+				return true
+			}
+		}
+		for _, glob := range globs {
+			if matched, _ := path.Match(glob, requestPath); matched {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(c *gin.Context) {
+		if bypassed(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		auth(c)
+	}, nil
+}
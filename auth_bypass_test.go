@@ -0,0 +1,83 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugjb/api-gateway/handlers"
+)
+
+// requireBearer stands in for the gateway auth middleware
+func requireBearer(c *gin.Context) {
+	if c.GetHeader("Authorization") == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": gin.H{"code": "UNAUTHORIZED", "message": "Authentication required"},
+		})
+		return
+	}
+	c.Next()
+}
+
+// TestAuthBypass verifies allowlisted paths skip auth while their siblings still require it
+func TestAuthBypass(t *testing.T) {
+	auth, err := handlers.AuthBypass([]string{
+		"/api/v1/reports/health",
+		"/api/v1/reports/assets/**",
+		"/api/v1/*/status",
+	}, requireBearer)
+	if err != nil {
+		t.Fatalf("Failed to create auth bypass: %v", err)
+	}
+
+	router := gin.New()
+	protected := router.Group("/api/v1", auth)
+	protected.Any("/*path", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		path     string
+		expected int
+	}{
+		{"/api/v1/reports/health", http.StatusOK},
+		{"/api/v1/reports/health/details", http.StatusUnauthorized},
+		{"/api/v1/reports/summary", http.StatusUnauthorized},
+		{"/api/v1/reports/assets", http.StatusOK},
+		{"/api/v1/reports/assets/css/app.css", http.StatusOK},
+		{"/api/v1/reports/assetsx/app.css", http.StatusUnauthorized},
+		{"/api/v1/tasks/status", http.StatusOK},
+		{"/api/v1/tasks/1/status", http.StatusUnauthorized},
+		{"/api/v1/reports/assets/../summary", http.StatusUnauthorized},
+		{"/api/v1/reports/health/", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.URL.Path = tt.path
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+
+	// Bypassed or not, a valid token is still accepted
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/reports/summary", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d with a token, got %d", http.StatusOK, w.Code)
+	}
+}
+
+// TestAuthBypassInvalidPattern verifies malformed patterns are rejected at startup
+func TestAuthBypassInvalidPattern(t *testing.T) {
+	for _, pattern := range []string{"api/v1/health", "/api/v1/[health"} {
+		if _, err := handlers.AuthBypass([]string{pattern}, requireBearer); err == nil {
+			t.Errorf("Expected error for pattern %q", pattern)
+		}
+	}
+}
@@ -33,12 +33,20 @@ package handlers
 //
 // - validateCredentials() - REMOVED: Gateway must not access user database
 //   -> Use: Authelia manages users in its own configuration
+//   -> There is no DB/config-user fallback to degrade here. Break-glass admin
+//      access during a user-store outage must be provided by Authelia (e.g. a
+//      file-based users backend), not by gateway-side credential checks.
+//      A DB outage surfaces as an Authelia error (502 AUTH_SERVICE_ERROR).
 //
 // - generateToken() - REMOVED: Gateway must not issue tokens
 //   -> Use: Authelia issues session cookies
 //
 // - determineRoles() - REMOVED: Gateway must not manage roles
 //   -> Use: Authelia returns Remote-Groups header
+//   -> The former hardcoded email patterns ("hr@" -> hr_manager,
+//      "pm@"/"project" -> project_manager) and admin emails must be
+//      expressed as Authelia user groups, not as gateway role rules.
+//      A config-driven role rule engine is intentionally not provided here.
 //
 // - updateUserPassword() - REMOVED: Gateway must not access database (ADR-0010)
 //   -> Use: Authelia /api/user/info for password changes
@@ -46,48 +54,75 @@ package handlers
 // See ADR-0010: Reverse Proxy Gateway for External Integration
 // The gateway should NOT access database directly.
 
+import (
+	"strings"
+	"unicode"
+)
+
 // extractNameFromEmail extracts a formatted name from an email address
+// (e.g., "john.doe+news@example.com" -> "John Doe", "mary-jane@example.com" -> "Mary Jane").
+// Plus-addressing tags are dropped, dots, underscores and hyphens separate
+// words, digits are kept. Returns the input unchanged if it has no '@'.
 // This is a utility function that doesn't require Authelia
 func extractNameFromEmail(email string) string {
-	// Extract name from email (e.g., "john.doe@example.com" -> "John Doe")
-	atIndex := -1
-	for i, c := range email {
-		if c == '@' {
-			atIndex = i
-			break
-		}
-	}
+	atIndex := strings.Index(email, "@")
 	if atIndex == -1 {
 		return email
 	}
 
-	name := email[:atIndex]
-	// Replace dots and underscores with spaces, capitalize words
-	result := make([]byte, 0, len(name))
+	local := email[:atIndex]
+	if plusIndex := strings.Index(local, "+"); plusIndex != -1 {
+		local = local[:plusIndex]
+	}
+
+	words := strings.FieldsFunc(local, func(r rune) bool {
+		return r == '.' || r == '_' || r == '-'
+	})
+	for i, word := range words {
+		words[i] = titleCaseWord(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// titleCaseWord capitalizes the first letter of a word and letters following digits
+// (e.g., "agent007bond" -> "Agent007Bond"). All-lowercase and all-uppercase words
+// are normalized; mixed-case words (e.g., "McDonald") keep their inner capitals.
+func titleCaseWord(word string) string {
+	mixedCase := word != strings.ToLower(word) && word != strings.ToUpper(word)
+
+	runes := []rune(word)
 	capitalizeNext := true
-	for _, c := range name {
-		if c == '.' || c == '_' || c == '-' {
-			result = append(result, ' ')
+	for i, r := range runes {
+		switch {
+		case unicode.IsDigit(r):
 			capitalizeNext = true
-		} else if capitalizeNext && c >= 'a' && c <= 'z' {
-			result = append(result, byte(c-32)) // Uppercase
-			capitalizeNext = false
-		} else {
-			result = append(result, byte(c))
+		case capitalizeNext:
+			runes[i] = unicode.ToUpper(r)
 			capitalizeNext = false
+		case !mixedCase:
+			runes[i] = unicode.ToLower(r)
 		}
 	}
-	return string(result)
+	return string(runes)
 }
 
 // containsAny checks if string s contains any of the substrings
+// Matching is case-insensitive unless caseSensitive is set (emails compare case-insensitively).
+// Empty substrings never match, so an empty pattern cannot match every input.
 // This is a utility function that doesn't require Authelia
-func containsAny(s string, substrs []string) bool {
+func containsAny(s string, substrs []string, caseSensitive bool) bool {
+	if !caseSensitive {
+		s = strings.ToLower(s)
+	}
 	for _, substr := range substrs {
-		for i := 0; i <= len(s)-len(substr); i++ {
-			if s[i:i+len(substr)] == substr {
-				return true
-			}
+		if substr == "" {
+			continue
+		}
+		if !caseSensitive {
+			substr = strings.ToLower(substr)
+		}
+		if strings.Contains(s, substr) {
+			return true
 		}
 	}
 	return false
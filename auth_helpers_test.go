@@ -0,0 +1,67 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/ugjb/api-gateway/handlers"
+)
+
+// TestContainsAny verifies substring matching including case and empty patterns
+func TestContainsAny(t *testing.T) {
+	tests := []struct {
+		name          string
+		s             string
+		substrs       []string
+		caseSensitive bool
+		expected      bool
+	}{
+		{"match", "hr@ugjb.com", []string{"pm@", "hr@"}, false, true},
+		{"no match", "dev@ugjb.com", []string{"pm@", "hr@"}, false, false},
+		{"case-insensitive match", "HR@UGJB.COM", []string{"hr@"}, false, true},
+		{"case-sensitive mismatch", "HR@UGJB.COM", []string{"hr@"}, true, false},
+		{"case-sensitive match", "hr@ugjb.com", []string{"hr@"}, true, true},
+		{"multibyte input", "nguyễn.văn@ugjb.com", []string{"VĂN"}, false, true},
+		{"empty substring never matches", "dev@ugjb.com", []string{""}, false, false},
+		{"empty input", "", []string{"", "hr@"}, false, false},
+		{"no substrings", "hr@ugjb.com", nil, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := handlers.ContainsAny(tt.s, tt.substrs, tt.caseSensitive); got != tt.expected {
+				t.Errorf("ContainsAny(%q, %q, %v) = %v, expected %v", tt.s, tt.substrs, tt.caseSensitive, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestExtractNameFromEmail verifies display name extraction from email addresses
+func TestExtractNameFromEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		expected string
+	}{
+		{"dotted", "john.doe@example.com", "John Doe"},
+		{"underscore", "john_doe@example.com", "John Doe"},
+		{"plus-addressing", "john.doe+test@example.com", "John Doe"},
+		{"plus-addressing with separators", "jane+news.letter@example.com", "Jane"},
+		{"all caps", "JOHN.DOE@example.com", "John Doe"},
+		{"mixed case preserved", "ronald.McDonald@example.com", "Ronald McDonald"},
+		{"trailing digits", "user2@example.com", "User2"},
+		{"letters after digits", "agent007bond@example.com", "Agent007Bond"},
+		{"leading digits", "3d.artist@example.com", "3D Artist"},
+		{"hyphenated", "mary-jane@example.com", "Mary Jane"},
+		{"hyphenated and dotted", "mary-jane.watson@example.com", "Mary Jane Watson"},
+		{"unicode", "nguyễn.văn@example.com", "Nguyễn Văn"},
+		{"no at sign", "not-an-email", "not-an-email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := handlers.ExtractNameFromEmail(tt.email); got != tt.expected {
+				t.Errorf("ExtractNameFromEmail(%q) = %q, expected %q", tt.email, got, tt.expected)
+			}
+		})
+	}
+}
@@ -19,10 +19,12 @@ type LoginRequest struct {
 }
 
 // LoginResponse represents the login response
+// Token and ExpiresAt are omitted in cookie-only mode (AutheliaOptions.CookieOnly)
 type LoginResponse struct {
-	Token     string    `json:"token"`
-	ExpiresAt time.Time `json:"expires_at"`
-	User      UserInfo  `json:"user"`
+	Token     string     `json:"token,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	User      UserInfo   `json:"user"`
+	Redirect  string     `json:"redirect,omitempty"`
 }
 
 // UserInfo represents user information in the response
@@ -16,20 +16,41 @@
 //   - POST /api/v1/auth/login -> Authelia /api/firstfactor
 //   - POST /api/v1/auth/logout -> Authelia /api/logout
 //   - GET /api/v1/auth/session -> Authelia /api/user/info
+//   - POST /api/v1/auth/refresh -> Authelia /api/user/info and /api/state
+//   - POST /api/v1/auth/totp -> Authelia /api/secondfactor/totp
+//   - POST /api/v1/auth/webauthn/start -> Authelia /api/secondfactor/webauthn/identity/start
+//   - GET /api/v1/auth/webauthn/assertion -> Authelia /api/secondfactor/webauthn/assertion
+//   - POST /api/v1/auth/webauthn/assertion -> Authelia /api/secondfactor/webauthn/assertion
+//
+// Gateway-local routes (no Authelia call):
+//   - GET /api/v1/auth/sessions -> list the user's active gateway sessions
+//   - DELETE /api/v1/auth/sessions/:id -> revoke a gateway session
+//   - GET /api/v1/auth/login-history -> the user's recent login attempts
+//   - GET /api/v1/auth/validate-email -> email format and availability pre-check
 //
 // Related files:
 //   - authelia_types.go: Type definitions for requests/responses
 //   - authelia_helpers.go: Helper functions for responses and cookies
 //   - authelia_login.go: Login handler implementation
 //   - authelia_logout.go: Logout handler implementation
+//   - authelia_second_factor.go: Second factor (TOTP) handlers
+//   - authelia_webauthn.go: Second factor (WebAuthn/passkey) passthrough handlers
+//   - authelia_redirect.go: Redirect target validation (open redirect protection)
+//   - authelia_sessions.go: Gateway session listing, revocation and token validation
+//   - authelia_login_history.go: Login attempt recording and history endpoint
+//   - authelia_websocket_auth.go: Query parameter JWT authentication for WebSocket upgrades
+//   - authelia_sliding_session.go: Refresh of gateway JWTs close to expiry
+//   - authelia_validate_email.go: Email format and availability pre-check
+//   - authelia_refresh.go: Gateway JWT renewal from the Authelia session
 //
 // See: agent/docs/network-topology/api-gateway-topology.mmd
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
-	"io"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -39,20 +60,115 @@ import (
 
 // AutheliaHandler handles authentication requests by proxying to internal Authelia
 type AutheliaHandler struct {
-	config *config.Config
-	logger *zap.Logger
-	client *http.Client
+	config  *config.Config
+	logger  *zap.Logger
+	client  *http.Client
+	options AutheliaOptions
+	// autheliaURL is the normalized Authelia.InternalURL; nil when it is
+	// invalid, in which case autheliaURLErr fails every Authelia call
+	autheliaURL    *url.URL
+	autheliaURLErr error
+}
+
+// AutheliaOptions configures gateway-side behavior of the Authelia handlers
+type AutheliaOptions struct {
+	// AllowedRedirectDomains lists hosts that login redirects may point to.
+	// An entry also allows its subdomains (e.g. "example.com" allows "app.example.com").
+	// Relative paths are always allowed.
+	AllowedRedirectDomains []string
+	// DefaultRedirectURL replaces disallowed redirect targets (empty means no redirect)
+	DefaultRedirectURL string
+	// PostLogoutRedirectURL tells the frontend where to send the user after logout
+	PostLogoutRedirectURL string
+	// Sessions tracks issued gateway JWTs so users can list and revoke them (nil disables tracking)
+	Sessions SessionStore
+	// LoginHistory records login attempts for auditing (nil disables recording)
+	LoginHistory LoginHistoryStore
+	// MaxJSONBodyBytes caps JSON request bodies (0 uses the 1 MiB default)
+	MaxJSONBodyBytes int64
+	// DisallowUnknownJSONFields rejects request bodies containing unexpected fields
+	DisallowUnknownJSONFields bool
+	// Metrics records auth request outcomes and latency (nil disables metrics)
+	Metrics *GatewayMetrics
+	// RequireSecondFactor withholds the JWT after login until a second factor succeeds
+	RequireSecondFactor bool
+	// NormalizeLoginResponse returns LoginResponse (token, expires_at, user, redirect)
+	// from every login-completing handler instead of the legacy Authelia shape
+	NormalizeLoginResponse bool
+	// CookieOnly relies on the Authelia session cookie alone: no gateway JWT is issued
+	CookieOnly bool
+	// WebSocketTokenParam is the query parameter WebSocketQueryAuth reads the JWT from (empty disables)
+	WebSocketTokenParam string
+	// RefreshWindow lets SlidingSession replace tokens expiring within this window (0 disables)
+	RefreshWindow time.Duration
+	// RefreshTokenCookie also sets refreshed tokens in a cookie of this name (empty disables)
+	RefreshTokenCookie string
+	// Accounts answers email availability for ValidateEmail (nil reports every
+	// well-formed email as available)
+	Accounts AccountDirectory
+	// ValidateEmailLimiter caps ValidateEmail lookups per client IP; over the limit
+	// availability is not revealed (nil disables the limit)
+	ValidateEmailLimiter *RateLimiter
+	// SessionCookie sets the attributes of the Authelia session cookie sent to clients
+	SessionCookie SessionCookieOptions
+	// LoginChallenge requires a CAPTCHA on Login after repeated failures (nil disables)
+	LoginChallenge *LoginChallengeConfig
+}
+
+// SessionCookieOptions configures the forwarded Authelia session cookie
+type SessionCookieOptions struct {
+	// SameSite mode; http.SameSiteNoneMode (cross-site SPA) always implies Secure
+	SameSite http.SameSite
+	// Path scopes the cookie (empty means "/")
+	Path string
+	// Secure forces the Secure flag; otherwise it is set only for TLS requests
+	Secure bool
+	// HTTPOnly hides the cookie from JavaScript
+	HTTPOnly bool
 }
 
-// NewAutheliaHandler creates a new AutheliaHandler
+// DefaultAutheliaOptions returns the options used by NewAutheliaHandler
+func DefaultAutheliaOptions() AutheliaOptions {
+	return AutheliaOptions{
+		DefaultRedirectURL:    "/",
+		PostLogoutRedirectURL: "/login",
+		Sessions:              NewMemorySessionStore(),
+		LoginHistory:          NewMemoryLoginHistoryStore(100),
+		MaxJSONBodyBytes:      64 << 10,
+		WebSocketTokenParam:   "access_token",
+		SessionCookie: SessionCookieOptions{
+			SameSite: http.SameSiteLaxMode,
+			Path:     "/",
+			HTTPOnly: true,
+		},
+	}
+}
+
+// NewAutheliaHandler creates a new AutheliaHandler with default options
 func NewAutheliaHandler(cfg *config.Config, logger *zap.Logger) *AutheliaHandler {
-	return &AutheliaHandler{
+	return NewAutheliaHandlerWithOptions(cfg, logger, DefaultAutheliaOptions())
+}
+
+// NewAutheliaHandlerWithOptions creates a new AutheliaHandler with the given options
+// An invalid Authelia.InternalURL is logged here; Authelia calls then fail with 500
+// instead of reaching a malformed URL.
+func NewAutheliaHandlerWithOptions(cfg *config.Config, logger *zap.Logger, opts AutheliaOptions) *AutheliaHandler {
+	h := &AutheliaHandler{
 		config: cfg,
 		logger: logger,
 		client: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		options: opts,
+	}
+	if opts.LoginChallenge != nil {
+		h.options.LoginChallenge = opts.LoginChallenge.normalize()
+	}
+	h.autheliaURL, h.autheliaURLErr = parseAutheliaURL(cfg.Authelia.InternalURL)
+	if h.autheliaURLErr != nil {
+		logger.Error("Invalid Authelia internal URL", zap.Error(h.autheliaURLErr))
 	}
+	return h
 }
 
 // GetSession returns the current user's session information
@@ -66,11 +182,12 @@ func NewAutheliaHandler(cfg *config.Config, logger *zap.Logger) *AutheliaHandler
 // @Failure 502 {object} map[string]interface{} "Auth service unavailable"
 // @Router /api/v1/auth/session [get]
 func (h *AutheliaHandler) GetSession(c *gin.Context) {
+	defer h.options.Metrics.observeAuth(c, "session", time.Now())
+
 	// Call Authelia /api/user/info (internal network only)
-	autheliaURL := h.config.Authelia.InternalURL + "/api/user/info"
-	proxyReq, err := http.NewRequestWithContext(c.Request.Context(), "GET", autheliaURL, nil)
+	proxyReq, err := h.newAutheliaRequest(c, http.MethodGet, "/api/user/info", nil)
 	if err != nil {
-		h.logger.Error("Failed to create Authelia session request", zap.Error(err))
+		h.logger.Error("Failed to create Authelia session request", logFields(c, zap.Error(err))...)
 		sendInternalError(c)
 		return
 	}
@@ -88,16 +205,16 @@ func (h *AutheliaHandler) GetSession(c *gin.Context) {
 
 	resp, err := h.client.Do(proxyReq)
 	if err != nil {
-		h.logger.Error("Authelia session request failed", zap.Error(err))
+		h.logger.Error("Authelia session request failed", logFields(c, zap.Error(err))...)
 		sendBadGatewayError(c)
 		return
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readAutheliaResponse(resp)
 	if err != nil {
-		h.logger.Error("Failed to read Authelia session response", zap.Error(err))
-		sendInternalError(c)
+		h.logger.Error("Failed to read Authelia session response", logFields(c, zap.Error(err))...)
+		sendInvalidAuthResponseError(c)
 		return
 	}
 
@@ -106,11 +223,17 @@ func (h *AutheliaHandler) GetSession(c *gin.Context) {
 		return
 	}
 
+	if len(bytes.TrimSpace(body)) == 0 {
+		h.logger.Error("Empty Authelia session response", logFields(c)...)
+		sendInvalidAuthResponseError(c)
+		return
+	}
+
 	// Parse and forward Authelia response
 	var userInfo map[string]interface{}
 	if err := json.Unmarshal(body, &userInfo); err != nil {
-		h.logger.Error("Failed to parse Authelia session response", zap.Error(err))
-		sendInternalError(c)
+		h.logger.Error("Failed to parse Authelia session response", logFields(c, zap.Error(err))...)
+		sendInvalidAuthResponseError(c)
 		return
 	}
 
@@ -0,0 +1,161 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file implements the CAPTCHA challenge required by Login after repeated
+// failed attempts.
+//
+// Associated Frontend Files:
+//   - web/app/src/pages/LoginPage.tsx (shows the CAPTCHA on CAPTCHA_REQUIRED)
+//   - web/app/src/hooks/useAuth.ts (login function - sends captcha_token)
+//
+// The challenge is a soft step below Authelia's regulation: once an email or
+// client IP reaches LoginChallengeConfig.Threshold failures within Window,
+// logins must carry a captcha_token accepted by the ChallengeVerifier.
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Defaults for unset LoginChallengeConfig fields
+const (
+	defaultChallengeWindow     = 15 * time.Minute
+	defaultChallengeStoreItems = 10000
+)
+
+// ChallengeVerifier verifies CAPTCHA tokens (e.g. with hCaptcha or Turnstile)
+type ChallengeVerifier interface {
+	// VerifyChallenge reports whether token is a valid solved challenge for remoteIP
+	VerifyChallenge(ctx context.Context, token, remoteIP string) (bool, error)
+}
+
+// NoopChallengeVerifier accepts every non-empty token; it is the default until
+// a real CAPTCHA provider is configured
+type NoopChallengeVerifier struct{}
+
+// VerifyChallenge accepts any token
+func (NoopChallengeVerifier) VerifyChallenge(context.Context, string, string) (bool, error) {
+	return true, nil
+}
+
+// LoginChallengeConfig configures the CAPTCHA challenge of Login
+type LoginChallengeConfig struct {
+	// Threshold is the number of failed logins per email or client IP within
+	// Window after which a captcha_token is required
+	Threshold int
+	// Window is the period failures are counted over (default 15m)
+	Window time.Duration
+	// Verifier checks captcha tokens (nil uses NoopChallengeVerifier)
+	Verifier ChallengeVerifier
+	// Store holds the failure counters (nil uses an in-memory store)
+	Store Store
+}
+
+// normalize fills in defaults for unset fields
+func (cfg LoginChallengeConfig) normalize() *LoginChallengeConfig {
+	if cfg.Window <= 0 {
+		cfg.Window = defaultChallengeWindow
+	}
+	if cfg.Verifier == nil {
+		cfg.Verifier = NoopChallengeVerifier{}
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore(defaultChallengeStoreItems)
+	}
+	return &cfg
+}
+
+// loginFailureKeys returns the failure counter keys of the email and the client IP
+func loginFailureKeys(c *gin.Context, email string) []string {
+	return []string{
+		"login-failures:email:" + strings.ToLower(email),
+		"login-failures:ip:" + RealClientIP(c),
+	}
+}
+
+// checkLoginChallenge enforces the CAPTCHA once the failure threshold is
+// reached. Returns false if an error response was sent. Store errors fail
+// open, like RateLimit.
+func (h *AutheliaHandler) checkLoginChallenge(c *gin.Context, req *AutheliaLoginRequest) bool {
+	challenge := h.options.LoginChallenge
+	if challenge == nil || challenge.Threshold <= 0 {
+		return true
+	}
+
+	required := false
+	for _, key := range loginFailureKeys(c, req.Email) {
+		value, ok, err := challenge.Store.Get(c.Request.Context(), key)
+		if err != nil {
+			h.logger.Error("Failed to read login failure count", logFields(c, zap.Error(err))...)
+			continue
+		}
+		if count, _ := strconv.Atoi(string(value)); ok && count >= challenge.Threshold {
+			required = true
+		}
+	}
+	if !required {
+		return true
+	}
+
+	if req.CaptchaToken == "" {
+		sendCaptchaError(c, "CAPTCHA_REQUIRED", "Complete the CAPTCHA to continue")
+		return false
+	}
+	valid, err := challenge.Verifier.VerifyChallenge(c.Request.Context(), req.CaptchaToken, RealClientIP(c))
+	if err != nil {
+		h.logger.Error("CAPTCHA verification failed", logFields(c, zap.Error(err))...)
+		sendBadGatewayError(c)
+		return false
+	}
+	if !valid {
+		h.logger.Warn("Invalid CAPTCHA token", logFields(c, zap.String("email", req.Email))...)
+		sendCaptchaError(c, "CAPTCHA_INVALID", "CAPTCHA verification failed")
+		return false
+	}
+	return true
+}
+
+// recordLoginFailure counts a failed login against the email and the client IP
+func (h *AutheliaHandler) recordLoginFailure(c *gin.Context, email string) {
+	challenge := h.options.LoginChallenge
+	if challenge == nil || challenge.Threshold <= 0 {
+		return
+	}
+	for _, key := range loginFailureKeys(c, email) {
+		count, err := challenge.Store.Incr(c.Request.Context(), key)
+		if err == nil && count == 1 {
+			err = challenge.Store.Expire(c.Request.Context(), key, challenge.Window)
+		}
+		if err != nil {
+			h.logger.Error("Failed to record login failure", logFields(c, zap.Error(err))...)
+		}
+	}
+}
+
+// resetLoginFailures clears the email's failure count after a correct password
+func (h *AutheliaHandler) resetLoginFailures(c *gin.Context, email string) {
+	challenge := h.options.LoginChallenge
+	if challenge == nil || challenge.Threshold <= 0 {
+		return
+	}
+	key := loginFailureKeys(c, email)[0]
+	if err := challenge.Store.Set(c.Request.Context(), key, []byte("0"), challenge.Window); err != nil {
+		h.logger.Error("Failed to reset login failures", logFields(c, zap.Error(err))...)
+	}
+}
+
+// sendCaptchaError sends the 428 asking the client to (re)solve the CAPTCHA
+func sendCaptchaError(c *gin.Context, code, message string) {
+	c.JSON(http.StatusPreconditionRequired, gin.H{
+		"error": gin.H{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
@@ -13,34 +13,121 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 // clearSessionCookie clears the Authelia session cookie
 func (h *AutheliaHandler) clearSessionCookie(c *gin.Context) {
-	http.SetCookie(c.Writer, &http.Cookie{
-		Name:     h.config.Authelia.SessionCookieName,
-		Value:    "",
-		Path:     "/",
-		Domain:   h.config.Authelia.SessionDomain,
-		MaxAge:   -1,
-		HttpOnly: true,
-		Secure:   c.Request.TLS != nil,
-		SameSite: http.SameSiteLaxMode,
-	})
+	cookie := &http.Cookie{
+		Name:   h.config.Authelia.SessionCookieName,
+		Value:  "",
+		MaxAge: -1,
+	}
+	h.applySessionCookieAttributes(c, cookie)
+	http.SetCookie(c.Writer, cookie)
 }
 
-// getScheme determines the request scheme (http/https)
-func getScheme(c *gin.Context) string {
-	if c.Request.TLS != nil {
-		return "https"
+// forwardSessionCookies copies Authelia's Set-Cookie headers to the client,
+// applying the gateway's attributes to the session cookie
+func (h *AutheliaHandler) forwardSessionCookies(c *gin.Context, resp *http.Response) {
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == h.config.Authelia.SessionCookieName {
+			h.applySessionCookieAttributes(c, cookie)
+		}
+		http.SetCookie(c.Writer, cookie)
+	}
+}
+
+// applySessionCookieAttributes sets the configured domain, path and flags on the session cookie
+func (h *AutheliaHandler) applySessionCookieAttributes(c *gin.Context, cookie *http.Cookie) {
+	opts := h.options.SessionCookie
+
+	cookie.Domain = h.config.Authelia.SessionDomain
+	cookie.Path = opts.Path
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+	cookie.HttpOnly = opts.HTTPOnly
+	cookie.SameSite = opts.SameSite
+	if cookie.SameSite == 0 {
+		cookie.SameSite = http.SameSiteLaxMode
 	}
-	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
-		return proto
+	// Browsers reject SameSite=None cookies without Secure
+	cookie.Secure = opts.Secure || cookie.SameSite == http.SameSiteNoneMode || c.Request.TLS != nil
+}
+
+// usernameFromEmail extracts the Authelia username from an email (e.g., admin@ugjb.com -> admin)
+func usernameFromEmail(email string) string {
+	if idx := strings.Index(email, "@"); idx > 0 {
+		return email[:idx]
 	}
-	return "http"
+	return email
+}
+
+// jsonBodyOptions returns the JSON decoding limits for Authelia handlers
+func (h *AutheliaHandler) jsonBodyOptions() jsonBodyOptions {
+	return jsonBodyOptions{
+		MaxBytes:              h.options.MaxJSONBodyBytes,
+		DisallowUnknownFields: h.options.DisallowUnknownJSONFields,
+	}
+}
+
+// parseAutheliaURL parses the configured Authelia.InternalURL, requiring an
+// http(s) scheme and a host. A trailing slash is trimmed so endpoint paths join cleanly.
+func parseAutheliaURL(raw string) (*url.URL, error) {
+	if err := validateServiceURL(raw); err != nil {
+		return nil, fmt.Errorf("Authelia.InternalURL: %w", err)
+	}
+	u, _ := url.Parse(raw)
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	return u, nil
+}
+
+// newAutheliaRequest builds a request to internal Authelia for the client
+// request c, carrying its X-Request-ID (generated if absent) for correlation
+func (h *AutheliaHandler) newAutheliaRequest(c *gin.Context, method, path string, body io.Reader) (*http.Request, error) {
+	if h.autheliaURLErr != nil {
+		return nil, h.autheliaURLErr
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), method, h.autheliaURL.JoinPath(path).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(HeaderRequestID, ensureRequestID(c))
+	return req, nil
+}
+
+// maxAutheliaResponseBytes caps how much of an Authelia response body is read (1 MiB)
+const maxAutheliaResponseBytes = 1 << 20
+
+// errAutheliaResponseTooLarge is returned by readAutheliaResponse for bodies over maxAutheliaResponseBytes
+var errAutheliaResponseTooLarge = errors.New("authelia response body too large")
+
+// readAutheliaResponse reads an Authelia response body, capped at maxAutheliaResponseBytes
+func readAutheliaResponse(resp *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxAutheliaResponseBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxAutheliaResponseBytes {
+		return nil, errAutheliaResponseTooLarge
+	}
+	return body, nil
+}
+
+// getScheme determines the request scheme (http/https); X-Forwarded-Proto is
+// only honored from trusted proxies (see forwardedProto)
+func getScheme(c *gin.Context) string {
+	return forwardedProto(c)
 }
 
 // sendInternalError sends a standardized internal error response
@@ -73,6 +160,17 @@ func sendBadGatewayError(c *gin.Context) {
 	})
 }
 
+// sendInvalidAuthResponseError sends a standardized error for an empty, oversized
+// or unparsable Authelia response
+func sendInvalidAuthResponseError(c *gin.Context) {
+	c.JSON(http.StatusBadGateway, gin.H{
+		"error": gin.H{
+			"code":    "AUTH_SERVICE_ERROR",
+			"message": "Invalid response from authentication service",
+		},
+	})
+}
+
 // sendInvalidCredentialsError sends a standardized invalid credentials error response
 func sendInvalidCredentialsError(c *gin.Context) {
 	c.JSON(http.StatusUnauthorized, gin.H{
@@ -102,3 +200,26 @@ func sendAuthServiceError(c *gin.Context) {
 		},
 	})
 }
+
+// sendRateLimitedError sends a 429 response, propagating Authelia's Retry-After (seconds) when present
+func sendRateLimitedError(c *gin.Context, retryAfter string) {
+	errBody := gin.H{
+		"code":    "RATE_LIMITED",
+		"message": "Too many attempts, please retry later",
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(retryAfter)); err == nil && seconds >= 0 {
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		errBody["retry_after"] = seconds
+	}
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": errBody})
+}
+
+// sendAccountBannedError sends a standardized account banned error response
+func sendAccountBannedError(c *gin.Context) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error": gin.H{
+			"code":    "ACCOUNT_BANNED",
+			"message": "Account temporarily locked after too many failed attempts",
+		},
+	})
+}
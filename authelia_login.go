@@ -16,9 +16,7 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
-	"io"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -36,21 +34,37 @@ import (
 // @Success 200 {object} AutheliaLoginResponse "Successful authentication"
 // @Failure 400 {object} map[string]interface{} "Invalid request body"
 // @Failure 401 {object} map[string]interface{} "Invalid credentials"
+// @Failure 403 {object} map[string]interface{} "Account banned"
+// @Failure 413 {object} map[string]interface{} "Request body too large"
+// @Failure 415 {object} map[string]interface{} "Unsupported media type"
+// @Failure 428 {object} map[string]interface{} "CAPTCHA required or invalid"
+// @Failure 429 {object} map[string]interface{} "Too many attempts"
 // @Failure 502 {object} map[string]interface{} "Auth service unavailable"
 // @Router /api/v1/auth/login [post]
 func (h *AutheliaHandler) Login(c *gin.Context) {
+	defer h.options.Metrics.observeAuth(c, "login", time.Now())
+
 	var req AutheliaLoginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := decodeJSONBody(c, &req, h.jsonBodyOptions()); err != nil {
 		h.logger.Warn("Invalid login request", zap.Error(err))
-		sendInvalidRequestError(c)
+		sendJSONBodyError(c, err)
+		return
+	}
+
+	if !h.checkLoginChallenge(c, &req) {
 		return
 	}
 
-	// Extract username from email (e.g., admin@ugjb.com -> admin)
 	// Authelia uses username, not email, for authentication
-	username := req.Email
-	if idx := strings.Index(req.Email, "@"); idx > 0 {
-		username = req.Email[:idx]
+	username := usernameFromEmail(req.Email)
+
+	// Never forward an untrusted redirect target to Authelia (open redirect)
+	if req.TargetURL != "" {
+		safeTarget := h.sanitizeRedirect(req.TargetURL)
+		if safeTarget != req.TargetURL {
+			h.logger.Warn("Rejected login redirect target", zap.String("target_url", req.TargetURL))
+		}
+		req.TargetURL = safeTarget
 	}
 
 	// Convert to Authelia format
@@ -69,42 +83,47 @@ func (h *AutheliaHandler) Login(c *gin.Context) {
 	}
 
 	// Call Authelia /api/firstfactor (internal network only)
-	autheliaURL := h.config.Authelia.InternalURL + "/api/firstfactor"
-	proxyReq, err := http.NewRequestWithContext(c.Request.Context(), "POST", autheliaURL, bytes.NewReader(reqBody))
+	proxyReq, err := h.newAutheliaRequest(c, http.MethodPost, "/api/firstfactor", bytes.NewReader(reqBody))
 	if err != nil {
-		h.logger.Error("Failed to create Authelia request", zap.Error(err))
+		h.logger.Error("Failed to create Authelia request", logFields(c, zap.Error(err))...)
 		sendInternalError(c)
 		return
 	}
 
 	proxyReq.Header.Set("Content-Type", "application/json")
-	proxyReq.Header.Set("X-Forwarded-For", c.ClientIP())
+	proxyReq.Header.Set("X-Forwarded-For", RealClientIP(c))
 	proxyReq.Header.Set("X-Forwarded-Proto", getScheme(c))
 	proxyReq.Header.Set("X-Forwarded-Host", c.Request.Host)
 
 	resp, err := h.client.Do(proxyReq)
 	if err != nil {
-		h.logger.Error("Authelia login request failed", zap.Error(err))
+		h.logger.Error("Authelia login request failed", logFields(c, zap.Error(err))...)
 		sendBadGatewayError(c)
 		return
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readAutheliaResponse(resp)
 	if err != nil {
-		h.logger.Error("Failed to read Authelia response", zap.Error(err))
-		sendInternalError(c)
+		h.logger.Error("Failed to read Authelia response", logFields(c, zap.Error(err))...)
+		sendInvalidAuthResponseError(c)
 		return
 	}
 
-	// Parse Authelia response
+	// Only a successful response must be JSON; errors (e.g. an HTML 502 from a
+	// proxy in front of Authelia) are mapped by status code alone
 	var autheliaResp autheliaFirstFactorResponse
-	if err := json.Unmarshal(body, &autheliaResp); err != nil {
-		h.logger.Error("Failed to parse Authelia response",
+	if resp.StatusCode == http.StatusOK && len(bytes.TrimSpace(body)) == 0 {
+		h.logger.Error("Empty Authelia response", logFields(c)...)
+		sendInvalidAuthResponseError(c)
+		return
+	}
+	if err := json.Unmarshal(body, &autheliaResp); err != nil && resp.StatusCode == http.StatusOK {
+		h.logger.Error("Failed to parse Authelia response", logFields(c,
 			zap.Error(err),
 			zap.String("body", string(body)),
-		)
-		sendInternalError(c)
+		)...)
+		sendInvalidAuthResponseError(c)
 		return
 	}
 
@@ -115,71 +134,156 @@ func (h *AutheliaHandler) Login(c *gin.Context) {
 func (h *AutheliaHandler) handleLoginResponse(c *gin.Context, resp *http.Response, req *AutheliaLoginRequest, autheliaResp *autheliaFirstFactorResponse, body []byte) {
 	switch resp.StatusCode {
 	case http.StatusOK:
+		h.resetLoginFailures(c, req.Email)
+
 		// Forward session cookies to client
-		for _, cookie := range resp.Cookies() {
-			if cookie.Name == h.config.Authelia.SessionCookieName {
-				cookie.Domain = h.config.Authelia.SessionDomain
-				cookie.Path = "/"
-				cookie.HttpOnly = true
-				cookie.Secure = c.Request.TLS != nil
-				cookie.SameSite = http.SameSiteLaxMode
-			}
-			http.SetCookie(c.Writer, cookie)
-		}
+		h.forwardSessionCookies(c, resp)
 
 		// Extract username from email for user info
-		username := req.Email
-		if idx := strings.Index(req.Email, "@"); idx > 0 {
-			username = req.Email[:idx]
-		}
+		username := usernameFromEmail(req.Email)
 
-		// Generate JWT token for API authentication
-		expiresAt := time.Now().Add(h.config.JWTExpiration)
-		claims := &Claims{
-			UserID: username,
-			Email:  req.Email,
-			Roles:  []string{"user"},
-			RegisteredClaims: jwt.RegisteredClaims{
-				ExpiresAt: jwt.NewNumericDate(expiresAt),
-				IssuedAt:  jwt.NewNumericDate(time.Now()),
-				Issuer:    "ugjb-api-gateway",
-				Subject:   username,
-			},
+		// With 2FA enforced the session is only first-factor authenticated:
+		// the JWT is issued by the second-factor handlers instead
+		if h.options.RequireSecondFactor {
+			h.logger.Info("First factor accepted, second factor required", logFields(c, zap.String("email", req.Email))...)
+			c.JSON(http.StatusOK, gin.H{
+				"status":                 "OK",
+				"second_factor_required": true,
+				"redirect":               h.sanitizeRedirect(autheliaResp.Data.Redirect),
+			})
+			return
 		}
 
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		tokenString, err := token.SignedString([]byte(h.config.JWTSecret))
-		if err != nil {
-			h.logger.Error("Failed to generate JWT token", zap.Error(err))
-			sendInternalError(c)
+		if !h.sendTokenResponse(c, username, req.Email, []string{"user"}, autheliaResp.Data.Redirect) {
 			return
 		}
 
-		h.logger.Info("User logged in successfully", zap.String("email", req.Email))
-
-		// Return response compatible with frontend expectations
-		c.JSON(http.StatusOK, gin.H{
-			"status":     "OK",
-			"token":      tokenString,
-			"expires_at": expiresAt.UTC().Format(time.RFC3339),
-			"user": gin.H{
-				"id":    username,
-				"name":  username,
-				"email": req.Email,
-				"roles": []string{"user"},
-			},
-			"redirect": autheliaResp.Data.Redirect,
-		})
-
-	case http.StatusUnauthorized:
-		h.logger.Warn("Authentication failed", zap.String("email", req.Email))
+		h.logger.Info("User logged in successfully", logFields(c, zap.String("email", req.Email))...)
+		h.recordLoginAttempt(c, username, req.Email, LoginOutcomeSuccess)
+
+	case http.StatusTooManyRequests:
+		h.logger.Warn("Login rate limited by Authelia", logFields(c, zap.String("email", req.Email))...)
+		h.recordLoginAttempt(c, usernameFromEmail(req.Email), req.Email, LoginOutcomeRateLimited)
+		sendRateLimitedError(c, resp.Header.Get("Retry-After"))
+
+	case http.StatusUnauthorized, http.StatusForbidden:
+		// Authelia regulation bans users after repeated failures
+		if isAutheliaBanned(resp.StatusCode, autheliaResp.Message) {
+			h.logger.Warn("Login rejected, account banned", logFields(c, zap.String("email", req.Email))...)
+			h.recordLoginAttempt(c, usernameFromEmail(req.Email), req.Email, LoginOutcomeBanned)
+			sendAccountBannedError(c)
+			return
+		}
+		h.logger.Warn("Authentication failed", logFields(c, zap.String("email", req.Email))...)
+		h.recordLoginAttempt(c, usernameFromEmail(req.Email), req.Email, LoginOutcomeInvalidCredentials)
+		h.recordLoginFailure(c, req.Email)
 		sendInvalidCredentialsError(c)
 
 	default:
-		h.logger.Error("Unexpected Authelia response",
-			zap.Int("status", resp.StatusCode),
+		h.logger.Error("Unexpected Authelia response", logFields(c,
+			zap.Int("upstream_status", resp.StatusCode),
 			zap.String("body", string(body)),
-		)
+		)...)
+		if resp.StatusCode >= http.StatusInternalServerError {
+			sendBadGatewayError(c)
+			return
+		}
 		sendAuthServiceError(c)
 	}
 }
+
+// isAutheliaBanned reports whether an Authelia rejection is a regulation ban rather than bad credentials
+func isAutheliaBanned(status int, message string) bool {
+	return status == http.StatusForbidden || containsAny(message, []string{"banned", "regulat"}, false)
+}
+
+// sendTokenResponse issues a gateway JWT (unless CookieOnly) and writes the login success response.
+// Returns false if an error response was sent instead.
+func (h *AutheliaHandler) sendTokenResponse(c *gin.Context, username, email string, roles []string, redirect string) bool {
+	response := LoginResponse{
+		User: UserInfo{
+			ID:    username,
+			Name:  username,
+			Email: email,
+			Roles: roles,
+		},
+		Redirect: h.sanitizeRedirect(redirect),
+	}
+
+	// Generate JWT token for API authentication
+	if !h.options.CookieOnly {
+		tokenString, expiresAt, err := h.issueToken(c, username, email, roles)
+		if err != nil {
+			h.logger.Error("Failed to generate JWT token", zap.Error(err))
+			sendInternalError(c)
+			return false
+		}
+		expiresAt = expiresAt.UTC().Truncate(time.Second)
+		response.Token = tokenString
+		response.ExpiresAt = &expiresAt
+	}
+
+	if h.options.NormalizeLoginResponse {
+		c.JSON(http.StatusOK, response)
+		return true
+	}
+
+	// Return response compatible with frontend expectations
+	body := gin.H{
+		"status":   "OK",
+		"user":     gin.H{"id": username, "name": username, "email": email, "roles": roles},
+		"redirect": response.Redirect,
+	}
+	if response.Token != "" {
+		body["token"] = response.Token
+		body["expires_at"] = response.ExpiresAt.Format(time.RFC3339)
+	}
+	c.JSON(http.StatusOK, body)
+	return true
+}
+
+// issueToken generates a gateway JWT and registers it as a new session
+func (h *AutheliaHandler) issueToken(c *gin.Context, username, email string, roles []string) (string, time.Time, error) {
+	sessionID, err := newSessionID()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(h.config.JWTExpiration)
+	claims := &Claims{
+		UserID: username,
+		Email:  email,
+		Roles:  roles,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        sessionID,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    "ugjb-api-gateway",
+			Subject:   username,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(h.config.JWTSecret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	if h.options.Sessions != nil {
+		session := Session{
+			ID:        sessionID,
+			UserID:    username,
+			UserAgent: c.Request.UserAgent(),
+			IP:        RealClientIP(c),
+			CreatedAt: now,
+			LastSeen:  now,
+			ExpiresAt: expiresAt,
+		}
+		if err := h.options.Sessions.Save(session); err != nil {
+			return "", time.Time{}, err
+		}
+	}
+
+	return tokenString, expiresAt, nil
+}
@@ -0,0 +1,70 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file implements login history recording and the login history endpoint.
+//
+// Associated Frontend Files:
+//   - web/app/src/pages/SettingsPage.tsx (security settings - recent login activity)
+//   - web/app/src/lib/api.ts (apiClient.get for login history)
+//
+// Architecture:
+//   Browser -> API Gateway (:8080) -> Authelia (:9091 internal) -> Redis (sessions)
+//
+// See: agent/docs/network-topology/api-gateway-topology.mmd
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// recordLoginAttempt stores a login attempt for auditing (never the password)
+func (h *AutheliaHandler) recordLoginAttempt(c *gin.Context, username, email, outcome string) {
+	if h.options.LoginHistory == nil {
+		return
+	}
+
+	attempt := LoginAttempt{
+		UserID:    username,
+		Email:     email,
+		IP:        RealClientIP(c),
+		UserAgent: c.Request.UserAgent(),
+		Outcome:   outcome,
+		Timestamp: time.Now().UTC(),
+	}
+	if err := h.options.LoginHistory.Record(attempt); err != nil {
+		h.logger.Error("Failed to record login attempt", zap.Error(err), zap.String("email", email))
+	}
+}
+
+// GetLoginHistory returns the current user's recent login attempts
+// @Summary Get login history
+// @Description Returns the authenticated user's recent login attempts (paginated)
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} PaginatedResponse[LoginAttempt] "Login history"
+// @Failure 401 {object} map[string]interface{} "Not authenticated"
+// @Router /api/v1/auth/login-history [get]
+func (h *AutheliaHandler) GetLoginHistory(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" || h.options.LoginHistory == nil {
+		sendUnauthorizedError(c)
+		return
+	}
+
+	params := parsePageParams(c)
+	attempts, total, err := h.options.LoginHistory.ListByUser(userID, params.Offset(), params.PageSize)
+	if err != nil {
+		h.logger.Error("Failed to list login history", zap.Error(err), zap.String("user_id", userID))
+		sendInternalError(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewPaginatedResponse(attempts, total, params))
+}
@@ -15,14 +15,19 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 // Logout handles user logout by proxying to internal Authelia
+// The gateway JWT presented in the Authorization header is revoked locally
+// and the session cookie is cleared even if Authelia cannot be reached, but
+// success is only reported once Authelia confirms the logout.
 // @Summary User logout
-// @Description Invalidate the current user session via Authelia
+// @Description Invalidate the current user session via Authelia and revoke the gateway token
 // @Tags Authentication
 // @Accept json
 // @Produce json
@@ -30,11 +35,15 @@ import (
 // @Failure 502 {object} map[string]interface{} "Auth service unavailable"
 // @Router /api/v1/auth/logout [post]
 func (h *AutheliaHandler) Logout(c *gin.Context) {
+	defer h.options.Metrics.observeAuth(c, "logout", time.Now())
+
+	// Revoke the gateway token regardless of the Authelia outcome
+	h.revokeBearerToken(c)
+
 	// Call Authelia /api/logout (internal network only)
-	autheliaURL := h.config.Authelia.InternalURL + "/api/logout"
-	proxyReq, err := http.NewRequestWithContext(c.Request.Context(), "POST", autheliaURL, nil)
+	proxyReq, err := h.newAutheliaRequest(c, http.MethodPost, "/api/logout", nil)
 	if err != nil {
-		h.logger.Error("Failed to create Authelia logout request", zap.Error(err))
+		h.logger.Error("Failed to create Authelia logout request", logFields(c, zap.Error(err))...)
 		sendInternalError(c)
 		return
 	}
@@ -47,16 +56,14 @@ func (h *AutheliaHandler) Logout(c *gin.Context) {
 		})
 	}
 
-	proxyReq.Header.Set("X-Forwarded-For", c.ClientIP())
+	proxyReq.Header.Set("X-Forwarded-For", RealClientIP(c))
 
 	resp, err := h.client.Do(proxyReq)
 	if err != nil {
-		h.logger.Error("Authelia logout request failed", zap.Error(err))
+		h.logger.Error("Authelia logout request failed", logFields(c, zap.Error(err))...)
 		// Still clear the cookie on the client side
 		h.clearSessionCookie(c)
-		c.JSON(http.StatusOK, gin.H{
-			"message": "Logged out",
-		})
+		sendBadGatewayError(c)
 		return
 	}
 	defer resp.Body.Close()
@@ -69,9 +76,42 @@ func (h *AutheliaHandler) Logout(c *gin.Context) {
 	// Also explicitly clear the session cookie
 	h.clearSessionCookie(c)
 
-	h.logger.Info("User logged out")
+	if resp.StatusCode != http.StatusOK {
+		h.logger.Error("Unexpected Authelia logout response", logFields(c, zap.Int("upstream_status", resp.StatusCode))...)
+		sendBadGatewayError(c)
+		return
+	}
+
+	// The body is not used, but an oversized one means something other than
+	// Authelia answered
+	if _, err := readAutheliaResponse(resp); err != nil {
+		h.logger.Error("Failed to read Authelia logout response", logFields(c, zap.Error(err))...)
+		sendInvalidAuthResponseError(c)
+		return
+	}
+
+	h.logger.Info("User logged out", logFields(c)...)
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Logged out successfully",
+		"message":  "Logged out successfully",
+		"redirect": h.options.PostLogoutRedirectURL,
 	})
 }
+
+// revokeBearerToken denylists the gateway JWT sent in the Authorization header, if any
+func (h *AutheliaHandler) revokeBearerToken(c *gin.Context) {
+	authHeader := c.GetHeader("Authorization")
+	if h.options.Sessions == nil || !strings.HasPrefix(authHeader, "Bearer ") {
+		return
+	}
+
+	claims, err := h.ValidateToken(strings.TrimPrefix(authHeader, "Bearer "))
+	if err != nil {
+		// Invalid, expired or already revoked tokens need no revocation
+		return
+	}
+
+	if _, err := h.options.Sessions.Revoke(claims.UserID, claims.ID); err != nil {
+		h.logger.Error("Failed to revoke token on logout", zap.Error(err), zap.String("user_id", claims.UserID))
+	}
+}
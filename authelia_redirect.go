@@ -0,0 +1,64 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains redirect target validation for Authelia handlers.
+//
+// Associated Frontend Files:
+//   - web/app/src/pages/LoginPage.tsx (sends targetURL, follows redirect)
+//   - web/app/src/hooks/useAuth.ts (post-login navigation)
+//
+// Architecture:
+//   Browser -> API Gateway (:8080) -> Authelia (:9091 internal) -> Redis (sessions)
+//
+// See: agent/docs/network-topology/api-gateway-topology.mmd
+package handlers
+
+import (
+	"net/url"
+	"strings"
+)
+
+// sanitizeRedirect returns target if it is a safe redirect, otherwise the configured default.
+// An empty target stays empty (no redirect requested).
+func (h *AutheliaHandler) sanitizeRedirect(target string) string {
+	if target == "" {
+		return ""
+	}
+	if h.isAllowedRedirect(target) {
+		return target
+	}
+	return h.options.DefaultRedirectURL
+}
+
+// isAllowedRedirect reports whether target is a relative path or points to an allowed host
+func (h *AutheliaHandler) isAllowedRedirect(target string) bool {
+	// Backslashes are normalized to slashes by browsers ("/\evil.com" -> "//evil.com")
+	if strings.Contains(target, "\\") {
+		return false
+	}
+
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+
+	// Relative path on the same origin (but not protocol-relative "//host")
+	if parsed.Scheme == "" && parsed.Host == "" {
+		return strings.HasPrefix(target, "/") && !strings.HasPrefix(target, "//")
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return false
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	for _, domain := range h.options.AllowedRedirectDomains {
+		domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+		if domain == "" {
+			continue
+		}
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file implements gateway JWT renewal from a still-valid Authelia session.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (apiClient - refreshes the token before it expires)
+//   - web/app/src/hooks/useAuth.ts (session restore on page load)
+//
+// Unlike SlidingSession, Refresh does not need the current JWT: it works from
+// the Authelia session cookie, so a client whose token already expired can
+// renew it without logging in again.
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Refresh issues a fresh gateway JWT for a valid Authelia session
+// @Summary Refresh token
+// @Description Validates the Authelia session cookie against Authelia and issues a new gateway JWT with a renewed expiry and the user's current groups. The previous token is not revoked.
+// @Tags Authentication
+// @Produce json
+// @Security SessionCookie
+// @Success 200 {object} AutheliaLoginResponse "New token issued"
+// @Failure 401 {object} map[string]interface{} "No valid session"
+// @Failure 502 {object} map[string]interface{} "Auth service unavailable"
+// @Router /api/v1/auth/refresh [post]
+func (h *AutheliaHandler) Refresh(c *gin.Context) {
+	defer h.options.Metrics.observeAuth(c, "refresh", time.Now())
+
+	sessionCookie, err := c.Cookie(h.config.Authelia.SessionCookieName)
+	if err != nil || sessionCookie == "" {
+		sendUnauthorizedError(c)
+		return
+	}
+
+	resp, err := h.doAutheliaRequest(c, http.MethodGet, "/api/user/info", sessionCookie, nil)
+	if err != nil {
+		h.logger.Error("Authelia user info request failed", logFields(c, zap.Error(err))...)
+		sendBadGatewayError(c)
+		return
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		sendUnauthorizedError(c)
+		return
+	case resp.StatusCode != http.StatusOK:
+		h.logger.Error("Unexpected Authelia user info response", logFields(c, zap.Int("upstream_status", resp.StatusCode))...)
+		sendBadGatewayError(c)
+		return
+	}
+	body, err := readAutheliaResponse(resp)
+	if err != nil {
+		h.logger.Error("Failed to read Authelia user info response", logFields(c, zap.Error(err))...)
+		sendInvalidAuthResponseError(c)
+		return
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		h.logger.Error("Empty Authelia user info response", logFields(c)...)
+		sendInvalidAuthResponseError(c)
+		return
+	}
+	var userInfo autheliaUserInfoResponse
+	if err := json.Unmarshal(body, &userInfo); err != nil {
+		h.logger.Error("Failed to parse Authelia user info response", logFields(c, zap.Error(err))...)
+		sendInvalidAuthResponseError(c)
+		return
+	}
+
+	// The user info has no username; the session state does
+	var state autheliaStateResponse
+	if err := h.getAutheliaJSON(c, "/api/state", sessionCookie, &state); err != nil {
+		h.logger.Error("Failed to read Authelia session state", logFields(c, zap.Error(err))...)
+		sendBadGatewayError(c)
+		return
+	}
+	if state.Data.Username == "" ||
+		(h.options.RequireSecondFactor && state.Data.AuthenticationLevel < autheliaTwoFactorLevel) {
+		sendUnauthorizedError(c)
+		return
+	}
+
+	username := state.Data.Username
+	email := username
+	if len(userInfo.Data.Emails) > 0 {
+		email = userInfo.Data.Emails[0]
+	}
+	roles := userInfo.Data.Groups
+	if len(roles) == 0 {
+		roles = []string{"user"}
+	}
+
+	if !h.sendTokenResponse(c, username, email, roles, "") {
+		return
+	}
+	h.logger.Info("Gateway token refreshed", logFields(c, zap.String("username", username))...)
+}
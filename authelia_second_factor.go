@@ -0,0 +1,210 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file implements second factor handlers for Authelia authentication.
+//
+// Associated Frontend Files:
+//   - web/app/src/pages/LoginPage.tsx (second factor step after first factor)
+//   - web/app/src/hooks/useAuth.ts (verifyTOTP - POST /auth/totp)
+//
+// Architecture:
+//   Browser -> API Gateway (:8080) -> Authelia (:9091 internal) -> Redis (sessions)
+//
+// Flow (with AutheliaOptions.RequireSecondFactor):
+//   1. POST /api/v1/auth/login -> first factor, session cookie, no JWT
+//   2. POST /api/v1/auth/totp  -> second factor; on success the JWT is issued
+//
+// See: agent/docs/network-topology/api-gateway-topology.mmd
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// autheliaTwoFactorLevel is Authelia's authentication_level after a second factor
+const autheliaTwoFactorLevel = 2
+
+// VerifyTOTP completes login with a TOTP code via internal Authelia
+// @Summary Verify TOTP second factor
+// @Description Verify a TOTP code for the pending session via Authelia and issue the JWT
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body AutheliaTOTPRequest true "TOTP code"
+// @Success 200 {object} AutheliaLoginResponse "Second factor accepted"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 401 {object} map[string]interface{} "Invalid code or no pending session"
+// @Failure 429 {object} map[string]interface{} "Too many attempts"
+// @Failure 502 {object} map[string]interface{} "Auth service unavailable"
+// @Router /api/v1/auth/totp [post]
+func (h *AutheliaHandler) VerifyTOTP(c *gin.Context) {
+	defer h.options.Metrics.observeAuth(c, "totp", time.Now())
+
+	var req AutheliaTOTPRequest
+	if err := decodeJSONBody(c, &req, h.jsonBodyOptions()); err != nil {
+		h.logger.Warn("Invalid TOTP request", zap.Error(err))
+		sendJSONBodyError(c, err)
+		return
+	}
+
+	sessionCookie, err := c.Cookie(h.config.Authelia.SessionCookieName)
+	if err != nil {
+		sendUnauthorizedError(c)
+		return
+	}
+
+	reqBody, err := json.Marshal(autheliaTOTPRequest{
+		Token:     req.Token,
+		TargetURL: h.sanitizeRedirect(req.TargetURL),
+	})
+	if err != nil {
+		h.logger.Error("Failed to marshal Authelia TOTP request", zap.Error(err))
+		sendInternalError(c)
+		return
+	}
+
+	resp, err := h.doAutheliaRequest(c, http.MethodPost, "/api/secondfactor/totp", sessionCookie, reqBody)
+	if err != nil {
+		h.logger.Error("Authelia TOTP request failed", zap.Error(err))
+		sendBadGatewayError(c)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := readAutheliaResponse(resp)
+	if err != nil {
+		h.logger.Error("Failed to read Authelia TOTP response", logFields(c, zap.Error(err))...)
+		sendInvalidAuthResponseError(c)
+		return
+	}
+
+	// Only a successful response must be JSON; errors are mapped by status code alone
+	var totpResp autheliaFirstFactorResponse
+	if err := json.Unmarshal(body, &totpResp); err != nil && resp.StatusCode == http.StatusOK {
+		h.logger.Error("Failed to parse Authelia TOTP response", logFields(c, zap.Error(err))...)
+		sendInvalidAuthResponseError(c)
+		return
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK && totpResp.Status != "KO":
+		h.forwardSessionCookies(c, resp)
+		h.completeSecondFactor(c, h.rotatedSessionCookie(resp, sessionCookie), totpResp.Data.Redirect)
+
+	case resp.StatusCode == http.StatusTooManyRequests:
+		h.logger.Warn("TOTP verification rate limited")
+		sendRateLimitedError(c, resp.Header.Get("Retry-After"))
+
+	case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		h.logger.Warn("TOTP verification failed", zap.Int("status", resp.StatusCode))
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_TOTP",
+				"message": "Invalid verification code",
+			},
+		})
+
+	default:
+		h.logger.Error("Unexpected Authelia TOTP response", zap.Int("status", resp.StatusCode))
+		sendAuthServiceError(c)
+	}
+}
+
+// completeSecondFactor confirms the session reached two-factor level and issues the JWT
+func (h *AutheliaHandler) completeSecondFactor(c *gin.Context, sessionCookie, redirect string) {
+	var state autheliaStateResponse
+	if err := h.getAutheliaJSON(c, "/api/state", sessionCookie, &state); err != nil {
+		h.logger.Error("Failed to read Authelia session state", zap.Error(err))
+		sendBadGatewayError(c)
+		return
+	}
+	if state.Data.Username == "" || state.Data.AuthenticationLevel < autheliaTwoFactorLevel {
+		h.logger.Warn("Session not elevated to second factor", zap.Int("level", state.Data.AuthenticationLevel))
+		sendUnauthorizedError(c)
+		return
+	}
+
+	username := state.Data.Username
+	email := username
+	var userInfo autheliaUserInfoResponse
+	if err := h.getAutheliaJSON(c, "/api/user/info", sessionCookie, &userInfo); err == nil && len(userInfo.Data.Emails) > 0 {
+		email = userInfo.Data.Emails[0]
+	}
+
+	if !h.sendTokenResponse(c, username, email, []string{"user"}, redirect) {
+		return
+	}
+
+	h.logger.Info("User completed second factor", zap.String("username", username))
+	h.recordLoginAttempt(c, username, email, LoginOutcomeSuccess)
+}
+
+// rotatedSessionCookie returns the session cookie Authelia set on elevation, or current if unchanged
+func (h *AutheliaHandler) rotatedSessionCookie(resp *http.Response, current string) string {
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == h.config.Authelia.SessionCookieName && cookie.Value != "" {
+			return cookie.Value
+		}
+	}
+	return current
+}
+
+// doAutheliaRequest sends a request to internal Authelia on behalf of the client session
+func (h *AutheliaHandler) doAutheliaRequest(c *gin.Context, method, path, sessionCookie string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	proxyReq, err := h.newAutheliaRequest(c, method, path, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		proxyReq.Header.Set("Content-Type", "application/json")
+	}
+	proxyReq.Header.Set("X-Forwarded-For", RealClientIP(c))
+	proxyReq.Header.Set("X-Forwarded-Proto", getScheme(c))
+	proxyReq.Header.Set("X-Forwarded-Host", c.Request.Host)
+	if sessionCookie != "" {
+		proxyReq.AddCookie(&http.Cookie{
+			Name:  h.config.Authelia.SessionCookieName,
+			Value: sessionCookie,
+		})
+	}
+
+	return h.client.Do(proxyReq)
+}
+
+// getAutheliaJSON performs an authenticated GET against Authelia and decodes the
+// JSON response; empty and oversized bodies are errors
+func (h *AutheliaHandler) getAutheliaJSON(c *gin.Context, path, sessionCookie string, out interface{}) error {
+	resp, err := h.doAutheliaRequest(c, http.MethodGet, path, sessionCookie, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("authelia %s returned status %d", path, resp.StatusCode)
+	}
+	body, err := readAutheliaResponse(resp)
+	if err != nil {
+		return fmt.Errorf("read authelia %s response: %w", path, err)
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return fmt.Errorf("authelia %s returned an empty body", path)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decode authelia %s response: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file implements gateway session listing, revocation and token validation.
+//
+// Associated Frontend Files:
+//   - web/app/src/pages/SettingsPage.tsx (security settings - active sessions list)
+//   - web/app/src/lib/api.ts (apiClient.get/delete for sessions)
+//
+// Architecture:
+//   Browser -> API Gateway (:8080) -> Authelia (:9091 internal) -> Redis (sessions)
+//
+// See: agent/docs/network-topology/api-gateway-topology.mmd
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// ErrTokenRevoked is returned by ValidateToken for denylisted tokens
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// ListSessions returns the current user's active gateway sessions
+// @Summary List active sessions
+// @Description Returns the authenticated user's active gateway sessions, newest first (paginated)
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} PaginatedResponse[Session] "Active sessions"
+// @Failure 401 {object} map[string]interface{} "Not authenticated"
+// @Router /api/v1/auth/sessions [get]
+func (h *AutheliaHandler) ListSessions(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" || h.options.Sessions == nil {
+		sendUnauthorizedError(c)
+		return
+	}
+
+	sessions, err := h.options.Sessions.ListByUser(userID)
+	if err != nil {
+		h.logger.Error("Failed to list sessions", zap.Error(err), zap.String("user_id", userID))
+		sendInternalError(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, paginate(sessions, parsePageParams(c)))
+}
+
+// RevokeSession revokes one of the current user's gateway sessions
+// @Summary Revoke session
+// @Description Revokes an active gateway session; its token is denylisted
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} map[string]interface{} "Session revoked"
+// @Failure 401 {object} map[string]interface{} "Not authenticated"
+// @Failure 404 {object} map[string]interface{} "Session not found"
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (h *AutheliaHandler) RevokeSession(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" || h.options.Sessions == nil {
+		sendUnauthorizedError(c)
+		return
+	}
+
+	sessionID := c.Param("id")
+	revoked, err := h.options.Sessions.Revoke(userID, sessionID)
+	if err != nil {
+		h.logger.Error("Failed to revoke session", zap.Error(err), zap.String("session_id", sessionID))
+		sendInternalError(c)
+		return
+	}
+	if !revoked {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": gin.H{
+				"code":    "SESSION_NOT_FOUND",
+				"message": "Session not found",
+			},
+		})
+		return
+	}
+
+	h.logger.Info("Session revoked", zap.String("user_id", userID), zap.String("session_id", sessionID))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Session revoked",
+	})
+}
+
+// ValidateToken parses a gateway JWT, rejecting invalid, expired and revoked tokens.
+// It also records the session as seen. Intended for the auth middleware.
+func (h *AutheliaHandler) ValidateToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return []byte(h.config.JWTSecret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	if h.options.Sessions != nil && claims.ID != "" {
+		revoked, err := h.options.Sessions.IsRevoked(claims.ID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+		if err := h.options.Sessions.Touch(claims.ID, time.Now()); err != nil {
+			h.logger.Warn("Failed to update session last-seen", zap.Error(err))
+		}
+	}
+
+	return claims, nil
+}
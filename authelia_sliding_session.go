@@ -0,0 +1,84 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file implements sliding gateway sessions: tokens close to expiry are
+// replaced transparently while the user stays active.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (apiClient - swaps in the X-Refreshed-Token value)
+//
+// Each token is refreshed at most once: the replacement is recorded on its
+// session, which is revoked after refreshGracePeriod so requests already in
+// flight with the old token do not fail.
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// HeaderRefreshedToken carries a replacement gateway JWT issued by SlidingSession
+const HeaderRefreshedToken = "X-Refreshed-Token"
+
+// refreshGracePeriod is how long a refreshed token keeps working
+const refreshGracePeriod = 30 * time.Second
+
+// SlidingSession returns a middleware that, for requests carrying a valid Bearer
+// token expiring within RefreshWindow, issues a fresh token in the
+// X-Refreshed-Token response header (and the RefreshTokenCookie cookie, if set).
+// Mount it after the auth middleware: invalid or expired tokens are never
+// refreshed and are left for the auth middleware to reject. It does nothing
+// when RefreshWindow is 0, in CookieOnly mode or without a Sessions store to
+// record replacements in.
+func (h *AutheliaHandler) SlidingSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.options.RefreshWindow <= 0 || h.options.CookieOnly || h.options.Sessions == nil {
+			c.Next()
+			return
+		}
+
+		tokenString, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || tokenString == "" {
+			c.Next()
+			return
+		}
+		claims, err := h.ValidateToken(tokenString)
+		if err != nil || claims.ExpiresAt == nil || time.Until(claims.ExpiresAt.Time) > h.options.RefreshWindow {
+			c.Next()
+			return
+		}
+
+		// Concurrent requests with the same token lose the claim and are not refreshed
+		replaced, err := h.options.Sessions.Replace(claims.UserID, claims.ID, time.Now().Add(refreshGracePeriod))
+		if err != nil || !replaced {
+			if err != nil {
+				// The current token is still valid; try again on the next request
+				h.logger.Warn("Failed to record gateway token refresh", zap.Error(err), zap.String("user_id", claims.UserID))
+			}
+			c.Next()
+			return
+		}
+
+		token, expiresAt, err := h.issueToken(c, claims.UserID, claims.Email, claims.Roles)
+		if err != nil {
+			h.logger.Error("Failed to refresh gateway token", zap.Error(err), zap.String("user_id", claims.UserID))
+			c.Next()
+			return
+		}
+
+		c.Header(HeaderRefreshedToken, token)
+		if name := h.options.RefreshTokenCookie; name != "" {
+			cookie := &http.Cookie{
+				Name:   name,
+				Value:  token,
+				MaxAge: int(time.Until(expiresAt).Seconds()),
+			}
+			h.applySessionCookieAttributes(c, cookie)
+			http.SetCookie(c.Writer, cookie)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,1375 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/ugjb/api-gateway/config"
+	"github.com/ugjb/api-gateway/handlers"
+	"go.uber.org/zap"
+)
+
+const testSessionCookieName = "authelia_session"
+
+// newFakeAuthelia creates a fake internal Authelia server
+func newFakeAuthelia(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newAutheliaTestConfig creates a gateway config pointing at the fake Authelia
+func newAutheliaTestConfig(autheliaURL string) *config.Config {
+	cfg := &config.Config{}
+	cfg.JWTSecret = "test-secret"
+	cfg.JWTExpiration = time.Hour
+	cfg.Authelia.InternalURL = autheliaURL
+	cfg.Authelia.SessionCookieName = testSessionCookieName
+	return cfg
+}
+
+// doLogin posts a login request to the Authelia handler
+func doLogin(h *handlers.AutheliaHandler, body interface{}) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.POST("/api/v1/auth/login", h.Login)
+
+	payload, _ := json.Marshal(body)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestAutheliaLoginRedirectValidation verifies targetURL and redirect are constrained to the allowlist
+func TestAutheliaLoginRedirectValidation(t *testing.T) {
+	tests := []struct {
+		name             string
+		targetURL        string
+		expectedUpstream string
+	}{
+		{"allowed host", "https://app.example.com/dashboard", "https://app.example.com/dashboard"},
+		{"disallowed external host", "https://evil.test/phish", "/"},
+		{"protocol-relative host", "//evil.test/phish", "/"},
+		{"relative path", "/projects/42", "/projects/42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var upstreamTarget string
+			authelia := newFakeAuthelia(t, func(w http.ResponseWriter, r *http.Request) {
+				var req map[string]interface{}
+				json.NewDecoder(r.Body).Decode(&req)
+				upstreamTarget, _ = req["targetURL"].(string)
+
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"status": "OK",
+					"data":   map[string]string{"redirect": upstreamTarget},
+				})
+			})
+
+			opts := handlers.DefaultAutheliaOptions()
+			opts.AllowedRedirectDomains = []string{"example.com"}
+			h := handlers.NewAutheliaHandlerWithOptions(newAutheliaTestConfig(authelia.URL), zap.NewNop(), opts)
+
+			w := doLogin(h, map[string]interface{}{
+				"email":     "jane@example.com",
+				"password":  "secret",
+				"targetURL": tt.targetURL,
+			})
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			if upstreamTarget != tt.expectedUpstream {
+				t.Errorf("Expected targetURL '%s' forwarded to Authelia, got '%s'", tt.expectedUpstream, upstreamTarget)
+			}
+
+			var body map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &body)
+			if body["redirect"] != tt.expectedUpstream {
+				t.Errorf("Expected redirect '%s', got '%v'", tt.expectedUpstream, body["redirect"])
+			}
+		})
+	}
+}
+
+// TestAutheliaLoginStripsUnsafeUpstreamRedirect verifies a redirect returned by Authelia is validated too
+func TestAutheliaLoginStripsUnsafeUpstreamRedirect(t *testing.T) {
+	authelia := newFakeAuthelia(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"OK","data":{"redirect":"https://evil.test/"}}`))
+	})
+
+	h := handlers.NewAutheliaHandler(newAutheliaTestConfig(authelia.URL), zap.NewNop())
+	w := doLogin(h, map[string]interface{}{"email": "jane@example.com", "password": "secret"})
+
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body["redirect"] != "/" {
+		t.Errorf("Expected unsafe redirect replaced with '/', got '%v'", body["redirect"])
+	}
+}
+
+// newOKAuthelia creates a fake Authelia accepting every first-factor login
+func newOKAuthelia(t *testing.T) *httptest.Server {
+	return newFakeAuthelia(t, func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: testSessionCookieName, Value: "session-value"})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"OK"}`))
+	})
+}
+
+// loginToken logs in through the handler and returns the issued gateway JWT
+func loginToken(t *testing.T, h *handlers.AutheliaHandler, email string) string {
+	w := doLogin(h, map[string]interface{}{"email": email, "password": "secret"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Login failed with status %d: %s", w.Code, w.Body.String())
+	}
+
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	token, _ := body["token"].(string)
+	if token == "" {
+		t.Fatal("Expected token in login response")
+	}
+	return token
+}
+
+// setupSessionsRouter creates a router authenticating requests with the gateway JWT
+func setupSessionsRouter(h *handlers.AutheliaHandler) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		claims, err := h.ValidateToken(tokenString)
+		if err != nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Set("user_id", claims.UserID)
+	})
+	router.GET("/api/v1/auth/sessions", h.ListSessions)
+	router.DELETE("/api/v1/auth/sessions/:id", h.RevokeSession)
+	return router
+}
+
+// TestAutheliaSessionsListAndRevoke verifies sessions are listed per user and revocation denylists the token
+func TestAutheliaSessionsListAndRevoke(t *testing.T) {
+	authelia := newOKAuthelia(t)
+	h := handlers.NewAutheliaHandler(newAutheliaTestConfig(authelia.URL), zap.NewNop())
+
+	token := loginToken(t, h, "jane@example.com")
+	otherToken := loginToken(t, h, "jane@example.com")
+	loginToken(t, h, "john@example.com")
+
+	router := setupSessionsRouter(h)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/auth/sessions", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var body struct {
+		Items []handlers.Session `json:"items"`
+		Total int                `json:"total"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if len(body.Items) != 2 || body.Total != 2 {
+		t.Fatalf("Expected 2 sessions for jane, got %d (total %d)", len(body.Items), body.Total)
+	}
+
+	otherClaims, err := h.ValidateToken(otherToken)
+	if err != nil {
+		t.Fatalf("Expected other token to be valid: %v", err)
+	}
+
+	req, _ = http.NewRequest(http.MethodDelete, "/api/v1/auth/sessions/"+otherClaims.ID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d revoking session, got %d", http.StatusOK, w.Code)
+	}
+
+	if _, err := h.ValidateToken(otherToken); !errors.Is(err, handlers.ErrTokenRevoked) {
+		t.Errorf("Expected revoked token to fail validation with ErrTokenRevoked, got %v", err)
+	}
+	if _, err := h.ValidateToken(token); err != nil {
+		t.Errorf("Expected current token to remain valid, got %v", err)
+	}
+
+	// Revoking an unknown session returns 404
+	req, _ = http.NewRequest(http.MethodDelete, "/api/v1/auth/sessions/unknown", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for unknown session, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+// TestAutheliaLoginHistory verifies login attempts are recorded and scoped to the requesting user
+func TestAutheliaLoginHistory(t *testing.T) {
+	authelia := newFakeAuthelia(t, func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["password"] != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":"KO","message":"Authentication failed"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"OK"}`))
+	})
+	h := handlers.NewAutheliaHandler(newAutheliaTestConfig(authelia.URL), zap.NewNop())
+
+	doLogin(h, map[string]interface{}{"email": "jane@example.com", "password": "wrong"})
+	token := loginToken(t, h, "jane@example.com")
+	loginToken(t, h, "john@example.com")
+
+	router := setupSessionsRouter(h)
+	router.GET("/api/v1/auth/login-history", h.GetLoginHistory)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/auth/login-history?page=1&page_size=10", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("User-Agent", "history-test")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var body struct {
+		Items []map[string]interface{} `json:"items"`
+		Total int                      `json:"total"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+
+	if body.Total != 2 || len(body.Items) != 2 {
+		t.Fatalf("Expected 2 attempts for jane only, got total=%d items=%d", body.Total, len(body.Items))
+	}
+	if body.Items[0]["outcome"] != "success" || body.Items[1]["outcome"] != "invalid_credentials" {
+		t.Errorf("Expected newest-first [success, invalid_credentials], got [%v, %v]", body.Items[0]["outcome"], body.Items[1]["outcome"])
+	}
+	for _, item := range body.Items {
+		if item["email"] != "jane@example.com" {
+			t.Errorf("Expected only jane's attempts, got %v", item["email"])
+		}
+		if _, ok := item["password"]; ok {
+			t.Error("Login history must never contain the password")
+		}
+	}
+}
+
+// TestAutheliaLoginJSONBodyErrors verifies each class of malformed request body gets a precise error
+func TestAutheliaLoginJSONBodyErrors(t *testing.T) {
+	authelia := newOKAuthelia(t)
+
+	opts := handlers.DefaultAutheliaOptions()
+	opts.MaxJSONBodyBytes = 128
+	opts.DisallowUnknownJSONFields = true
+	h := handlers.NewAutheliaHandlerWithOptions(newAutheliaTestConfig(authelia.URL), zap.NewNop(), opts)
+
+	router := gin.New()
+	router.POST("/api/v1/auth/login", h.Login)
+
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"syntax error", "application/json", `{"email": "jane@example.com",`, http.StatusBadRequest, "MALFORMED_JSON"},
+		{"unknown field", "application/json", `{"email":"jane@example.com","password":"x","admin":true}`, http.StatusBadRequest, "UNKNOWN_FIELD"},
+		{"body too large", "application/json", `{"email":"jane@example.com","password":"` + strings.Repeat("x", 256) + `"}`, http.StatusRequestEntityTooLarge, "BODY_TOO_LARGE"},
+		{"empty body", "application/json", ``, http.StatusBadRequest, "EMPTY_BODY"},
+		{"wrong content type", "text/plain", `{"email":"jane@example.com","password":"x"}`, http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE"},
+		{"validation failure", "application/json", `{"email":"not-an-email","password":"x"}`, http.StatusBadRequest, "INVALID_REQUEST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			var body map[string]map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &body)
+			if body["error"]["code"] != tt.expectedCode {
+				t.Errorf("Expected code '%s', got '%v'", tt.expectedCode, body["error"]["code"])
+			}
+		})
+	}
+}
+
+// TestAutheliaLogoutRevokesToken verifies logout denylists the token and returns the redirect
+func TestAutheliaLogoutRevokesToken(t *testing.T) {
+	authelia := newOKAuthelia(t)
+	h := handlers.NewAutheliaHandler(newAutheliaTestConfig(authelia.URL), zap.NewNop())
+	token := loginToken(t, h, "jane@example.com")
+
+	router := gin.New()
+	router.POST("/api/v1/auth/logout", h.Logout)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body["message"] != "Logged out successfully" {
+		t.Errorf("Expected success message, got %v", body["message"])
+	}
+	if body["redirect"] != "/login" {
+		t.Errorf("Expected redirect '/login', got %v", body["redirect"])
+	}
+
+	if _, err := h.ValidateToken(token); !errors.Is(err, handlers.ErrTokenRevoked) {
+		t.Errorf("Expected token to be revoked after logout, got %v", err)
+	}
+}
+
+// TestAutheliaLogoutUpstreamFailure verifies logout is not reported as successful when Authelia fails
+func TestAutheliaLogoutUpstreamFailure(t *testing.T) {
+	authelia := newFakeAuthelia(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/logout" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"status":"OK"}`))
+	})
+	h := handlers.NewAutheliaHandler(newAutheliaTestConfig(authelia.URL), zap.NewNop())
+	token := loginToken(t, h, "jane@example.com")
+
+	router := gin.New()
+	router.POST("/api/v1/auth/logout", h.Logout)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("Expected status %d, got %d", http.StatusBadGateway, w.Code)
+	}
+	if _, err := h.ValidateToken(token); !errors.Is(err, handlers.ErrTokenRevoked) {
+		t.Errorf("Expected token to be revoked even when Authelia fails, got %v", err)
+	}
+}
+
+// TestAutheliaAuthMetrics verifies auth outcomes and latency are recorded on the injected registry
+func TestAutheliaAuthMetrics(t *testing.T) {
+	authelia := newFakeAuthelia(t, func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["password"] != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":"KO"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"OK"}`))
+	})
+
+	registry := prometheus.NewRegistry()
+	opts := handlers.DefaultAutheliaOptions()
+	opts.Metrics = handlers.NewGatewayMetrics(registry)
+	h := handlers.NewAutheliaHandlerWithOptions(newAutheliaTestConfig(authelia.URL), zap.NewNop(), opts)
+
+	doLogin(h, map[string]interface{}{"email": "jane@example.com", "password": "secret"})
+	doLogin(h, map[string]interface{}{"email": "jane@example.com", "password": "wrong"})
+	doLogin(h, map[string]interface{}{"email": "jane@example.com", "password": "wrong"})
+
+	if got := testutil.ToFloat64(opts.Metrics.AuthRequests.WithLabelValues("login", "success")); got != 1 {
+		t.Errorf("Expected 1 successful login, got %v", got)
+	}
+	if got := testutil.ToFloat64(opts.Metrics.AuthRequests.WithLabelValues("login", "invalid_credentials")); got != 2 {
+		t.Errorf("Expected 2 invalid_credentials logins, got %v", got)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	var observed uint64
+	for _, family := range families {
+		if family.GetName() == "auth_request_duration_seconds" {
+			for _, metric := range family.GetMetric() {
+				observed += metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	if observed != 3 {
+		t.Errorf("Expected 3 latency observations, got %d", observed)
+	}
+}
+
+// newTwoFactorAuthelia creates a fake Authelia that elevates the session after TOTP code "123456"
+// and answers code "999999" with an unparsable 200 response
+func newTwoFactorAuthelia(t *testing.T) *httptest.Server {
+	level := 1
+	return newFakeAuthelia(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/firstfactor":
+			http.SetCookie(w, &http.Cookie{Name: testSessionCookieName, Value: "session-value"})
+			w.Write([]byte(`{"status":"OK"}`))
+		case "/api/secondfactor/totp":
+			var req map[string]string
+			json.NewDecoder(r.Body).Decode(&req)
+			if cookie, err := r.Cookie(testSessionCookieName); err != nil || cookie.Value != "session-value" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if req["token"] == "999999" {
+				w.Write([]byte(`<html>OK</html>`))
+				return
+			}
+			if req["token"] != "123456" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"status":"KO","message":"Authentication failed"}`))
+				return
+			}
+			level = 2
+			w.Write([]byte(`{"status":"OK"}`))
+		case "/api/state":
+			fmt.Fprintf(w, `{"status":"OK","data":{"username":"jane","authentication_level":%d}}`, level)
+		case "/api/user/info":
+			w.Write([]byte(`{"status":"OK","data":{"display_name":"Jane","emails":["jane@example.com"]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+// TestAutheliaTOTPSecondFactor verifies the JWT is only issued after a valid TOTP code
+func TestAutheliaTOTPSecondFactor(t *testing.T) {
+	authelia := newTwoFactorAuthelia(t)
+	opts := handlers.DefaultAutheliaOptions()
+	opts.RequireSecondFactor = true
+	h := handlers.NewAutheliaHandlerWithOptions(newAutheliaTestConfig(authelia.URL), zap.NewNop(), opts)
+
+	w := doLogin(h, map[string]interface{}{"email": "jane@example.com", "password": "secret"})
+	var loginBody map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &loginBody)
+	if w.Code != http.StatusOK || loginBody["second_factor_required"] != true {
+		t.Fatalf("Expected second factor required, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, ok := loginBody["token"]; ok {
+		t.Fatal("Expected no token before second factor")
+	}
+
+	router := gin.New()
+	router.POST("/api/v1/auth/totp", h.VerifyTOTP)
+	verify := func(code string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/totp", strings.NewReader(`{"token":"`+code+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: testSessionCookieName, Value: "session-value"})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	w = verify("000000")
+	if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "INVALID_TOTP") {
+		t.Fatalf("Expected INVALID_TOTP for wrong code, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = verify("999999")
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("Expected status %d for an unparsable Authelia response, got %d: %s", http.StatusBadGateway, w.Code, w.Body.String())
+	}
+
+	w = verify("12ab56")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for non-numeric code, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	w = verify("123456")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if token, _ := body["token"].(string); token == "" {
+		t.Error("Expected token after second factor")
+	}
+	user, _ := body["user"].(map[string]interface{})
+	if user["email"] != "jane@example.com" {
+		t.Errorf("Expected email from Authelia user info, got '%v'", user["email"])
+	}
+}
+
+// TestAutheliaWebAuthnAssertion verifies WebAuthn payloads pass through intact and the JWT follows a successful assertion
+func TestAutheliaWebAuthnAssertion(t *testing.T) {
+	const challenge = `{"status":"OK","data":{"publicKey":{"challenge":"q2xr-_Z3aA","allowCredentials":[{"type":"public-key","id":"AQID-_8"}]}}}`
+	const assertion = `{"id":"AQID-_8","rawId":"AQID-_8","type":"public-key","response":{"authenticatorData":"SZYN5Y-_","clientDataJSON":"eyJ0eXBlIjoid2ViYXV0aG4uZ2V0In0","signature":"MEUCIQ-_"}}`
+
+	level := 1
+	var received string
+	authelia := newFakeAuthelia(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/secondfactor/webauthn/assertion" && r.Method == http.MethodGet:
+			w.Write([]byte(challenge))
+		case r.URL.Path == "/api/secondfactor/webauthn/assertion" && r.Method == http.MethodPost:
+			payload, _ := io.ReadAll(r.Body)
+			received = string(payload)
+			if received == `{"id":"garbled"}` {
+				w.Write([]byte(`<html>OK</html>`))
+				return
+			}
+			if received != assertion {
+				w.Write([]byte(`{"status":"KO"}`))
+				return
+			}
+			level = 2
+			w.Write([]byte(`{"status":"OK"}`))
+		case r.URL.Path == "/api/state":
+			fmt.Fprintf(w, `{"status":"OK","data":{"username":"jane","authentication_level":%d}}`, level)
+		case r.URL.Path == "/api/user/info":
+			w.Write([]byte(`{"status":"OK","data":{"emails":["jane@example.com"]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	opts := handlers.DefaultAutheliaOptions()
+	opts.RequireSecondFactor = true
+	h := handlers.NewAutheliaHandlerWithOptions(newAutheliaTestConfig(authelia.URL), zap.NewNop(), opts)
+
+	router := gin.New()
+	router.GET("/api/v1/auth/webauthn/assertion", h.GetWebAuthnAssertionOptions)
+	router.POST("/api/v1/auth/webauthn/assertion", h.VerifyWebAuthnAssertion)
+	do := func(method, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, "/api/v1/auth/webauthn/assertion", strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.AddCookie(&http.Cookie{Name: testSessionCookieName, Value: "session-value"})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	w := do(http.MethodGet, "")
+	if w.Code != http.StatusOK || w.Body.String() != challenge {
+		t.Fatalf("Expected challenge relayed unchanged, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = do(http.MethodPost, `{"id":"forged"}`)
+	if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "INVALID_WEBAUTHN_ASSERTION") {
+		t.Fatalf("Expected rejected assertion, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = do(http.MethodPost, `{"id":"garbled"}`)
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("Expected status %d for an unparsable Authelia response, got %d: %s", http.StatusBadGateway, w.Code, w.Body.String())
+	}
+
+	w = do(http.MethodPost, assertion)
+	if received != assertion {
+		t.Errorf("Expected assertion forwarded byte for byte, got %s", received)
+	}
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if token, _ := body["token"].(string); token == "" {
+		t.Error("Expected token after successful assertion")
+	}
+}
+
+// sessionCookieFrom returns the session cookie set on a recorded response
+func sessionCookieFrom(t *testing.T, w *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == testSessionCookieName {
+			return cookie
+		}
+	}
+	t.Fatalf("Expected %s cookie in response, got %v", testSessionCookieName, w.Header().Values("Set-Cookie"))
+	return nil
+}
+
+// TestAutheliaSessionCookieAttributes verifies configured cookie attributes on login and logout
+func TestAutheliaSessionCookieAttributes(t *testing.T) {
+	tests := []struct {
+		name           string
+		sameSite       http.SameSite
+		secure         bool
+		expectedSecure bool
+	}{
+		{"lax", http.SameSiteLaxMode, false, false},
+		{"strict secure", http.SameSiteStrictMode, true, true},
+		{"none implies secure", http.SameSiteNoneMode, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authelia := newFakeAuthelia(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/logout" {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				http.SetCookie(w, &http.Cookie{Name: testSessionCookieName, Value: "session-value"})
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"status":"OK"}`))
+			})
+
+			opts := handlers.DefaultAutheliaOptions()
+			opts.SessionCookie = handlers.SessionCookieOptions{
+				SameSite: tt.sameSite,
+				Path:     "/app",
+				Secure:   tt.secure,
+				HTTPOnly: false,
+			}
+			h := handlers.NewAutheliaHandlerWithOptions(newAutheliaTestConfig(authelia.URL), zap.NewNop(), opts)
+
+			check := func(cookie *http.Cookie) {
+				t.Helper()
+				if cookie.SameSite != tt.sameSite {
+					t.Errorf("Expected SameSite %v, got %v", tt.sameSite, cookie.SameSite)
+				}
+				if cookie.Secure != tt.expectedSecure {
+					t.Errorf("Expected Secure %v, got %v", tt.expectedSecure, cookie.Secure)
+				}
+				if cookie.Path != "/app" {
+					t.Errorf("Expected Path '/app', got '%s'", cookie.Path)
+				}
+				if cookie.HttpOnly {
+					t.Error("Expected HttpOnly disabled")
+				}
+			}
+
+			check(sessionCookieFrom(t, doLogin(h, map[string]interface{}{"email": "jane@example.com", "password": "secret"})))
+
+			// A failed upstream logout clears the cookie with the same attributes
+			router := gin.New()
+			router.POST("/api/v1/auth/logout", h.Logout)
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			cleared := sessionCookieFrom(t, w)
+			if cleared.MaxAge >= 0 {
+				t.Errorf("Expected cookie cleared, got MaxAge %d", cleared.MaxAge)
+			}
+			check(cleared)
+		})
+	}
+}
+
+// responseKeys returns the sorted top-level keys of a JSON object response
+func responseKeys(t *testing.T, w *httptest.ResponseRecorder) []string {
+	t.Helper()
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Expected JSON body, got error: %v", err)
+	}
+	keys := make([]string, 0, len(body))
+	for key := range body {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TestAutheliaNormalizedLoginResponse verifies login and second-factor completion share one response shape
+func TestAutheliaNormalizedLoginResponse(t *testing.T) {
+	tests := []struct {
+		name         string
+		cookieOnly   bool
+		expectedKeys []string
+	}{
+		{"jwt", false, []string{"expires_at", "token", "user"}},
+		{"cookie only", true, []string{"user"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authelia := newTwoFactorAuthelia(t)
+			opts := handlers.DefaultAutheliaOptions()
+			opts.NormalizeLoginResponse = true
+			opts.CookieOnly = tt.cookieOnly
+			loginHandler := handlers.NewAutheliaHandlerWithOptions(newAutheliaTestConfig(authelia.URL), zap.NewNop(), opts)
+
+			opts.RequireSecondFactor = true
+			totpHandler := handlers.NewAutheliaHandlerWithOptions(newAutheliaTestConfig(authelia.URL), zap.NewNop(), opts)
+
+			loginKeys := responseKeys(t, doLogin(loginHandler, map[string]interface{}{
+				"email": "jane@example.com", "password": "secret",
+			}))
+
+			router := gin.New()
+			router.POST("/api/v1/auth/totp", totpHandler.VerifyTOTP)
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/totp", strings.NewReader(`{"token":"123456"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.AddCookie(&http.Cookie{Name: testSessionCookieName, Value: "session-value"})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			totpKeys := responseKeys(t, w)
+
+			if strings.Join(loginKeys, ",") != strings.Join(tt.expectedKeys, ",") {
+				t.Errorf("Expected login keys %v, got %v", tt.expectedKeys, loginKeys)
+			}
+			if strings.Join(totpKeys, ",") != strings.Join(loginKeys, ",") {
+				t.Errorf("Expected identical shapes, login %v vs totp %v", loginKeys, totpKeys)
+			}
+		})
+	}
+}
+
+// TestAutheliaLoginRateLimitedAndBanned verifies Authelia throttling and bans map to client errors without a token
+func TestAutheliaLoginRateLimitedAndBanned(t *testing.T) {
+	tests := []struct {
+		name               string
+		status             int
+		retryAfter         string
+		body               string
+		expectedStatus     int
+		expectedCode       string
+		expectedRetryAfter string
+	}{
+		{"rate limited", http.StatusTooManyRequests, "30", `{"status":"KO","message":"Too many requests"}`, http.StatusTooManyRequests, "RATE_LIMITED", "30"},
+		{"rate limited without retry-after", http.StatusTooManyRequests, "", `{"status":"KO"}`, http.StatusTooManyRequests, "RATE_LIMITED", ""},
+		{"banned forbidden", http.StatusForbidden, "", `{"status":"KO","message":"Authentication failed"}`, http.StatusForbidden, "ACCOUNT_BANNED", ""},
+		{"banned message", http.StatusUnauthorized, "", `{"status":"KO","message":"User is banned"}`, http.StatusForbidden, "ACCOUNT_BANNED", ""},
+		{"invalid credentials", http.StatusUnauthorized, "", `{"status":"KO","message":"Authentication failed"}`, http.StatusUnauthorized, "INVALID_CREDENTIALS", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authelia := newFakeAuthelia(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+			h := handlers.NewAutheliaHandler(newAutheliaTestConfig(authelia.URL), zap.NewNop())
+
+			w := doLogin(h, map[string]interface{}{"email": "jane@example.com", "password": "secret"})
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.expectedRetryAfter {
+				t.Errorf("Expected Retry-After %q, got %q", tt.expectedRetryAfter, got)
+			}
+
+			var body map[string]map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &body)
+			if body["error"]["code"] != tt.expectedCode {
+				t.Errorf("Expected code %s, got %v", tt.expectedCode, body["error"]["code"])
+			}
+			if tt.expectedRetryAfter != "" && body["error"]["retry_after"] != float64(30) {
+				t.Errorf("Expected retry_after 30, got %v", body["error"]["retry_after"])
+			}
+			if strings.Contains(w.Body.String(), "token") {
+				t.Error("No token may be issued outside the authenticated path")
+			}
+		})
+	}
+}
+
+// TestAutheliaWebSocketQueryAuth verifies WebSocket upgrades authenticate via ?access_token= which is never forwarded
+func TestAutheliaWebSocketQueryAuth(t *testing.T) {
+	var handshake *http.Request
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handshake = r
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+	}))
+	t.Cleanup(upstream.Close)
+
+	h := handlers.NewAutheliaHandler(newAutheliaTestConfig(newOKAuthelia(t).URL), zap.NewNop())
+	token := loginToken(t, h, "jane@example.com")
+
+	cfg := &config.Config{}
+	cfg.ServiceURLs.Frontend = upstream.URL
+	proxyHandler := newTestProxyHandler(t, cfg, handlers.DefaultProxyOptions())
+
+	router := gin.New()
+	requireUser := func(c *gin.Context) {
+		if c.GetString("user_id") == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+		}
+	}
+	router.GET("/ws", h.WebSocketQueryAuth(), requireUser, proxyHandler.ProxyWithWebSocket("frontend"))
+	gateway := httptest.NewServer(router)
+	t.Cleanup(gateway.Close)
+
+	clientHeader := http.Header{
+		"Sec-Websocket-Key":     {"dGhlIHNhbXBsZSBub25jZQ=="},
+		"Sec-Websocket-Version": {"13"},
+	}
+
+	t.Run("valid query token", func(t *testing.T) {
+		_, resp := dialWebSocketConn(t, gateway, "/ws?room=1&access_token="+token, clientHeader)
+		if resp.StatusCode != http.StatusSwitchingProtocols {
+			t.Fatalf("Expected status %d, got %d", http.StatusSwitchingProtocols, resp.StatusCode)
+		}
+		if got := handshake.URL.Query(); got.Has("access_token") || got.Get("room") != "1" {
+			t.Errorf("Expected access_token stripped and other params kept, got %v", got)
+		}
+		if got := handshake.Header.Get("X-User-ID"); got != "jane" {
+			t.Errorf("Expected X-User-ID 'jane' forwarded, got '%s'", got)
+		}
+	})
+
+	t.Run("mixed-case Upgrade header", func(t *testing.T) {
+		header := http.Header{"Upgrade": {"WebSocket"}}
+		for key, values := range clientHeader {
+			header[key] = values
+		}
+		_, resp := dialWebSocketConn(t, gateway, "/ws?access_token="+token, header)
+		if resp.StatusCode != http.StatusSwitchingProtocols {
+			t.Fatalf("Expected status %d, got %d", http.StatusSwitchingProtocols, resp.StatusCode)
+		}
+		if handshake.URL.Query().Has("access_token") {
+			t.Errorf("Expected access_token stripped, got %v", handshake.URL.Query())
+		}
+	})
+
+	t.Run("invalid query token", func(t *testing.T) {
+		_, resp := dialWebSocketConn(t, gateway, "/ws?access_token=not-a-jwt", clientHeader)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("Expected status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+		}
+	})
+}
+
+// TestAutheliaLoginNonJSONErrors verifies text/HTML error bodies from Authelia are mapped by status, not reported as 500
+func TestAutheliaLoginNonJSONErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		body           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"text 401", http.StatusUnauthorized, "Unauthorized", http.StatusUnauthorized, "INVALID_CREDENTIALS"},
+		{"html 502", http.StatusBadGateway, "<html><body>502 Bad Gateway</body></html>", http.StatusBadGateway, "AUTH_SERVICE_UNAVAILABLE"},
+		{"text 429", http.StatusTooManyRequests, "Too Many Requests", http.StatusTooManyRequests, "RATE_LIMITED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authelia := newFakeAuthelia(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+			h := handlers.NewAutheliaHandler(newAutheliaTestConfig(authelia.URL), zap.NewNop())
+
+			w := doLogin(h, map[string]interface{}{"email": "jane@example.com", "password": "secret"})
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			var body map[string]map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &body)
+			if body["error"]["code"] != tt.expectedCode {
+				t.Errorf("Expected code %s, got %v", tt.expectedCode, body["error"]["code"])
+			}
+		})
+	}
+}
+
+// TestAutheliaSlidingSession verifies only tokens close to expiry are refreshed
+func TestAutheliaSlidingSession(t *testing.T) {
+	autheliaURL := newOKAuthelia(t).URL
+
+	newHandler := func(expiration, window time.Duration) *handlers.AutheliaHandler {
+		cfg := newAutheliaTestConfig(autheliaURL)
+		cfg.JWTExpiration = expiration
+		opts := handlers.DefaultAutheliaOptions()
+		opts.RefreshWindow = window
+		opts.RefreshTokenCookie = "gateway_token"
+		return handlers.NewAutheliaHandlerWithOptions(cfg, zap.NewNop(), opts)
+	}
+	request := func(h *handlers.AutheliaHandler, token string) *httptest.ResponseRecorder {
+		router := setupSessionsRouter(h)
+		router.Use(h.SlidingSession())
+		router.GET("/api/v1/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/ping", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("near expiry token is refreshed", func(t *testing.T) {
+		h := newHandler(time.Minute, 5*time.Minute)
+		token := loginToken(t, h, "jane@example.com")
+
+		w := request(h, token)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		refreshed := w.Header().Get("X-Refreshed-Token")
+		if refreshed == "" || refreshed == token {
+			t.Fatalf("Expected a new token in X-Refreshed-Token, got '%s'", refreshed)
+		}
+		claims, err := h.ValidateToken(refreshed)
+		if err != nil || claims.UserID != "jane" || claims.Email != "jane@example.com" {
+			t.Fatalf("Expected refreshed token for jane, got %+v (%v)", claims, err)
+		}
+		if !strings.Contains(w.Header().Get("Set-Cookie"), "gateway_token="+refreshed) {
+			t.Errorf("Expected refreshed token cookie, got '%s'", w.Header().Get("Set-Cookie"))
+		}
+		if _, err := h.ValidateToken(token); err != nil {
+			t.Errorf("Expected the replaced token to stay valid during the grace period: %v", err)
+		}
+
+		// The old token is refreshed once; later requests with it get no new token
+		if got := request(h, token).Header().Get("X-Refreshed-Token"); got != "" {
+			t.Errorf("Expected no second refresh of the same token, got '%s'", got)
+		}
+	})
+
+	t.Run("fresh token is not refreshed", func(t *testing.T) {
+		h := newHandler(time.Hour, 5*time.Minute)
+		w := request(h, loginToken(t, h, "jane@example.com"))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		if got := w.Header().Get("X-Refreshed-Token"); got != "" {
+			t.Errorf("Expected no refresh for a fresh token, got '%s'", got)
+		}
+	})
+
+	t.Run("expired token still fails", func(t *testing.T) {
+		h := newHandler(-time.Minute, 5*time.Minute)
+		w := request(h, loginToken(t, h, "jane@example.com"))
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+		if got := w.Header().Get("X-Refreshed-Token"); got != "" {
+			t.Errorf("Expected no refresh for an expired token, got '%s'", got)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		h := newHandler(time.Minute, 0)
+		if got := request(h, loginToken(t, h, "jane@example.com")).Header().Get("X-Refreshed-Token"); got != "" {
+			t.Errorf("Expected no refresh with RefreshWindow 0, got '%s'", got)
+		}
+	})
+}
+
+// fakeAccounts is an AccountDirectory over a fixed set of emails
+type fakeAccounts map[string]bool
+
+func (a fakeAccounts) EmailExists(ctx context.Context, email string) (bool, error) {
+	return a[email], nil
+}
+
+// TestAutheliaValidateEmail verifies format and availability checks and the generic answer over the limit
+func TestAutheliaValidateEmail(t *testing.T) {
+	opts := handlers.DefaultAutheliaOptions()
+	opts.Accounts = fakeAccounts{"taken@example.com": true}
+	opts.ValidateEmailLimiter = handlers.NewRateLimiter(handlers.NewMemoryStore(0), 3, time.Minute)
+	h := handlers.NewAutheliaHandlerWithOptions(newAutheliaTestConfig("http://authelia:9091"), zap.NewNop(), opts)
+
+	router := gin.New()
+	router.GET("/api/v1/auth/validate-email", h.ValidateEmail)
+	check := func(email string) handlers.ValidateEmailResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/validate-email?email="+url.QueryEscape(email), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		var resp handlers.ValidateEmailResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	tests := []struct {
+		name  string
+		email string
+		want  handlers.ValidateEmailResponse
+	}{
+		{"valid and available", "new@example.com", handlers.ValidateEmailResponse{ValidFormat: true, Available: true}},
+		{"taken", "Taken@Example.com", handlers.ValidateEmailResponse{ValidFormat: true, Available: false}},
+		{"malformed", "not-an-email", handlers.ValidateEmailResponse{ValidFormat: false, Available: false}},
+		{"empty", "", handlers.ValidateEmailResponse{ValidFormat: false, Available: false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := check(tt.email); got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	// Malformed emails never reach the directory, so two lookups used two of three
+	check("new@example.com")
+	if got := check("taken@example.com"); !got.Available {
+		t.Error("Expected availability to be withheld over the limit")
+	}
+}
+
+// TestAutheliaRequestIDPropagation verifies the client request ID (or a generated
+// one) reaches Authelia and is echoed to the client
+func TestAutheliaRequestIDPropagation(t *testing.T) {
+	var upstreamID atomic.Value
+	authelia := newFakeAuthelia(t, func(w http.ResponseWriter, r *http.Request) {
+		upstreamID.Store(r.Header.Get("X-Request-ID"))
+		http.SetCookie(w, &http.Cookie{Name: testSessionCookieName, Value: "session-value"})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"OK","data":{"display_name":"Jane"}}`))
+	})
+	h := handlers.NewAutheliaHandler(newAutheliaTestConfig(authelia.URL), zap.NewNop())
+
+	router := gin.New()
+	router.POST("/api/v1/auth/login", h.Login)
+	router.POST("/api/v1/auth/logout", h.Logout)
+	router.GET("/api/v1/auth/session", h.GetSession)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		clientID string
+	}{
+		{"login with client ID", http.MethodPost, "/api/v1/auth/login", `{"email":"jane@example.com","password":"secret"}`, "client-req-1"},
+		{"login without ID", http.MethodPost, "/api/v1/auth/login", `{"email":"jane@example.com","password":"secret"}`, ""},
+		{"session", http.MethodGet, "/api/v1/auth/session", "", "client-req-2"},
+		{"logout without ID", http.MethodPost, "/api/v1/auth/logout", "", ""},
+		{"unusable client ID", http.MethodGet, "/api/v1/auth/session", "", strings.Repeat("x", 200)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstreamID.Store("")
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.AddCookie(&http.Cookie{Name: testSessionCookieName, Value: "session-value"})
+			if tt.clientID != "" {
+				req.Header.Set("X-Request-ID", tt.clientID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			sent, _ := upstreamID.Load().(string)
+			if sent == "" {
+				t.Fatal("Expected X-Request-ID on the Authelia request")
+			}
+			if got := w.Header().Get("X-Request-ID"); got != sent {
+				t.Errorf("Expected response X-Request-ID '%s', got '%s'", sent, got)
+			}
+			if tt.clientID != "" && len(tt.clientID) <= 128 && sent != tt.clientID {
+				t.Errorf("Expected client ID '%s' forwarded, got '%s'", tt.clientID, sent)
+			}
+			if len(sent) > 128 {
+				t.Errorf("Expected an oversized client ID to be replaced, got %d chars", len(sent))
+			}
+		})
+	}
+}
+
+// TestAutheliaInternalURLNormalization verifies endpoint URLs are joined cleanly
+// and an Authelia URL without scheme is rejected
+func TestAutheliaInternalURLNormalization(t *testing.T) {
+	var hits atomic.Int32
+	var upstreamPath atomic.Value
+	authelia := newFakeAuthelia(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		upstreamPath.Store(r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"OK","data":{"display_name":"Jane"}}`))
+	})
+	host := strings.TrimPrefix(authelia.URL, "http://")
+
+	tests := []struct {
+		name         string
+		internalURL  string
+		expectStatus int
+		expectPath   string
+	}{
+		{"no trailing slash", authelia.URL, http.StatusOK, "/api/user/info"},
+		{"trailing slash", authelia.URL + "/", http.StatusOK, "/api/user/info"},
+		{"base path with trailing slash", authelia.URL + "/authelia/", http.StatusOK, "/authelia/api/user/info"},
+		{"missing scheme", host, http.StatusInternalServerError, ""},
+		{"missing scheme with path", host + "/authelia", http.StatusInternalServerError, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits.Store(0)
+			upstreamPath.Store("")
+			h := handlers.NewAutheliaHandler(newAutheliaTestConfig(tt.internalURL), zap.NewNop())
+			router := gin.New()
+			router.GET("/api/v1/auth/session", h.GetSession)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/session", nil)
+			req.AddCookie(&http.Cookie{Name: testSessionCookieName, Value: "session-value"})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectStatus, w.Code, w.Body.String())
+			}
+			if tt.expectPath == "" {
+				if hits.Load() != 0 {
+					t.Errorf("Expected no Authelia call for an invalid URL, got %d", hits.Load())
+				}
+				return
+			}
+			if got, _ := upstreamPath.Load().(string); got != tt.expectPath {
+				t.Errorf("Expected Authelia path '%s', got '%s'", tt.expectPath, got)
+			}
+		})
+	}
+}
+
+// TestAutheliaRefresh verifies a valid Authelia session renews the gateway JWT
+// with current groups and an expired session is rejected
+func TestAutheliaRefresh(t *testing.T) {
+	authelia := newFakeAuthelia(t, func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie(testSessionCookieName); err != nil || cookie.Value != "session-value" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"status":"KO","message":"Unauthorized"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/user/info":
+			w.Write([]byte(`{"status":"OK","data":{"display_name":"Jane","emails":["jane@example.com"],"groups":["admins","dev"]}}`))
+		case "/api/state":
+			w.Write([]byte(`{"status":"OK","data":{"username":"jane","authentication_level":1}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	h := handlers.NewAutheliaHandler(newAutheliaTestConfig(authelia.URL), zap.NewNop())
+	router := gin.New()
+	router.POST("/api/v1/auth/refresh", h.Refresh)
+
+	refresh := func(sessionCookie string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", nil)
+		if sessionCookie != "" {
+			req.AddCookie(&http.Cookie{Name: testSessionCookieName, Value: sessionCookie})
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("valid session", func(t *testing.T) {
+		w := refresh("session-value")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var body map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		token, _ := body["token"].(string)
+		claims, err := h.ValidateToken(token)
+		if err != nil {
+			t.Fatalf("Expected a valid token, got error: %v", err)
+		}
+		if claims.UserID != "jane" || claims.Email != "jane@example.com" {
+			t.Errorf("Expected claims for jane, got %+v", claims)
+		}
+		if len(claims.Roles) != 2 || claims.Roles[0] != "admins" || claims.Roles[1] != "dev" {
+			t.Errorf("Expected current groups as roles, got %v", claims.Roles)
+		}
+		if time.Until(claims.ExpiresAt.Time) < 59*time.Minute {
+			t.Errorf("Expected a renewed expiry, got %v", claims.ExpiresAt.Time)
+		}
+	})
+
+	t.Run("expired session", func(t *testing.T) {
+		w := refresh("expired-session")
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+		if strings.Contains(w.Body.String(), "token") {
+			t.Errorf("Expected no token for an expired session, got %s", w.Body.String())
+		}
+	})
+
+	t.Run("no session cookie", func(t *testing.T) {
+		if w := refresh(""); w.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+	})
+}
+
+// TestAutheliaInvalidResponseBodies verifies empty and oversized Authelia bodies map to a clear 502
+func TestAutheliaInvalidResponseBodies(t *testing.T) {
+	oversized := `{"status":"OK","data":"` + strings.Repeat("x", 2<<20) + `"}`
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"login empty 200", http.MethodPost, "/api/v1/auth/login", ""},
+		{"login oversized", http.MethodPost, "/api/v1/auth/login", oversized},
+		{"session empty 200", http.MethodGet, "/api/v1/auth/session", ""},
+		{"session oversized", http.MethodGet, "/api/v1/auth/session", oversized},
+		{"logout oversized", http.MethodPost, "/api/v1/auth/logout", oversized},
+		{"refresh empty 200", http.MethodPost, "/api/v1/auth/refresh", ""},
+		{"refresh oversized", http.MethodPost, "/api/v1/auth/refresh", oversized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authelia := newFakeAuthelia(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.body))
+			})
+
+			h := handlers.NewAutheliaHandler(newAutheliaTestConfig(authelia.URL), zap.NewNop())
+			router := gin.New()
+			router.POST("/api/v1/auth/login", h.Login)
+			router.GET("/api/v1/auth/session", h.GetSession)
+			router.POST("/api/v1/auth/logout", h.Logout)
+			router.POST("/api/v1/auth/refresh", h.Refresh)
+
+			var reqBody io.Reader
+			if tt.method == http.MethodPost {
+				reqBody = strings.NewReader(`{"email":"jane@example.com","password":"secret"}`)
+			}
+			req, _ := http.NewRequest(tt.method, tt.path, reqBody)
+			req.Header.Set("Content-Type", "application/json")
+			req.AddCookie(&http.Cookie{Name: testSessionCookieName, Value: "session-abc"})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadGateway {
+				t.Fatalf("Expected status %d, got %d: %s", http.StatusBadGateway, w.Code, w.Body.String())
+			}
+			var body map[string]map[string]string
+			json.Unmarshal(w.Body.Bytes(), &body)
+			if body["error"]["code"] != "AUTH_SERVICE_ERROR" {
+				t.Errorf("Expected error code AUTH_SERVICE_ERROR, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+// fakeChallengeVerifier accepts only the "solved" CAPTCHA token
+type fakeChallengeVerifier struct {
+	calls atomic.Int32
+}
+
+func (v *fakeChallengeVerifier) VerifyChallenge(_ context.Context, token, _ string) (bool, error) {
+	v.calls.Add(1)
+	return token == "solved", nil
+}
+
+// TestAutheliaLoginChallenge verifies a CAPTCHA is required once failed logins reach the threshold
+func TestAutheliaLoginChallenge(t *testing.T) {
+	var autheliaCalls atomic.Int32
+	authelia := newFakeAuthelia(t, func(w http.ResponseWriter, r *http.Request) {
+		autheliaCalls.Add(1)
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		if req["password"] != "right" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":"KO","message":"Authentication failed"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"OK"}`))
+	})
+
+	verifier := &fakeChallengeVerifier{}
+	opts := handlers.DefaultAutheliaOptions()
+	opts.LoginChallenge = &handlers.LoginChallengeConfig{Threshold: 2, Verifier: verifier}
+	h := handlers.NewAutheliaHandlerWithOptions(newAutheliaTestConfig(authelia.URL), zap.NewNop(), opts)
+
+	login := func(password, captchaToken string) *httptest.ResponseRecorder {
+		return doLogin(h, map[string]interface{}{
+			"email":         "jane@example.com",
+			"password":      password,
+			"captcha_token": captchaToken,
+		})
+	}
+	errorCode := func(w *httptest.ResponseRecorder) string {
+		var body map[string]map[string]string
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return body["error"]["code"]
+	}
+
+	// Below the threshold no CAPTCHA is needed
+	for i := 0; i < 2; i++ {
+		if w := login("wrong", ""); w.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected status %d below the threshold, got %d: %s", http.StatusUnauthorized, w.Code, w.Body.String())
+		}
+	}
+	if verifier.calls.Load() != 0 {
+		t.Errorf("Expected no CAPTCHA verification below the threshold, got %d", verifier.calls.Load())
+	}
+
+	// At the threshold the token is required before Authelia is called
+	calls := autheliaCalls.Load()
+	w := login("right", "")
+	if w.Code != http.StatusPreconditionRequired || errorCode(w) != "CAPTCHA_REQUIRED" {
+		t.Fatalf("Expected %d CAPTCHA_REQUIRED, got %d: %s", http.StatusPreconditionRequired, w.Code, w.Body.String())
+	}
+	w = login("right", "bogus")
+	if w.Code != http.StatusPreconditionRequired || errorCode(w) != "CAPTCHA_INVALID" {
+		t.Fatalf("Expected %d CAPTCHA_INVALID, got %d: %s", http.StatusPreconditionRequired, w.Code, w.Body.String())
+	}
+	if autheliaCalls.Load() != calls {
+		t.Errorf("Expected Authelia not called without a valid CAPTCHA")
+	}
+
+	// A verified CAPTCHA lets the login through
+	if w := login("right", "solved"); w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d with a verified CAPTCHA, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// A correct password clears the email's count, but the client IP still has
+	// its failures, so the CAPTCHA stays required from this address
+	if w := login("right", ""); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("Expected the client IP to still need a CAPTCHA, got %d", w.Code)
+	}
+}
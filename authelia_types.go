@@ -19,6 +19,8 @@ type AutheliaLoginRequest struct {
 	Password       string `json:"password" binding:"required,min=1"`
 	KeepMeLoggedIn bool   `json:"keepMeLoggedIn"`
 	TargetURL      string `json:"targetURL,omitempty"`
+	// CaptchaToken is required once LoginChallenge's failure threshold is reached
+	CaptchaToken string `json:"captcha_token,omitempty"`
 }
 
 // autheliaFirstFactorRequest is the internal format for Authelia /api/firstfactor
@@ -57,3 +59,34 @@ type autheliaUserInfo struct {
 	Email    string
 	Groups   []string
 }
+
+// AutheliaTOTPRequest represents the second factor TOTP request body
+type AutheliaTOTPRequest struct {
+	Token     string `json:"token" binding:"required,len=6,numeric"`
+	TargetURL string `json:"targetURL,omitempty"`
+}
+
+// autheliaTOTPRequest is the internal format for Authelia /api/secondfactor/totp
+type autheliaTOTPRequest struct {
+	Token     string `json:"token"`
+	TargetURL string `json:"targetURL,omitempty"`
+}
+
+// autheliaStateResponse is the internal format for Authelia /api/state
+type autheliaStateResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		Username            string `json:"username"`
+		AuthenticationLevel int    `json:"authentication_level"`
+	} `json:"data"`
+}
+
+// autheliaUserInfoResponse is the internal format for Authelia /api/user/info
+type autheliaUserInfoResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		DisplayName string   `json:"display_name"`
+		Emails      []string `json:"emails"`
+		Groups      []string `json:"groups"`
+	} `json:"data"`
+}
@@ -0,0 +1,90 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file implements the email pre-check used by the registration form.
+//
+// Associated Frontend Files:
+//   - web/app/src/pages/RegisterPage.tsx (inline email validation)
+//   - web/app/src/lib/api.ts (apiClient.get for validate-email)
+//
+// Account lookups go through AccountDirectory (the user service); the gateway
+// never queries the user database itself (ADR-0010).
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.uber.org/zap"
+)
+
+// AccountDirectory looks up existing user accounts
+type AccountDirectory interface {
+	// EmailExists reports whether an account already uses email (lowercased)
+	EmailExists(ctx context.Context, email string) (bool, error)
+}
+
+// ValidateEmailResponse is the result of an email pre-check
+type ValidateEmailResponse struct {
+	ValidFormat bool `json:"valid_format"`
+	Available   bool `json:"available"`
+}
+
+// emailFormat applies the binding rules of LoginRequest.Email
+type emailFormat struct {
+	Email string `binding:"required,email"`
+}
+
+// ValidateEmail reports whether an email is well-formed and not yet registered
+// @Summary Validate email
+// @Description Checks an email's format with the same rules as request binding and whether an account already uses it. Rate limited per client; over the limit "available" is always true so the endpoint cannot be used to enumerate accounts.
+// @Tags Authentication
+// @Produce json
+// @Param email query string true "Email address to check"
+// @Success 200 {object} ValidateEmailResponse "Format and availability"
+// @Router /api/v1/auth/validate-email [get]
+func (h *AutheliaHandler) ValidateEmail(c *gin.Context) {
+	email := strings.TrimSpace(c.Query("email"))
+	resp := ValidateEmailResponse{
+		ValidFormat: binding.Validator.ValidateStruct(emailFormat{Email: email}) == nil,
+	}
+	if !resp.ValidFormat {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
+	// Without a directory, or over the limit, every well-formed email is reported
+	// available; registration still rejects duplicates
+	resp.Available = true
+	if h.options.Accounts == nil || !h.allowEmailLookup(c) {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
+	exists, err := h.options.Accounts.EmailExists(c.Request.Context(), strings.ToLower(email))
+	if err != nil {
+		h.logger.Error("Failed to look up email availability", zap.Error(err))
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+	resp.Available = !exists
+	c.JSON(http.StatusOK, resp)
+}
+
+// allowEmailLookup counts a lookup against the client's ValidateEmailLimiter
+// budget. Store errors fail open, like RateLimit.
+func (h *AutheliaHandler) allowEmailLookup(c *gin.Context) bool {
+	if h.options.ValidateEmailLimiter == nil {
+		return true
+	}
+	allowed, _, err := h.options.ValidateEmailLimiter.Allow(c.Request.Context(), "validate-email:"+RealClientIP(c))
+	if err != nil {
+		return true
+	}
+	if !allowed {
+		h.logger.Warn("Email validation rate limit exceeded", zap.String("client_ip", RealClientIP(c)))
+	}
+	return allowed
+}
@@ -0,0 +1,176 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file implements WebAuthn (passkey / hardware key) second factor
+// passthrough handlers for Authelia authentication.
+//
+// Associated Frontend Files:
+//   - web/app/src/pages/LoginPage.tsx (security key prompt after first factor)
+//   - web/app/src/hooks/useAuth.ts (navigator.credentials.get round trip)
+//
+// Architecture:
+//   Browser -> API Gateway (:8080) -> Authelia (:9091 internal) -> Redis (sessions)
+//
+// Flow (with AutheliaOptions.RequireSecondFactor):
+//   1. POST /api/v1/auth/login                -> first factor, session cookie, no JWT
+//   2. GET  /api/v1/auth/webauthn/assertion   -> challenge (PublicKeyCredentialRequestOptions)
+//   3. POST /api/v1/auth/webauthn/assertion   -> signed assertion; on success the JWT is issued
+//
+// WebAuthn payloads carry base64url-encoded binary fields (challenge,
+// credential IDs, authenticatorData, signature). The gateway never decodes
+// them: request and response bodies are forwarded byte for byte.
+//
+// See: agent/docs/network-topology/api-gateway-topology.mmd
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	autheliaWebAuthnStartPath     = "/api/secondfactor/webauthn/identity/start"
+	autheliaWebAuthnAssertionPath = "/api/secondfactor/webauthn/assertion"
+)
+
+// StartWebAuthn starts the WebAuthn identity verification via internal Authelia
+// @Summary Start WebAuthn identity verification
+// @Description Proxy Authelia's WebAuthn identity start endpoint for the pending session
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Authelia response (passthrough)"
+// @Failure 401 {object} map[string]interface{} "No pending session"
+// @Failure 502 {object} map[string]interface{} "Auth service unavailable"
+// @Router /api/v1/auth/webauthn/start [post]
+func (h *AutheliaHandler) StartWebAuthn(c *gin.Context) {
+	defer h.options.Metrics.observeAuth(c, "webauthn_start", time.Now())
+	h.passthroughWebAuthn(c, http.MethodPost, autheliaWebAuthnStartPath)
+}
+
+// GetWebAuthnAssertionOptions returns the WebAuthn assertion challenge via internal Authelia
+// @Summary Get WebAuthn assertion options
+// @Description Proxy Authelia's WebAuthn assertion challenge for the pending session
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} map[string]interface{} "PublicKeyCredentialRequestOptions (passthrough)"
+// @Failure 401 {object} map[string]interface{} "No pending session"
+// @Failure 502 {object} map[string]interface{} "Auth service unavailable"
+// @Router /api/v1/auth/webauthn/assertion [get]
+func (h *AutheliaHandler) GetWebAuthnAssertionOptions(c *gin.Context) {
+	defer h.options.Metrics.observeAuth(c, "webauthn_options", time.Now())
+	h.passthroughWebAuthn(c, http.MethodGet, autheliaWebAuthnAssertionPath)
+}
+
+// VerifyWebAuthnAssertion completes login with a signed WebAuthn assertion via internal Authelia
+// @Summary Verify WebAuthn assertion
+// @Description Forward the signed WebAuthn assertion to Authelia and issue the JWT on success
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Success 200 {object} AutheliaLoginResponse "Second factor accepted"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 401 {object} map[string]interface{} "Assertion rejected or no pending session"
+// @Failure 502 {object} map[string]interface{} "Auth service unavailable"
+// @Router /api/v1/auth/webauthn/assertion [post]
+func (h *AutheliaHandler) VerifyWebAuthnAssertion(c *gin.Context) {
+	defer h.options.Metrics.observeAuth(c, "webauthn", time.Now())
+
+	var assertion json.RawMessage
+	if err := decodeJSONBody(c, &assertion, h.jsonBodyOptions()); err != nil {
+		h.logger.Warn("Invalid WebAuthn assertion request", zap.Error(err))
+		sendJSONBodyError(c, err)
+		return
+	}
+
+	sessionCookie, err := c.Cookie(h.config.Authelia.SessionCookieName)
+	if err != nil {
+		sendUnauthorizedError(c)
+		return
+	}
+
+	resp, err := h.doAutheliaRequest(c, http.MethodPost, autheliaWebAuthnAssertionPath, sessionCookie, assertion)
+	if err != nil {
+		h.logger.Error("Authelia WebAuthn assertion request failed", zap.Error(err))
+		sendBadGatewayError(c)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := readAutheliaResponse(resp)
+	if err != nil {
+		h.logger.Error("Failed to read Authelia WebAuthn assertion response", logFields(c, zap.Error(err))...)
+		sendInvalidAuthResponseError(c)
+		return
+	}
+
+	// Only a successful response must be JSON; errors are mapped by status code alone
+	var assertionResp autheliaFirstFactorResponse
+	if err := json.Unmarshal(body, &assertionResp); err != nil && resp.StatusCode == http.StatusOK {
+		h.logger.Error("Failed to parse Authelia WebAuthn assertion response", logFields(c, zap.Error(err))...)
+		sendInvalidAuthResponseError(c)
+		return
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK && assertionResp.Status == "OK":
+		h.forwardSessionCookies(c, resp)
+		h.completeSecondFactor(c, h.rotatedSessionCookie(resp, sessionCookie), assertionResp.Data.Redirect)
+
+	case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		h.logger.Warn("WebAuthn assertion rejected", zap.Int("status", resp.StatusCode))
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_WEBAUTHN_ASSERTION",
+				"message": "Security key verification failed",
+			},
+		})
+
+	default:
+		h.logger.Error("Unexpected Authelia WebAuthn response", zap.Int("status", resp.StatusCode))
+		sendAuthServiceError(c)
+	}
+}
+
+// passthroughWebAuthn forwards a WebAuthn request to Authelia and relays the response unchanged
+func (h *AutheliaHandler) passthroughWebAuthn(c *gin.Context, method, path string) {
+	var payload json.RawMessage
+	if method != http.MethodGet && c.Request.ContentLength != 0 {
+		if err := decodeJSONBody(c, &payload, h.jsonBodyOptions()); err != nil {
+			h.logger.Warn("Invalid WebAuthn request", zap.Error(err))
+			sendJSONBodyError(c, err)
+			return
+		}
+	}
+
+	sessionCookie, err := c.Cookie(h.config.Authelia.SessionCookieName)
+	if err != nil {
+		sendUnauthorizedError(c)
+		return
+	}
+
+	resp, err := h.doAutheliaRequest(c, method, path, sessionCookie, payload)
+	if err != nil {
+		h.logger.Error("Authelia WebAuthn request failed", zap.String("path", path), zap.Error(err))
+		sendBadGatewayError(c)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := readAutheliaResponse(resp)
+	if err != nil {
+		h.logger.Error("Failed to read Authelia WebAuthn response", zap.Error(err))
+		sendInvalidAuthResponseError(c)
+		return
+	}
+
+	h.forwardSessionCookies(c, resp)
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	c.Data(resp.StatusCode, contentType, body)
+}
@@ -0,0 +1,60 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file implements gateway JWT authentication for WebSocket upgrades.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (appends ?access_token= to WebSocket URLs)
+//
+// Browsers cannot set an Authorization header on a WebSocket handshake, so
+// upgrades may carry the JWT in a query parameter instead. The parameter is
+// never forwarded upstream.
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// WebSocketQueryAuth returns a middleware authenticating WebSocket upgrade requests
+// from the WebSocketTokenParam query parameter. The token is validated like a header
+// token (ValidateToken) and user_id, email and roles are set in the context as the
+// auth middleware does. The Authorization header stays primary: when present, the
+// query token is ignored. Non-upgrade requests are passed through untouched.
+func (h *AutheliaHandler) WebSocketQueryAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		param := h.options.WebSocketTokenParam
+		if param == "" || !isWebSocketUpgrade(c) {
+			c.Next()
+			return
+		}
+
+		// Strip the token before anything can forward or log the URL
+		query := c.Request.URL.Query()
+		token := query.Get(param)
+		if !query.Has(param) {
+			c.Next()
+			return
+		}
+		query.Del(param)
+		c.Request.URL.RawQuery = query.Encode()
+		c.Request.RequestURI = c.Request.URL.RequestURI()
+
+		if c.GetHeader("Authorization") != "" || token == "" {
+			c.Next()
+			return
+		}
+
+		claims, err := h.ValidateToken(token)
+		if err != nil {
+			h.logger.Warn("Rejected WebSocket query token", zap.Error(err))
+			sendUnauthorizedError(c)
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", claims.UserID)
+		c.Set("email", claims.Email)
+		c.Set("roles", claims.Roles)
+		c.Next()
+	}
+}
@@ -0,0 +1,240 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file implements opt-in request/response body logging with JSON field
+// masking, for debugging traffic without leaking secrets into the logs.
+//
+// Associated Frontend Files:
+//   - None (debug logging only)
+//
+// Bodies are only logged when the BodyLogger middleware is mounted. Login
+// endpoints always mask credentials, whatever MaskFields says, and their
+// bodies are dropped entirely when they cannot be parsed and masked.
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maskedValue replaces masked JSON field values in logged bodies
+const maskedValue = "***"
+
+// loginBodyMaskFields are always masked on login endpoints
+var loginBodyMaskFields = []string{"password", "current_password", "new_password", "confirm_password", "token"}
+
+// BodyLogConfig configures the BodyLogger middleware
+type BodyLogConfig struct {
+	// MaskFields lists JSON paths whose values are replaced with "***".
+	// A single name (e.g. "password") matches that key at any depth; a dotted
+	// path (e.g. "user.profile.ssn", "*" matching any key) is matched from the
+	// document root. Arrays are traversed, so "items.secret" masks secret in
+	// every element of items.
+	MaskFields []string
+	// MaxBodyBytes caps captured bodies; larger bodies are not logged
+	MaxBodyBytes int
+	// LogResponses also logs response bodies
+	LogResponses bool
+	// LoginPaths are path prefixes whose bodies are only ever logged masked
+	LoginPaths []string
+}
+
+// DefaultBodyLogConfig masks credential fields and treats the auth endpoints as login paths
+func DefaultBodyLogConfig() BodyLogConfig {
+	return BodyLogConfig{
+		MaskFields:   append([]string(nil), loginBodyMaskFields...),
+		MaxBodyBytes: 64 << 10,
+		LogResponses: true,
+		LoginPaths:   []string{"/api/v1/auth/login", "/api/v1/auth/totp", "/api/v1/auth/webauthn"},
+	}
+}
+
+// BodyLogger returns a middleware logging request (and optionally response)
+// bodies at Debug level, with MaskFields masked in JSON bodies. Non-JSON
+// bodies are logged as-is, except on login paths where they are omitted.
+func BodyLogger(logger *zap.Logger, cfg BodyLogConfig) gin.HandlerFunc {
+	masker := newJSONMasker(cfg.MaskFields)
+	loginMasker := newJSONMasker(append(append([]string(nil), cfg.MaskFields...), loginBodyMaskFields...))
+
+	return func(c *gin.Context) {
+		login := hasPathPrefix(c.Request.URL.Path, cfg.LoginPaths)
+		m := masker
+		if login {
+			m = loginMasker
+		}
+
+		var requestBody []byte
+		requestTooLarge := false
+		if c.Request.Body != nil {
+			// Peek at most MaxBodyBytes+1 and hand the handler the full, unread stream
+			captured, _ := io.ReadAll(io.LimitReader(c.Request.Body, int64(cfg.MaxBodyBytes)+1))
+			c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(captured), c.Request.Body), c.Request.Body}
+			requestTooLarge = len(captured) > cfg.MaxBodyBytes
+			if !requestTooLarge {
+				requestBody = captured
+			}
+		}
+
+		var writer *bodyCaptureWriter
+		if cfg.LogResponses {
+			writer = &bodyCaptureWriter{ResponseWriter: c.Writer, max: cfg.MaxBodyBytes}
+			c.Writer = writer
+		}
+
+		c.Next()
+
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.Int("status", c.Writer.Status()),
+		}
+		if body, ok := maskBody(m, requestBody, login); ok {
+			fields = append(fields, zap.String("request_body", body))
+		} else if requestTooLarge {
+			fields = append(fields, zap.Bool("request_body_omitted", true))
+		}
+		if writer != nil {
+			if body, ok := maskBody(m, writer.body.Bytes(), login); ok && !writer.truncated {
+				fields = append(fields, zap.String("response_body", body))
+			} else if writer.truncated {
+				fields = append(fields, zap.Bool("response_body_omitted", true))
+			}
+		}
+		logger.Debug("Request body", fields...)
+	}
+}
+
+// maskBody returns the loggable form of body, or false if it must not be logged
+func maskBody(m *jsonMasker, body []byte, strict bool) (string, bool) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return "", false
+	}
+	masked, err := m.mask(body)
+	if err != nil {
+		if strict {
+			return "", false
+		}
+		return string(body), true
+	}
+	return string(masked), true
+}
+
+// jsonMasker replaces configured JSON fields with maskedValue
+type jsonMasker struct {
+	anyDepth map[string]bool
+	rooted   [][]string
+}
+
+func newJSONMasker(fields []string) *jsonMasker {
+	m := &jsonMasker{anyDepth: make(map[string]bool)}
+	for _, field := range fields {
+		if field == "" {
+			continue
+		}
+		if !strings.Contains(field, ".") {
+			m.anyDepth[field] = true
+			continue
+		}
+		m.rooted = append(m.rooted, strings.Split(field, "."))
+	}
+	return m
+}
+
+// mask returns body re-encoded with masked fields; it fails on invalid JSON
+func (m *jsonMasker) mask(body []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(m.walk(doc, nil))
+}
+
+func (m *jsonMasker) walk(value interface{}, path []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := append(path[:len(path):len(path)], key)
+			if m.anyDepth[key] || m.matchesRooted(childPath) {
+				v[key] = maskedValue
+				continue
+			}
+			v[key] = m.walk(child, childPath)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = m.walk(child, path)
+		}
+	}
+	return value
+}
+
+func (m *jsonMasker) matchesRooted(path []string) bool {
+	for _, rooted := range m.rooted {
+		if len(rooted) != len(path) {
+			continue
+		}
+		matched := true
+		for i, segment := range rooted {
+			if segment != "*" && segment != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPathPrefix reports whether path equals or is below one of prefixes
+func hasPathPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// readCloser pairs a replacement reader with the original body's Close
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyCaptureWriter keeps a copy of up to max response bytes
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (w *bodyCaptureWriter) capture(data []byte) {
+	if w.truncated {
+		return
+	}
+	if w.body.Len()+len(data) > w.max {
+		w.truncated = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(data)
+}
+
+func (w *bodyCaptureWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
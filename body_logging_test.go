@@ -0,0 +1,125 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugjb/api-gateway/handlers"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// setupBodyLoggerRouter creates a router with BodyLogger echoing request bodies back
+func setupBodyLoggerRouter(cfg handlers.BodyLogConfig) (*gin.Engine, *observer.ObservedLogs, *string) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	received := new(string)
+
+	router := gin.New()
+	router.Use(handlers.BodyLogger(zap.New(core), cfg))
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		*received = string(body)
+		c.JSON(http.StatusOK, gin.H{"token": "jwt-secret", "user": gin.H{"email": "user@example.com"}})
+	}
+	router.POST("/api/v1/auth/login", echo)
+	router.POST("/api/v1/profile", echo)
+	return router, logs, received
+}
+
+// postBody posts body to path and returns the logged request and response bodies
+func postBody(t *testing.T, router *gin.Engine, logs *observer.ObservedLogs, path, body string) (string, string) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	entries := logs.TakeAll()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 body log entry, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	requestBody, _ := fields["request_body"].(string)
+	responseBody, _ := fields["response_body"].(string)
+	return requestBody, responseBody
+}
+
+// decodeLogged decodes a logged JSON body
+func decodeLogged(t *testing.T, body string) map[string]interface{} {
+	t.Helper()
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatalf("Expected logged JSON body, got '%s': %v", body, err)
+	}
+	return doc
+}
+
+// TestBodyLoggerMasksLoginBody verifies credentials are never logged on login paths
+func TestBodyLoggerMasksLoginBody(t *testing.T) {
+	// Even with no configured mask fields, login bodies are masked
+	cfg := handlers.DefaultBodyLogConfig()
+	cfg.MaskFields = nil
+	router, logs, received := setupBodyLoggerRouter(cfg)
+
+	body := `{"email":"user@example.com","password":"hunter2"}`
+	requestBody, responseBody := postBody(t, router, logs, "/api/v1/auth/login", body)
+
+	if *received != body {
+		t.Errorf("Expected handler to receive the unmasked body, got '%s'", *received)
+	}
+	if strings.Contains(requestBody, "hunter2") {
+		t.Fatalf("Password leaked into logs: %s", requestBody)
+	}
+	logged := decodeLogged(t, requestBody)
+	if logged["password"] != "***" || logged["email"] != "user@example.com" {
+		t.Errorf("Expected password masked and email kept, got %v", logged)
+	}
+	if strings.Contains(responseBody, "jwt-secret") || decodeLogged(t, responseBody)["token"] != "***" {
+		t.Errorf("Expected response token masked, got %s", responseBody)
+	}
+
+	// Bodies that cannot be masked are dropped on login paths
+	requestBody, _ = postBody(t, router, logs, "/api/v1/auth/login", `password=hunter2`)
+	if requestBody != "" {
+		t.Errorf("Expected unparseable login body omitted, got '%s'", requestBody)
+	}
+}
+
+// TestBodyLoggerMaskPaths verifies nested objects, arrays and rooted paths are masked
+func TestBodyLoggerMaskPaths(t *testing.T) {
+	cfg := handlers.DefaultBodyLogConfig()
+	cfg.MaskFields = []string{"secret", "user.profile.ssn"}
+	router, logs, _ := setupBodyLoggerRouter(cfg)
+
+	body := `{"items":[{"secret":"a","name":"one"},{"nested":{"secret":"b"}}],` +
+		`"user":{"profile":{"ssn":"123","city":"Hanoi"}},"ssn":"top-level"}`
+	requestBody, _ := postBody(t, router, logs, "/api/v1/profile", body)
+	logged := decodeLogged(t, requestBody)
+
+	items := logged["items"].([]interface{})
+	if items[0].(map[string]interface{})["secret"] != "***" || items[0].(map[string]interface{})["name"] != "one" {
+		t.Errorf("Expected secret masked in array element, got %v", items[0])
+	}
+	if items[1].(map[string]interface{})["nested"].(map[string]interface{})["secret"] != "***" {
+		t.Errorf("Expected nested secret masked, got %v", items[1])
+	}
+	profile := logged["user"].(map[string]interface{})["profile"].(map[string]interface{})
+	if profile["ssn"] != "***" || profile["city"] != "Hanoi" {
+		t.Errorf("Expected rooted path masked only, got %v", profile)
+	}
+	if logged["ssn"] != "top-level" {
+		t.Errorf("Expected rooted path not to match elsewhere, got %v", logged["ssn"])
+	}
+
+	// Non-JSON bodies outside login paths are logged as-is
+	requestBody, _ = postBody(t, router, logs, "/api/v1/profile", "plain text")
+	if requestBody != "plain text" {
+		t.Errorf("Expected plain body logged, got '%s'", requestBody)
+	}
+}
@@ -0,0 +1,72 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains client IP resolution and trusted proxy configuration.
+//
+// Associated Frontend Files:
+//   - None (client IP is derived from the connection, not the frontend)
+//
+// Architecture:
+//   Browser -> [Load Balancer] -> API Gateway (:8080) -> Backend services
+//
+// Precedence:
+//   - If the immediate peer (RemoteAddr) is a trusted proxy, the client IP is
+//     taken from X-Forwarded-For (walking right to left, skipping trusted hops),
+//     then X-Real-IP.
+//   - Otherwise forwarding headers are ignored and RemoteAddr is used, so
+//     clients cannot spoof their IP by sending X-Forwarded-For directly.
+package handlers
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConfigureTrustedProxies configures client IP resolution on the gin engine.
+// trustedProxies lists IPs or CIDRs of the proxies in front of the gateway
+// (e.g. the load balancer). An empty list trusts no proxy, which makes
+// forwarding headers ignored. This must be called before serving requests.
+func ConfigureTrustedProxies(engine *gin.Engine, trustedProxies []string) error {
+	engine.ForwardedByClientIP = true
+	engine.RemoteIPHeaders = []string{"X-Forwarded-For", "X-Real-IP"}
+
+	if len(trustedProxies) == 0 {
+		// Gin trusts every proxy by default, which makes X-Forwarded-For spoofable
+		return engine.SetTrustedProxies(nil)
+	}
+	return engine.SetTrustedProxies(trustedProxies)
+}
+
+// RealClientIP returns the resolved client IP for the request.
+// All handlers must use this instead of calling c.ClientIP() directly so
+// forwarding, logging and rate limiting agree on the same address.
+func RealClientIP(c *gin.Context) string {
+	if ip := c.ClientIP(); ip != "" {
+		return ip
+	}
+
+	// Fall back to the raw peer address (e.g. engine not configured)
+	if host, _, err := net.SplitHostPort(c.Request.RemoteAddr); err == nil {
+		return host
+	}
+	return c.Request.RemoteAddr
+}
+
+// fromTrustedProxy reports whether the immediate peer (RemoteIP) is one of the
+// trusted proxies configured by ConfigureTrustedProxies. Gin keeps the trusted
+// CIDRs private, so it is asked to resolve a probe forwarding header, which it
+// only honors from a trusted peer. This holds whether or not the proxy sent
+// X-Forwarded-For itself.
+func fromTrustedProxy(c *gin.Context) bool {
+	probeIP := "192.0.2.254" // TEST-NET-1, never a real peer
+	if c.RemoteIP() == probeIP {
+		probeIP = "192.0.2.253"
+	}
+	probe := c.Copy()
+	probe.Request = &http.Request{
+		RemoteAddr: c.Request.RemoteAddr,
+		Header:     http.Header{"X-Forwarded-For": {probeIP}, "X-Real-Ip": {probeIP}},
+	}
+	return probe.ClientIP() == probeIP
+}
@@ -0,0 +1,86 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugjb/api-gateway/handlers"
+)
+
+// setupClientIPRouter creates a router echoing the resolved client IP
+func setupClientIPRouter(t *testing.T, trustedProxies []string) *gin.Engine {
+	router := gin.New()
+	if err := handlers.ConfigureTrustedProxies(router, trustedProxies); err != nil {
+		t.Fatalf("Failed to configure trusted proxies: %v", err)
+	}
+
+	router.GET("/ip", func(c *gin.Context) {
+		c.String(http.StatusOK, handlers.RealClientIP(c))
+	})
+
+	return router
+}
+
+// TestRealClientIP verifies client IP resolution for direct and proxied requests
+func TestRealClientIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		expected       string
+	}{
+		{
+			name:       "direct connection",
+			remoteAddr: "203.0.113.7:51234",
+			expected:   "203.0.113.7",
+		},
+		{
+			name:         "direct connection ignores spoofed X-Forwarded-For",
+			remoteAddr:   "203.0.113.7:51234",
+			forwardedFor: "198.51.100.1",
+			expected:     "203.0.113.7",
+		},
+		{
+			name:           "trusted proxy uses X-Forwarded-For",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.2:443",
+			forwardedFor:   "198.51.100.1",
+			expected:       "198.51.100.1",
+		},
+		{
+			name:           "trusted proxy chain skips trusted hops",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.2:443",
+			forwardedFor:   "192.0.2.9, 198.51.100.1, 10.0.0.5",
+			expected:       "198.51.100.1",
+		},
+		{
+			name:           "untrusted peer with trusted list configured",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "203.0.113.7:51234",
+			forwardedFor:   "198.51.100.1",
+			expected:       "203.0.113.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupClientIPRouter(t, tt.trustedProxies)
+
+			req, _ := http.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if got := w.Body.String(); got != tt.expected {
+				t.Errorf("Expected client IP '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
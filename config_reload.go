@@ -0,0 +1,115 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file implements runtime configuration reloads (SIGHUP or the admin
+// reload endpoint) without restarting the gateway.
+//
+// Associated Frontend Files:
+//   - web/app/src/pages/AdminPage.tsx (admin tools - reload configuration)
+//
+// Reloads replace the configuration as a whole: each request resolves its
+// upstream from one snapshot, so requests in flight during a reload finish
+// against the upstream they started with. Only handlers registered with
+// OnReload see the new configuration; settings read once at startup (JWT
+// secret, Authelia cookie settings, ProxyOptions) still require a restart.
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/ugjb/api-gateway/config"
+	"go.uber.org/zap"
+)
+
+// ConfigReloader reloads the gateway configuration (implemented by ConfigStore)
+type ConfigReloader interface {
+	ReloadConfig() error
+}
+
+// ConfigLoader reads a fresh configuration, e.g. from the environment or a file
+type ConfigLoader func() (*config.Config, error)
+
+// ConfigStore holds the live configuration and swaps it on reload
+type ConfigStore struct {
+	current    atomic.Pointer[config.Config]
+	load       ConfigLoader
+	production bool
+	logger     *zap.Logger
+
+	// mu serializes reloads so listeners see configurations in order
+	mu        sync.Mutex
+	listeners []func(*config.Config)
+}
+
+// NewConfigStore creates a ConfigStore serving cfg until the first reload.
+// Reloaded configurations must pass ValidateConfig with the given production flag.
+func NewConfigStore(cfg *config.Config, load ConfigLoader, production bool, logger *zap.Logger) *ConfigStore {
+	s := &ConfigStore{
+		load:       load,
+		production: production,
+		logger:     logger,
+	}
+	s.current.Store(cfg)
+	return s
+}
+
+// Config returns the current configuration; callers must not modify it
+func (s *ConfigStore) Config() *config.Config {
+	return s.current.Load()
+}
+
+// OnReload registers fn to receive every configuration accepted by ReloadConfig,
+// e.g. ProxyHandler.UpdateConfig
+func (s *ConfigStore) OnReload(fn func(*config.Config)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// ReloadConfig loads and validates a new configuration and, if valid, swaps it
+// in and notifies the OnReload listeners. On any error the current
+// configuration stays in effect.
+func (s *ConfigStore) ReloadConfig() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cfg, err := s.load()
+	if err != nil {
+		s.logger.Error("Failed to load configuration", zap.Error(err))
+		return fmt.Errorf("load config: %w", err)
+	}
+	if err := ValidateConfig(cfg, s.production); err != nil {
+		s.logger.Error("Rejected invalid configuration", zap.Error(err))
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	s.current.Store(cfg)
+	for _, fn := range s.listeners {
+		fn(cfg)
+	}
+	s.logger.Info("Configuration reloaded")
+	return nil
+}
+
+// WatchSignals reloads the configuration on every SIGHUP until ctx is done
+func (s *ConfigStore) WatchSignals(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				// Failures are logged by ReloadConfig; the old configuration stays
+				_ = s.ReloadConfig()
+			}
+		}
+	}()
+}
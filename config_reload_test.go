@@ -0,0 +1,162 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugjb/api-gateway/config"
+	"github.com/ugjb/api-gateway/handlers"
+	"go.uber.org/zap"
+)
+
+// TestConfigReloadSwapsServiceURL verifies a reload redirects new requests while
+// a request already in flight completes against the old upstream
+func TestConfigReloadSwapsServiceURL(t *testing.T) {
+	received := make(chan struct{})
+	release := make(chan struct{})
+	oldUpstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(received)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"upstream":"old"}`))
+	}))
+	t.Cleanup(oldUpstream.Close)
+	newUpstream := newEchoUpstream(t, "new")
+
+	cfg := newValidConfig()
+	cfg.ServiceURLs.TaskDispatcher = oldUpstream.URL
+	next := newValidConfig()
+	next.ServiceURLs.TaskDispatcher = newUpstream.URL
+
+	proxyHandler := newTestProxyHandler(t, cfg, handlers.DefaultProxyOptions())
+	store := handlers.NewConfigStore(cfg, func() (*config.Config, error) { return next, nil }, true, zap.NewNop())
+	store.OnReload(proxyHandler.UpdateConfig)
+
+	router := gin.New()
+	router.GET("/api/v1/tasks", proxyHandler.ProxyToService("task_dispatcher", "/tasks"))
+
+	inFlight := make(chan *closeNotifyRecorder)
+	go func() {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+		w := newProxyRecorder()
+		router.ServeHTTP(w, req)
+		inFlight <- w
+	}()
+	<-received
+
+	if err := store.ReloadConfig(); err != nil {
+		t.Fatalf("Expected reload to succeed, got %v", err)
+	}
+	if store.Config() != next {
+		t.Error("Expected the store to serve the reloaded config")
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+	w := newProxyRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if echo := decodeEcho(t, w); echo.Upstream != "new" {
+		t.Errorf("Expected request after reload to reach 'new', got '%s'", echo.Upstream)
+	}
+
+	close(release)
+	old := <-inFlight
+	if old.Code != http.StatusOK {
+		t.Fatalf("Expected in-flight request status %d, got %d", http.StatusOK, old.Code)
+	}
+	if !strings.Contains(old.Body.String(), `"old"`) {
+		t.Errorf("Expected in-flight request to complete against 'old', got %s", old.Body.String())
+	}
+}
+
+// TestConfigReloadRejectsInvalidConfig verifies a failed reload keeps the current config
+func TestConfigReloadRejectsInvalidConfig(t *testing.T) {
+	cfg := newValidConfig()
+	tests := []struct {
+		name        string
+		load        handlers.ConfigLoader
+		expectedErr string
+	}{
+		{"invalid", func() (*config.Config, error) {
+			invalid := newValidConfig()
+			invalid.ServiceURLs.TaskDispatcher = "task-dispatcher:8080"
+			return invalid, nil
+		}, "ServiceURLs.TaskDispatcher"},
+		{"load failure", func() (*config.Config, error) {
+			return nil, errors.New("config file unreadable")
+		}, "config file unreadable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := handlers.NewConfigStore(cfg, tt.load, true, zap.NewNop())
+			notified := false
+			store.OnReload(func(*config.Config) { notified = true })
+
+			err := store.ReloadConfig()
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("Expected error containing %q, got %v", tt.expectedErr, err)
+			}
+			if store.Config() != cfg {
+				t.Error("Expected the current config to be kept")
+			}
+			if notified {
+				t.Error("Expected listeners not to be notified")
+			}
+		})
+	}
+}
+
+// TestAdminReloadConfig verifies the admin reload endpoint and its error envelope
+func TestAdminReloadConfig(t *testing.T) {
+	next := newValidConfig()
+	next.ServiceURLs.Frontend = "http://frontend-v2:3000"
+	cfg := newValidConfig()
+
+	h := handlers.NewAdminHandler(cfg, zap.NewNop())
+	store := handlers.NewConfigStore(cfg, func() (*config.Config, error) { return next, nil }, true, zap.NewNop())
+	store.OnReload(h.UpdateConfig)
+	h.SetConfigReloader(store)
+
+	router := gin.New()
+	router.POST("/api/v1/admin/reload", withUser("root", "admin"), h.ReloadConfig)
+	router.GET("/api/v1/admin/config", withUser("root", "admin"), h.GetConfig)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/reload", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/admin/config", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "frontend-v2") {
+		t.Errorf("Expected GetConfig to show the reloaded config, got %s", w.Body.String())
+	}
+
+	// A rejected config keeps the reloaded one and reports why
+	next = newValidConfig()
+	next.JWTSecret = "changeme"
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/admin/reload", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status %d, got %d", http.StatusUnprocessableEntity, w.Code)
+	}
+	var body map[string]map[string]string
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body["error"]["code"] != "CONFIG_RELOAD_FAILED" || !strings.Contains(body["error"]["message"], "JWTSecret") {
+		t.Errorf("Unexpected error body %s", w.Body.String())
+	}
+	if store.Config().ServiceURLs.Frontend != "http://frontend-v2:3000" {
+		t.Error("Expected the previously reloaded config to be kept")
+	}
+}
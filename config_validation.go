@@ -0,0 +1,91 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains startup validation of the gateway configuration.
+//
+// Associated Frontend Files:
+//   - None (startup checks run before any request is served)
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+
+	"github.com/ugjb/api-gateway/config"
+)
+
+// minProductionJWTSecretLength is the minimum JWT secret length accepted in production
+const minProductionJWTSecretLength = 32
+
+// insecureJWTSecrets are well-known placeholder secrets that must never reach production
+var insecureJWTSecrets = map[string]bool{
+	"":                       true,
+	"secret":                 true,
+	"changeme":               true,
+	"change-me":              true,
+	"your-secret-key":        true,
+	"dev-secret":             true,
+	"development-secret-key": true,
+}
+
+// ValidateConfig checks the configuration the handlers depend on and returns
+// all problems found, joined into one error. In production, placeholder or
+// short JWT secrets are rejected. Call it from main before building handlers.
+func ValidateConfig(cfg *config.Config, production bool) error {
+	if cfg == nil {
+		return errors.New("config is nil")
+	}
+
+	var errs []error
+
+	if cfg.Authelia.InternalURL == "" {
+		errs = append(errs, errors.New("Authelia.InternalURL is required"))
+	} else if err := validateServiceURL(cfg.Authelia.InternalURL); err != nil {
+		errs = append(errs, fmt.Errorf("Authelia.InternalURL: %w", err))
+	}
+	if cfg.Authelia.SessionCookieName == "" {
+		errs = append(errs, errors.New("Authelia.SessionCookieName is required"))
+	}
+
+	if cfg.JWTExpiration <= 0 {
+		errs = append(errs, errors.New("JWTExpiration must be positive"))
+	}
+	if production {
+		if insecureJWTSecrets[cfg.JWTSecret] {
+			errs = append(errs, errors.New("JWTSecret uses an insecure default value in production"))
+		} else if len(cfg.JWTSecret) < minProductionJWTSecretLength {
+			errs = append(errs, fmt.Errorf("JWTSecret must be at least %d characters in production", minProductionJWTSecretLength))
+		}
+	}
+
+	// Service URLs are optional (unset services return 503) but must parse when set
+	services := reflect.ValueOf(cfg.ServiceURLs)
+	for i := 0; i < services.NumField(); i++ {
+		field := services.Type().Field(i)
+		value := services.Field(i)
+		if value.Kind() != reflect.String || value.String() == "" {
+			continue
+		}
+		if err := validateServiceURL(value.String()); err != nil {
+			errs = append(errs, fmt.Errorf("ServiceURLs.%s: %w", field.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateServiceURL requires an absolute http(s) URL with a host
+func validateServiceURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid URL %q: scheme must be http or https", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid URL %q: missing host", raw)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package handlers_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ugjb/api-gateway/config"
+	"github.com/ugjb/api-gateway/handlers"
+)
+
+// newValidConfig creates a configuration that passes validation in production
+func newValidConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.JWTSecret = strings.Repeat("k", 48)
+	cfg.JWTExpiration = time.Hour
+	cfg.Authelia.InternalURL = "http://authelia:9091"
+	cfg.Authelia.SessionCookieName = "authelia_session"
+	cfg.ServiceURLs.Frontend = "http://frontend:3000"
+	return cfg
+}
+
+// TestValidateConfig verifies configuration problems are reported with readable messages
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		mutate      func(cfg *config.Config)
+		production  bool
+		expectedErr string
+	}{
+		{"valid config", func(cfg *config.Config) {}, true, ""},
+		{"missing Authelia URL", func(cfg *config.Config) { cfg.Authelia.InternalURL = "" }, false, "Authelia.InternalURL is required"},
+		{"invalid service URL", func(cfg *config.Config) { cfg.ServiceURLs.TaskDispatcher = "task-dispatcher:8080" }, false, "ServiceURLs.TaskDispatcher"},
+		{"default secret in production", func(cfg *config.Config) { cfg.JWTSecret = "your-secret-key" }, true, "insecure default"},
+		{"default secret outside production", func(cfg *config.Config) { cfg.JWTSecret = "your-secret-key" }, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newValidConfig()
+			tt.mutate(cfg)
+
+			err := handlers.ValidateConfig(cfg, tt.production)
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+// TestValidateConfigAggregatesErrors verifies every problem is reported at once
+func TestValidateConfigAggregatesErrors(t *testing.T) {
+	cfg := newValidConfig()
+	cfg.Authelia.InternalURL = ""
+	cfg.ServiceURLs.Bugsink = "ftp://bugsink"
+
+	err := handlers.ValidateConfig(cfg, false)
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+	for _, expected := range []string{"Authelia.InternalURL", "ServiceURLs.Bugsink"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected aggregated error to mention %s, got %v", expected, err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file implements double-submit CSRF protection for requests
+// authenticated by the Authelia session cookie.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (copies the csrf_token cookie into X-CSRF-Token)
+//
+// Requests authenticated only by a Bearer JWT carry no ambient credentials
+// and are exempt; the check only applies when the session cookie is present.
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CSRFConfig configures the CSRFProtection middleware
+type CSRFConfig struct {
+	// Enabled toggles the check; when false the middleware is a no-op
+	Enabled bool
+	// SessionCookieName is the cookie whose presence makes a request cookie-authenticated
+	SessionCookieName string
+	// CookieName holds the CSRF token readable by the frontend
+	CookieName string
+	// HeaderName must echo the CookieName value on state-changing requests
+	HeaderName string
+	// CookieDomain and CookiePath scope the CSRF cookie (empty path means "/")
+	CookieDomain string
+	CookiePath   string
+}
+
+// DefaultCSRFConfig returns the CSRF configuration for the given session cookie
+func DefaultCSRFConfig(sessionCookieName string) CSRFConfig {
+	return CSRFConfig{
+		Enabled:           true,
+		SessionCookieName: sessionCookieName,
+		CookieName:        "csrf_token",
+		HeaderName:        "X-CSRF-Token",
+		CookiePath:        "/",
+	}
+}
+
+// CSRFProtection returns a middleware enforcing double-submit CSRF tokens.
+// It issues the CSRF cookie when missing and rejects cookie-authenticated
+// non-safe requests whose header does not match the cookie with 403 CSRF_INVALID.
+func CSRFProtection(cfg CSRFConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.Enabled {
+			c.Next()
+			return
+		}
+
+		token, err := c.Cookie(cfg.CookieName)
+		if err != nil || token == "" {
+			issueCSRFCookie(c, cfg)
+		}
+
+		if isSafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		// Bearer-only requests carry no ambient credentials
+		if _, err := c.Cookie(cfg.SessionCookieName); err != nil {
+			c.Next()
+			return
+		}
+
+		header := c.GetHeader(cfg.HeaderName)
+		if token == "" || header == "" || subtle.ConstantTimeCompare([]byte(token), []byte(header)) != 1 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": gin.H{
+					"code":    "CSRF_INVALID",
+					"message": "Missing or invalid CSRF token",
+				},
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// issueCSRFCookie sets a new random CSRF token cookie (readable by JavaScript by design)
+func issueCSRFCookie(c *gin.Context, cfg CSRFConfig) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return
+	}
+
+	path := cfg.CookiePath
+	if path == "" {
+		path = "/"
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     cfg.CookieName,
+		Value:    base64.RawURLEncoding.EncodeToString(buf),
+		Path:     path,
+		Domain:   cfg.CookieDomain,
+		Secure:   getScheme(c) == "https",
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// isSafeMethod reports whether method does not change state (RFC 9110)
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugjb/api-gateway/handlers"
+)
+
+// setupCSRFRouter creates a router with CSRF protection and simple handlers
+func setupCSRFRouter(cfg handlers.CSRFConfig) *gin.Engine {
+	router := gin.New()
+	router.Use(handlers.CSRFProtection(cfg))
+	router.GET("/api/v1/tasks", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/api/v1/tasks", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	return router
+}
+
+// TestCSRFProtection verifies double-submit validation for cookie-authenticated requests
+func TestCSRFProtection(t *testing.T) {
+	router := setupCSRFRouter(handlers.DefaultCSRFConfig("authelia_session"))
+
+	// A safe request issues the CSRF cookie
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var csrfCookie *http.Cookie
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == "csrf_token" {
+			csrfCookie = cookie
+		}
+	}
+	if w.Code != http.StatusOK || csrfCookie == nil || csrfCookie.Value == "" {
+		t.Fatalf("Expected GET to pass and issue csrf_token cookie, got %d %v", w.Code, w.Header().Values("Set-Cookie"))
+	}
+
+	tests := []struct {
+		name           string
+		sessionCookie  bool
+		csrfCookie     bool
+		header         string
+		bearer         bool
+		expectedStatus int
+	}{
+		{"valid token", true, true, csrfCookie.Value, false, http.StatusCreated},
+		{"missing header", true, true, "", false, http.StatusForbidden},
+		{"mismatched header", true, true, "forged", false, http.StatusForbidden},
+		{"missing cookie", true, false, csrfCookie.Value, false, http.StatusForbidden},
+		{"bearer only is exempt", false, false, "", true, http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/tasks", nil)
+			if tt.sessionCookie {
+				req.AddCookie(&http.Cookie{Name: "authelia_session", Value: "session"})
+			}
+			if tt.csrfCookie {
+				req.AddCookie(csrfCookie)
+			}
+			if tt.header != "" {
+				req.Header.Set("X-CSRF-Token", tt.header)
+			}
+			if tt.bearer {
+				req.Header.Set("Authorization", "Bearer token")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus == http.StatusForbidden {
+				var body map[string]map[string]interface{}
+				json.Unmarshal(w.Body.Bytes(), &body)
+				if body["error"]["code"] != "CSRF_INVALID" {
+					t.Errorf("Expected error code CSRF_INVALID, got %v", body["error"]["code"])
+				}
+			}
+		})
+	}
+}
+
+// TestCSRFProtectionDisabled verifies the middleware can be turned off
+func TestCSRFProtectionDisabled(t *testing.T) {
+	cfg := handlers.DefaultCSRFConfig("authelia_session")
+	cfg.Enabled = false
+	router := setupCSRFRouter(cfg)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tasks", nil)
+	req.AddCookie(&http.Cookie{Name: "authelia_session", Value: "session"})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status %d with CSRF disabled, got %d", http.StatusCreated, w.Code)
+	}
+}
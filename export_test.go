@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Exported aliases of internal helpers for tests in package handlers_test.
+var (
+	ContainsAny          = containsAny
+	ExtractNameFromEmail = extractNameFromEmail
+	LogFields            = logFields
+	ParsePageParams      = parsePageParams
+	RewriteJSONURLs      = rewriteJSONURLs
+	WriteError           = writeError
+)
+
+// ProxyRequestWithPathRewrite exposes proxyRequestWithPathRewrite for tests.
+func (p *ProxyHandler) ProxyRequestWithPathRewrite(serviceName, targetPath, pathPrefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p.proxyRequestWithPathRewrite(c, serviceName, p.resolveServiceURL(c, serviceName), targetPath, pathPrefix)
+	}
+}
+
+// UpstreamTransport exposes the transport used to reach serviceName for tests.
+func (p *ProxyHandler) UpstreamTransport(serviceName string) *http.Transport {
+	return p.transportFor(serviceName)
+}
+
+// SetClock replaces the clock of t for tests.
+func (t *SLOTracker) SetClock(now func() time.Time) {
+	t.now = now
+}
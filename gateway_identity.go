@@ -0,0 +1,106 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file implements the gateway identity headers middleware used to tell
+// replicas apart when debugging multi-replica deployments.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (apiClient - responses carry X-Served-By)
+package handlers
+
+import (
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gateway identity response headers
+const (
+	HeaderGatewayVersion = "X-Gateway-Version"
+	HeaderServedBy       = "X-Served-By"
+)
+
+// GatewayIdentityConfig configures the GatewayIdentity middleware
+// Empty string values disable the corresponding header
+type GatewayIdentityConfig struct {
+	// Version is the gateway build version (see version.go)
+	Version string
+	// ServedBy identifies the replica (e.g. the pod name)
+	ServedBy string
+}
+
+// DefaultGatewayIdentityConfig returns the build Version and the replica name
+// taken from POD_NAME, falling back to the hostname
+func DefaultGatewayIdentityConfig() GatewayIdentityConfig {
+	cfg := GatewayIdentityConfig{Version: Version, ServedBy: os.Getenv("POD_NAME")}
+	if cfg.ServedBy == "" {
+		cfg.ServedBy, _ = os.Hostname()
+	}
+	return cfg
+}
+
+// GatewayIdentity returns a middleware that adds X-Gateway-Version and X-Served-By
+// to every response. Headers are applied when the response is written, after
+// proxied upstream headers have been copied; if an upstream already set one of
+// these names, its value is kept and the gateway value is prepended.
+func GatewayIdentity(cfg GatewayIdentityConfig) gin.HandlerFunc {
+	headers := map[string]string{
+		HeaderGatewayVersion: cfg.Version,
+		HeaderServedBy:       cfg.ServedBy,
+	}
+	return func(c *gin.Context) {
+		c.Writer = &gatewayIdentityWriter{ResponseWriter: c.Writer, headers: headers}
+		c.Next()
+	}
+}
+
+// gatewayIdentityWriter applies identity headers right before the header is written
+type gatewayIdentityWriter struct {
+	gin.ResponseWriter
+	headers map[string]string
+	applied bool
+}
+
+func (w *gatewayIdentityWriter) apply() {
+	if w.applied {
+		return
+	}
+	w.applied = true
+
+	h := w.ResponseWriter.Header()
+	for key, value := range w.headers {
+		if value == "" {
+			continue
+		}
+		if existing := h.Values(key); len(existing) > 0 {
+			value += ", " + strings.Join(existing, ", ")
+		}
+		h.Set(key, value)
+	}
+}
+
+func (w *gatewayIdentityWriter) WriteHeader(code int) {
+	w.apply()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gatewayIdentityWriter) WriteHeaderNow() {
+	w.apply()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *gatewayIdentityWriter) Write(data []byte) (int, error) {
+	w.apply()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *gatewayIdentityWriter) WriteString(s string) (int, error) {
+	w.apply()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Flush makes sure headers are applied for streamed responses
+func (w *gatewayIdentityWriter) Flush() {
+	w.apply()
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,70 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugjb/api-gateway/config"
+	"github.com/ugjb/api-gateway/handlers"
+	"go.uber.org/zap"
+)
+
+// setupGatewayIdentityRouter creates a router serving a gateway response and a proxied response
+func setupGatewayIdentityRouter(t *testing.T, upstreamHeaders map[string]string) *gin.Engine {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for key, value := range upstreamHeaders {
+			w.Header().Set(key, value)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(upstream.Close)
+
+	cfg := &config.Config{}
+	cfg.ServiceURLs.TaskDispatcher = upstream.URL
+	proxyHandler := newTestProxyHandler(t, cfg, handlers.DefaultProxyOptions())
+
+	router := gin.New()
+	router.Use(handlers.GatewayIdentity(handlers.GatewayIdentityConfig{Version: "v1.2.3", ServedBy: "gateway-0"}))
+
+	healthHandler := handlers.NewHealthHandler(zap.NewNop())
+	router.GET("/health", healthHandler.Health)
+	router.GET("/api/v1/tasks", proxyHandler.ProxyToService("task_dispatcher", "/tasks"))
+
+	return router
+}
+
+// TestGatewayIdentityHeaders verifies identity headers on gateway and proxied responses
+func TestGatewayIdentityHeaders(t *testing.T) {
+	router := setupGatewayIdentityRouter(t, nil)
+
+	for _, path := range []string{"/health", "/api/v1/tasks"} {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		w := newProxyRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status %d, got %d", path, http.StatusOK, w.Code)
+		}
+		if got := w.Header().Get("X-Gateway-Version"); got != "v1.2.3" {
+			t.Errorf("%s: expected X-Gateway-Version 'v1.2.3', got '%s'", path, got)
+		}
+		if got := w.Header().Get("X-Served-By"); got != "gateway-0" {
+			t.Errorf("%s: expected X-Served-By 'gateway-0', got '%s'", path, got)
+		}
+	}
+}
+
+// TestGatewayIdentityKeepsUpstreamValues verifies upstream values are preserved behind the gateway value
+func TestGatewayIdentityKeepsUpstreamValues(t *testing.T) {
+	router := setupGatewayIdentityRouter(t, map[string]string{"X-Served-By": "task-dispatcher-7"})
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+	w := newProxyRecorder()
+	router.ServeHTTP(w, req)
+
+	if got := w.Header().Values("X-Served-By"); len(got) != 1 || got[0] != "gateway-0, task-dispatcher-7" {
+		t.Errorf("Expected X-Served-By [gateway-0, task-dispatcher-7], got %v", got)
+	}
+}
@@ -14,8 +14,14 @@ import (
 
 // HealthHandler handles health check endpoints
 type HealthHandler struct {
-	logger    *zap.Logger
-	startTime time.Time
+	logger     *zap.Logger
+	startTime  time.Time
+	webSockets WebSocketCounter
+}
+
+// WebSocketCounter reports open proxied WebSocket connections (implemented by ProxyHandler)
+type WebSocketCounter interface {
+	ActiveWebSockets() int64
 }
 
 // NewHealthHandler creates a new HealthHandler
@@ -26,6 +32,19 @@ func NewHealthHandler(logger *zap.Logger) *HealthHandler {
 	}
 }
 
+// SetWebSocketCounter makes liveness responses report active WebSocket connections
+func (h *HealthHandler) SetWebSocketCounter(counter WebSocketCounter) {
+	h.webSockets = counter
+}
+
+// activeWebSockets returns the open WebSocket count, or 0 when not tracked
+func (h *HealthHandler) activeWebSockets() int64 {
+	if h.webSockets == nil {
+		return 0
+	}
+	return h.webSockets.ActiveWebSockets()
+}
+
 // Health returns basic health status
 // @Summary Health check
 // @Description Returns the health status of the API Gateway
@@ -60,7 +79,7 @@ func (h *HealthHandler) Ready(c *gin.Context) {
 
 // Live returns liveness status
 // @Summary Liveness check
-// @Description Returns the liveness status of the API Gateway with uptime
+// @Description Returns the liveness status of the API Gateway with uptime and active WebSocket connections
 // @Tags Health
 // @Accept json
 // @Produce json
@@ -68,10 +87,11 @@ func (h *HealthHandler) Ready(c *gin.Context) {
 // @Router /health/live [get]
 func (h *HealthHandler) Live(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"status":    "live",
-		"service":   "api-gateway",
-		"uptime":    time.Since(h.startTime).String(),
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"status":            "live",
+		"service":           "api-gateway",
+		"uptime":            time.Since(h.startTime).String(),
+		"active_websockets": h.activeWebSockets(),
+		"timestamp":         time.Now().UTC().Format(time.RFC3339),
 	})
 }
 
@@ -94,6 +114,17 @@ func (h *HealthHandler) Readiness(c *gin.Context) {
 	})
 }
 
+// Ping returns a static plain-text response for uptime monitors
+// @Summary Ping
+// @Description Returns a static "pong" with no dynamic fields for exact-match uptime checks
+// @Tags Health
+// @Produce plain
+// @Success 200 {string} string "pong"
+// @Router /api/v1/public/ping [get]
+func (h *HealthHandler) Ping(c *gin.Context) {
+	c.String(http.StatusOK, "pong")
+}
+
 // Status returns detailed status information
 // @Summary Service status
 // @Description Returns detailed status information including version and uptime
@@ -106,7 +137,7 @@ func (h *HealthHandler) Status(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status":    "operational",
 		"service":   "api-gateway",
-		"version":   "1.0.0",
+		"version":   Version,
 		"uptime":    time.Since(h.startTime).String(),
 		"timestamp": time.Now().UTC().Format(time.RFC3339),
 	})
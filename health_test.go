@@ -0,0 +1,178 @@
+package handlers_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugjb/api-gateway/config"
+	"github.com/ugjb/api-gateway/handlers"
+	"go.uber.org/zap"
+)
+
+// setupHealthRouter creates a router with the health endpoints
+func setupHealthRouter(h *handlers.HealthHandler) *gin.Engine {
+	router := gin.New()
+	router.GET("/health", h.Health)
+	router.GET("/health/live", h.Live)
+	router.GET("/api/v1/public/status", h.Status)
+	router.GET("/api/v1/public/version", h.Version)
+	router.GET("/api/v1/public/ping", h.Ping)
+	return router
+}
+
+// getJSON performs a GET request and decodes the JSON response
+func getJSON(t *testing.T, router http.Handler, path string) (int, map[string]interface{}) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Expected JSON body from %s, got error: %v", path, err)
+	}
+	return w.Code, body
+}
+
+// TestVersionEndpoint verifies build info fields and that they follow the ldflags variables
+func TestVersionEndpoint(t *testing.T) {
+	originalVersion, originalCommit := handlers.Version, handlers.Commit
+	handlers.Version, handlers.Commit = "1.2.3", "abc1234"
+	t.Cleanup(func() {
+		handlers.Version, handlers.Commit = originalVersion, originalCommit
+	})
+
+	router := setupHealthRouter(handlers.NewHealthHandler(zap.NewNop()))
+
+	code, body := getJSON(t, router, "/api/v1/public/version")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+	}
+	for _, field := range []string{"version", "commit", "build_time", "go_version"} {
+		if _, ok := body[field]; !ok {
+			t.Errorf("Expected field '%s' in version response", field)
+		}
+	}
+	if body["version"] != "1.2.3" || body["commit"] != "abc1234" {
+		t.Errorf("Expected injected version/commit, got %v/%v", body["version"], body["commit"])
+	}
+	if body["go_version"] != runtime.Version() {
+		t.Errorf("Expected go_version '%s', got %v", runtime.Version(), body["go_version"])
+	}
+
+	_, status := getJSON(t, router, "/api/v1/public/status")
+	if status["version"] != "1.2.3" {
+		t.Errorf("Expected status version to match build version, got %v", status["version"])
+	}
+}
+
+// TestPingEndpoint verifies ping returns a static plain-text pong
+func TestPingEndpoint(t *testing.T) {
+	router := setupHealthRouter(handlers.NewHealthHandler(zap.NewNop()))
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/public/ping", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+			t.Errorf("Expected text/plain Content-Type, got '%s'", ct)
+		}
+		if w.Body.String() != "pong" {
+			t.Errorf("Expected body 'pong', got '%s'", w.Body.String())
+		}
+	}
+}
+
+// newWebSocketUpstream creates a fake backend that accepts upgrades and echoes lines
+func newWebSocketUpstream(t *testing.T) *httptest.Server {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+		for {
+			line, err := rw.ReadString('\n')
+			if err != nil {
+				return
+			}
+			rw.WriteString(line)
+			rw.Flush()
+		}
+	}))
+	t.Cleanup(upstream.Close)
+	return upstream
+}
+
+// TestLiveReportsActiveWebSockets verifies the liveness counter follows WebSocket open and close
+func TestLiveReportsActiveWebSockets(t *testing.T) {
+	upstream := newWebSocketUpstream(t)
+	cfg := &config.Config{}
+	cfg.ServiceURLs.Frontend = upstream.URL
+
+	proxyHandler, err := handlers.NewProxyHandlerWithOptions(cfg, zap.NewNop(), handlers.DefaultProxyOptions())
+	if err != nil {
+		t.Fatalf("Failed to create proxy handler: %v", err)
+	}
+	gatewayRouter := gin.New()
+	gatewayRouter.NoRoute(proxyHandler.ProxyWithWebSocket("frontend"))
+	gateway := httptest.NewServer(gatewayRouter)
+	t.Cleanup(gateway.Close)
+
+	healthHandler := handlers.NewHealthHandler(zap.NewNop())
+	healthHandler.SetWebSocketCounter(proxyHandler)
+	healthRouter := setupHealthRouter(healthHandler)
+	activeWebSockets := func() float64 {
+		_, body := getJSON(t, healthRouter, "/health/live")
+		count, _ := body["active_websockets"].(float64)
+		return count
+	}
+
+	if got := activeWebSockets(); got != 0 {
+		t.Fatalf("Expected 0 active websockets, got %v", got)
+	}
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(gateway.URL, "http://"))
+	if err != nil {
+		t.Fatalf("Failed to dial gateway: %v", err)
+	}
+	conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: gateway\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n"))
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil || resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected 101 Switching Protocols, got %v (err: %v)", resp, err)
+	}
+
+	conn.Write([]byte("hello\n"))
+	if line, _ := reader.ReadString('\n'); line != "hello\n" {
+		t.Fatalf("Expected echo through upgraded connection, got %q", line)
+	}
+	if got := activeWebSockets(); got != 1 {
+		t.Errorf("Expected 1 active websocket, got %v", got)
+	}
+
+	// Abrupt client close must also release the counter
+	conn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for activeWebSockets() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected active websockets to drop to 0 after close")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
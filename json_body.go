@@ -0,0 +1,159 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains strict JSON request body decoding for gateway handlers.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (error formatting and display)
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// defaultMaxJSONBodyBytes caps JSON bodies when no limit is configured (1 MiB)
+const defaultMaxJSONBodyBytes = 1 << 20
+
+// jsonBodyOptions configures decodeJSONBody
+type jsonBodyOptions struct {
+	MaxBytes              int64
+	DisallowUnknownFields bool
+}
+
+// jsonBodyError describes why a JSON body was rejected
+type jsonBodyError struct {
+	status  int
+	code    string
+	message string
+	err     error
+}
+
+func (e *jsonBodyError) Error() string {
+	if e.err != nil {
+		return e.message + ": " + e.err.Error()
+	}
+	return e.message
+}
+
+func (e *jsonBodyError) Unwrap() error {
+	return e.err
+}
+
+// decodeJSONBody decodes and validates a JSON request body into dst.
+// Unlike ShouldBindJSON it bounds memory use and classifies failures so
+// clients get a precise 400/413/415 instead of a generic bad request.
+func decodeJSONBody(c *gin.Context, dst interface{}, opts jsonBodyOptions) error {
+	if contentType := c.GetHeader("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+			return &jsonBodyError{
+				status:  http.StatusUnsupportedMediaType,
+				code:    "UNSUPPORTED_MEDIA_TYPE",
+				message: "Content-Type must be application/json",
+			}
+		}
+	}
+
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxJSONBodyBytes
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+
+	decoder := json.NewDecoder(c.Request.Body)
+	if opts.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	if err := decoder.Decode(dst); err != nil {
+		return classifyJSONError(err, maxBytes)
+	}
+
+	// Reject trailing data such as a second JSON value
+	if err := decoder.Decode(&struct{}{}); err != io.EOF {
+		if err == nil {
+			err = errors.New("multiple JSON values")
+		}
+		return classifyJSONError(err, maxBytes)
+	}
+
+	// Raw passthrough bodies are only checked for well-formedness
+	if _, ok := dst.(*json.RawMessage); ok {
+		return nil
+	}
+
+	if err := binding.Validator.ValidateStruct(dst); err != nil {
+		return &jsonBodyError{
+			status:  http.StatusBadRequest,
+			code:    "INVALID_REQUEST",
+			message: "Invalid request body",
+			err:     err,
+		}
+	}
+
+	return nil
+}
+
+// classifyJSONError maps a decoding error to a client-facing error class
+func classifyJSONError(err error, maxBytes int64) *jsonBodyError {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return &jsonBodyError{status: http.StatusBadRequest, code: "EMPTY_BODY", message: "Request body must not be empty", err: err}
+	case errors.As(err, &maxBytesErr):
+		return &jsonBodyError{
+			status:  http.StatusRequestEntityTooLarge,
+			code:    "BODY_TOO_LARGE",
+			message: fmt.Sprintf("Request body must not exceed %d bytes", maxBytes),
+			err:     err,
+		}
+	case errors.As(err, &syntaxErr):
+		return &jsonBodyError{
+			status:  http.StatusBadRequest,
+			code:    "MALFORMED_JSON",
+			message: fmt.Sprintf("Malformed JSON at position %d", syntaxErr.Offset),
+			err:     err,
+		}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &jsonBodyError{status: http.StatusBadRequest, code: "MALFORMED_JSON", message: "Malformed JSON: unexpected end of body", err: err}
+	case errors.As(err, &typeErr):
+		return &jsonBodyError{
+			status:  http.StatusBadRequest,
+			code:    "INVALID_FIELD_TYPE",
+			message: fmt.Sprintf("Invalid type for field %q", typeErr.Field),
+			err:     err,
+		}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return &jsonBodyError{status: http.StatusBadRequest, code: "UNKNOWN_FIELD", message: "Unknown field " + field, err: err}
+	default:
+		return &jsonBodyError{status: http.StatusBadRequest, code: "MALFORMED_JSON", message: "Malformed JSON", err: err}
+	}
+}
+
+// sendJSONBodyError sends the standardized error response for a decodeJSONBody failure
+func sendJSONBodyError(c *gin.Context, err error) {
+	var bodyErr *jsonBodyError
+	if !errors.As(err, &bodyErr) {
+		sendInvalidRequestError(c)
+		return
+	}
+
+	c.JSON(bodyErr.status, gin.H{
+		"error": gin.H{
+			"code":    bodyErr.code,
+			"message": bodyErr.message,
+		},
+	})
+}
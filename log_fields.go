@@ -0,0 +1,63 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file defines the standard fields of handler log lines, so every
+// request-scoped entry can be parsed and joined the same way by the log pipeline.
+//
+// Associated Frontend Files:
+//   - None (server-side logging only)
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Standard log field keys shared by request-scoped handler log lines
+const (
+	logKeyRequestID  = "request_id"
+	logKeyService    = "service"
+	logKeyRoute      = "route"
+	logKeyStatus     = "status"
+	logKeyDurationMS = "duration_ms"
+	logKeyClientIP   = "client_ip"
+	logKeyUserID     = "user_id"
+)
+
+// logFields returns the standard fields describing c followed by extra:
+// request_id, route, client_ip and, when known, user_id and status. Use
+// serviceField and durationField for the service and duration_ms fields.
+// Extra fields whose key looks secret (token, password, ...) are redacted.
+func logFields(c *gin.Context, extra ...zap.Field) []zap.Field {
+	fields := make([]zap.Field, 0, 5+len(extra))
+	fields = append(fields,
+		zap.String(logKeyRequestID, c.GetHeader("X-Request-ID")),
+		zap.String(logKeyRoute, c.FullPath()),
+		zap.String(logKeyClientIP, RealClientIP(c)),
+	)
+	if userID := c.GetString("user_id"); userID != "" {
+		fields = append(fields, zap.String(logKeyUserID, userID))
+	}
+	if c.Writer.Written() {
+		fields = append(fields, zap.Int(logKeyStatus, c.Writer.Status()))
+	}
+
+	for _, field := range extra {
+		if sensitiveFieldPattern.MatchString(field.Key) {
+			field = zap.String(field.Key, redactedValue)
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// serviceField is the standard field naming the backend service
+func serviceField(serviceName string) zap.Field {
+	return zap.String(logKeyService, serviceName)
+}
+
+// durationField is the standard duration field, in fractional milliseconds
+func durationField(d time.Duration) zap.Field {
+	return zap.Float64(logKeyDurationMS, float64(d)/float64(time.Millisecond))
+}
@@ -0,0 +1,42 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugjb/api-gateway/handlers"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// TestLogFieldsRedaction verifies extra fields with secret-looking keys are redacted
+func TestLogFieldsRedaction(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/auth/refresh", nil)
+
+	fields := handlers.LogFields(c,
+		zap.String("refresh_token", "eyJhbGciOi"),
+		zap.String("password", "hunter2"),
+		zap.String("target", "http://auth:8080"),
+	)
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+
+	for _, key := range []string{"refresh_token", "password"} {
+		if enc.Fields[key] != "***" {
+			t.Errorf("Expected %s redacted, got %v", key, enc.Fields[key])
+		}
+	}
+	if enc.Fields["target"] != "http://auth:8080" {
+		t.Errorf("Expected target kept, got %v", enc.Fields["target"])
+	}
+	for _, key := range []string{"request_id", "route", "client_ip"} {
+		if _, ok := enc.Fields[key]; !ok {
+			t.Errorf("Expected standard field %s, got %v", key, enc.Fields)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains the login history store used for security auditing.
+//
+// Associated Frontend Files:
+//   - web/app/src/pages/SettingsPage.tsx (security settings - recent login activity)
+//
+// Only attempt metadata is recorded (never passwords). Credentials are
+// validated by Authelia; the gateway merely observes the outcome.
+package handlers
+
+import (
+	"sync"
+	"time"
+)
+
+// Login attempt outcomes
+const (
+	LoginOutcomeSuccess            = "success"
+	LoginOutcomeInvalidCredentials = "invalid_credentials"
+	LoginOutcomeRateLimited        = "rate_limited"
+	LoginOutcomeBanned             = "banned"
+)
+
+// LoginAttempt represents a recorded login attempt
+type LoginAttempt struct {
+	UserID    string    `json:"-"`
+	Email     string    `json:"email"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Outcome   string    `json:"outcome"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// LoginHistoryStore persists login attempts
+type LoginHistoryStore interface {
+	// Record stores a login attempt
+	Record(attempt LoginAttempt) error
+	// ListByUser returns a page of the user's attempts (newest first) and the total count
+	ListByUser(userID string, offset, limit int) ([]LoginAttempt, int, error)
+}
+
+// MemoryLoginHistoryStore is an in-process LoginHistoryStore keeping the most recent attempts per user
+type MemoryLoginHistoryStore struct {
+	mu         sync.RWMutex
+	maxPerUser int
+	attempts   map[string][]LoginAttempt // user ID -> attempts, oldest first
+}
+
+// NewMemoryLoginHistoryStore creates a MemoryLoginHistoryStore retaining maxPerUser attempts per user
+func NewMemoryLoginHistoryStore(maxPerUser int) *MemoryLoginHistoryStore {
+	if maxPerUser <= 0 {
+		maxPerUser = 100
+	}
+	return &MemoryLoginHistoryStore{
+		maxPerUser: maxPerUser,
+		attempts:   make(map[string][]LoginAttempt),
+	}
+}
+
+// Record stores a login attempt, dropping the oldest beyond the retention limit
+func (s *MemoryLoginHistoryStore) Record(attempt LoginAttempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts := append(s.attempts[attempt.UserID], attempt)
+	if len(attempts) > s.maxPerUser {
+		attempts = attempts[len(attempts)-s.maxPerUser:]
+	}
+	s.attempts[attempt.UserID] = attempts
+	return nil
+}
+
+// ListByUser returns a page of the user's attempts, newest first
+func (s *MemoryLoginHistoryStore) ListByUser(userID string, offset, limit int) ([]LoginAttempt, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempts := s.attempts[userID]
+	total := len(attempts)
+
+	page := make([]LoginAttempt, 0, limit)
+	for i := total - 1 - offset; i >= 0 && len(page) < limit; i-- {
+		page = append(page, attempts[i])
+	}
+	return page, total, nil
+}
@@ -0,0 +1,214 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains Prometheus metrics shared by gateway handlers.
+//
+// Associated Frontend Files:
+//   - None (metrics are scraped by Prometheus, not used by the frontend)
+//
+// Metrics are registered on an injectable prometheus.Registerer so tests can
+// use an isolated registry and main can use prometheus.DefaultRegisterer.
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Auth request results recorded in auth_requests_total
+const (
+	authResultSuccess            = "success"
+	authResultInvalidCredentials = "invalid_credentials"
+	authResultInvalidRequest     = "invalid_request"
+	authResultLocked             = "locked"
+	authResultRateLimited        = "rate_limited"
+	authResultUpstreamError      = "upstream_error"
+	authResultInternalError      = "internal_error"
+)
+
+// bodySizeBuckets spans 64B to 16MiB for the body size histograms
+var bodySizeBuckets = prometheus.ExponentialBuckets(64, 4, 10)
+
+// GatewayMetrics holds the Prometheus collectors used by gateway handlers
+type GatewayMetrics struct {
+	AuthRequests        *prometheus.CounterVec
+	AuthLatency         *prometheus.HistogramVec
+	WSConnectionsActive prometheus.Gauge
+	WSConnectionsTotal  prometheus.Counter
+	UpstreamErrors      *prometheus.CounterVec
+	BulkheadQueueDepth  *prometheus.GaugeVec
+	BulkheadRejections  *prometheus.CounterVec
+	UpstreamEjections   *prometheus.CounterVec
+	RequestBytes        *prometheus.HistogramVec
+	ResponseBytes       *prometheus.HistogramVec
+	ShadowRequests      *prometheus.CounterVec
+	VariantRequests     *prometheus.CounterVec
+}
+
+// NewGatewayMetrics creates the gateway collectors and registers them on reg
+func NewGatewayMetrics(reg prometheus.Registerer) *GatewayMetrics {
+	m := &GatewayMetrics{
+		AuthRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_requests_total",
+			Help: "Authentication requests handled by the gateway, by endpoint and result.",
+		}, []string{"endpoint", "result"}),
+		AuthLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "auth_request_duration_seconds",
+			Help:    "Latency of authentication requests handled by the gateway.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		WSConnectionsActive: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "gateway_ws_connections_active",
+			Help: "Proxied WebSocket connections currently open.",
+		}),
+		WSConnectionsTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "gateway_ws_connections_total",
+			Help: "Proxied WebSocket connections opened since start.",
+		}),
+		UpstreamErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "upstream_errors_total",
+			Help: "Failed upstream calls made by the proxy, by service and reason (dial, timeout, reset, tls, other).",
+		}, []string{"service", "reason"}),
+		BulkheadQueueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "gateway_bulkhead_queue_depth",
+			Help: "Requests waiting for a free bulkhead slot, by service.",
+		}, []string{"service"}),
+		BulkheadRejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gateway_bulkhead_rejections_total",
+			Help: "Requests rejected with 503 UPSTREAM_BUSY by the bulkhead, by service.",
+		}, []string{"service"}),
+		UpstreamEjections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gateway_upstream_ejections_total",
+			Help: "Load-balanced instances ejected by outlier detection, by service and instance.",
+		}, []string{"service", "instance"}),
+		RequestBytes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "gateway_request_bytes",
+			Help:    "Size of proxied request bodies in bytes, by service.",
+			Buckets: bodySizeBuckets,
+		}, []string{"service"}),
+		ResponseBytes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "gateway_response_bytes",
+			Help:    "Size of proxied response bodies in bytes, by service.",
+			Buckets: bodySizeBuckets,
+		}, []string{"service"}),
+		ShadowRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gateway_shadow_requests_total",
+			Help: "Mirrored requests sent to shadow services, by service and result (match, mismatch, error, dropped).",
+		}, []string{"service", "result"}),
+		VariantRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gateway_variant_requests_total",
+			Help: "Requests to services with a canary, by service and variant (stable, canary).",
+		}, []string{"service", "variant"}),
+	}
+
+	reg.MustRegister(m.AuthRequests, m.AuthLatency, m.WSConnectionsActive, m.WSConnectionsTotal, m.UpstreamErrors,
+		m.BulkheadQueueDepth, m.BulkheadRejections, m.UpstreamEjections, m.RequestBytes, m.ResponseBytes,
+		m.ShadowRequests, m.VariantRequests)
+	return m
+}
+
+// observeAuth records the outcome and latency of an auth request (nil-safe).
+// The result is derived from the response status written by the handler.
+func (m *GatewayMetrics) observeAuth(c *gin.Context, endpoint string, start time.Time) {
+	if m == nil {
+		return
+	}
+	m.AuthRequests.WithLabelValues(endpoint, authResultFromStatus(c.Writer.Status())).Inc()
+	m.AuthLatency.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+}
+
+// webSocketOpened records an upgraded WebSocket connection (nil-safe)
+func (m *GatewayMetrics) webSocketOpened() {
+	if m == nil {
+		return
+	}
+	m.WSConnectionsActive.Inc()
+	m.WSConnectionsTotal.Inc()
+}
+
+// webSocketClosed records the end of a WebSocket connection (nil-safe)
+func (m *GatewayMetrics) webSocketClosed() {
+	if m == nil {
+		return
+	}
+	m.WSConnectionsActive.Dec()
+}
+
+// upstreamError records a failed upstream call (nil-safe)
+func (m *GatewayMetrics) upstreamError(service, reason string) {
+	if m == nil {
+		return
+	}
+	m.UpstreamErrors.WithLabelValues(service, reason).Inc()
+}
+
+// bulkheadQueueChanged adds delta (+1 queued, -1 dequeued) to a service's bulkhead queue depth (nil-safe)
+func (m *GatewayMetrics) bulkheadQueueChanged(service string, delta float64) {
+	if m == nil {
+		return
+	}
+	m.BulkheadQueueDepth.WithLabelValues(service).Add(delta)
+}
+
+// bulkheadRejected records a request turned away by the bulkhead (nil-safe)
+func (m *GatewayMetrics) bulkheadRejected(service string) {
+	if m == nil {
+		return
+	}
+	m.BulkheadRejections.WithLabelValues(service).Inc()
+}
+
+// upstreamEjected records an instance ejected by outlier detection (nil-safe)
+func (m *GatewayMetrics) upstreamEjected(service, instance string) {
+	if m == nil {
+		return
+	}
+	m.UpstreamEjections.WithLabelValues(service, instance).Inc()
+}
+
+// observeBodySizes records the body sizes of a proxied request (nil-safe)
+func (m *GatewayMetrics) observeBodySizes(service string, requestBytes, responseBytes int64) {
+	if m == nil {
+		return
+	}
+	m.RequestBytes.WithLabelValues(service).Observe(float64(requestBytes))
+	m.ResponseBytes.WithLabelValues(service).Observe(float64(responseBytes))
+}
+
+// shadowRequest records the outcome of a mirrored request (nil-safe)
+func (m *GatewayMetrics) shadowRequest(service, result string) {
+	if m == nil {
+		return
+	}
+	m.ShadowRequests.WithLabelValues(service, result).Inc()
+}
+
+// variantRequest records a request routed to a stable or canary variant (nil-safe)
+func (m *GatewayMetrics) variantRequest(service, variant string) {
+	if m == nil {
+		return
+	}
+	m.VariantRequests.WithLabelValues(service, variant).Inc()
+}
+
+// authResultFromStatus maps an auth response status to a metrics result label
+func authResultFromStatus(status int) string {
+	switch {
+	case status < 300:
+		return authResultSuccess
+	case status == http.StatusUnauthorized:
+		return authResultInvalidCredentials
+	case status == http.StatusLocked || status == http.StatusForbidden:
+		return authResultLocked
+	case status == http.StatusTooManyRequests:
+		return authResultRateLimited
+	case status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout:
+		return authResultUpstreamError
+	case status < 500:
+		return authResultInvalidRequest
+	default:
+		return authResultInternalError
+	}
+}
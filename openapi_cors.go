@@ -0,0 +1,77 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file implements CORS for the OpenAPI spec documents, so API explorers
+// and the docs portal on other origins can fetch them.
+//
+// Associated Frontend Files:
+//   - None (consumed by API tooling, not the web app)
+//
+// Only the spec documents (doc.json, doc.yaml, openapi.json, openapi.yaml)
+// get CORS headers; the Swagger UI pages stay same-origin.
+package handlers
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// specDocuments are the file names of the served OpenAPI spec documents
+var specDocuments = map[string]bool{
+	"doc.json":     true,
+	"doc.yaml":     true,
+	"openapi.json": true,
+	"openapi.yaml": true,
+}
+
+// SpecCORSConfig configures cross-origin access to the OpenAPI spec
+type SpecCORSConfig struct {
+	// AllowedOrigins lists origins that may fetch the spec (e.g. "https://docs.example.com");
+	// "*" allows any origin. Empty disables CORS.
+	AllowedOrigins []string
+	// MaxAge lets browsers cache preflight results (0 omits Access-Control-Max-Age)
+	MaxAge time.Duration
+}
+
+// SpecCORS returns a middleware adding CORS headers to spec document responses
+// for allowed origins. Mount it on the /swagger/*any and /openapi.* routes;
+// preflight requests are answered with 204 when the router routes OPTIONS to it.
+// The spec is public data, so credentials are never allowed.
+func SpecCORS(cfg SpecCORSConfig) gin.HandlerFunc {
+	allowAny := false
+	allowed := make(map[string]bool, len(cfg.AllowedOrigins))
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			allowAny = true
+		}
+		allowed[origin] = true
+	}
+
+	return func(c *gin.Context) {
+		if !specDocuments[path.Base(c.Request.URL.Path)] {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		origin := c.GetHeader("Origin")
+		if origin == "" || !(allowAny || allowed[origin]) {
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Origin", origin)
+		c.Header("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+		if c.Request.Method == http.MethodOptions {
+			if cfg.MaxAge > 0 {
+				c.Header("Access-Control-Max-Age", strconv.Itoa(int(cfg.MaxAge.Seconds())))
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
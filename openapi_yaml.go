@@ -0,0 +1,72 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file serves the generated OpenAPI spec as YAML, next to the JSON
+// served by gin-swagger at /swagger/doc.json.
+//
+// Associated Frontend Files:
+//   - None (consumed by API tooling, not the web app)
+//
+// Routes (registered in main):
+//   - GET /swagger/*any -> WithOpenAPIYAML(ginSwagger.WrapHandler(...)) adds /swagger/doc.yaml
+//   - GET /openapi.yaml -> OpenAPIYAML()
+package handlers
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-yaml"
+	"github.com/swaggo/swag"
+)
+
+// openAPIYAMLContentType is the media type of the YAML spec
+const openAPIYAMLContentType = "application/x-yaml"
+
+// OpenAPIYAML returns a handler serving the registered swag spec as YAML.
+// The conversion is cached until the spec changes (e.g. main setting
+// SwaggerInfo.Host after startup).
+func OpenAPIYAML() gin.HandlerFunc {
+	var mu sync.Mutex
+	var cachedJSON string
+	var cachedYAML []byte
+
+	return func(c *gin.Context) {
+		doc, err := swag.ReadDoc()
+		if err != nil {
+			NotFoundHandler(c)
+			return
+		}
+
+		mu.Lock()
+		if doc != cachedJSON {
+			// Keys keep the JSON order so diffs against doc.json stay readable
+			out, err := yaml.JSONToYAML([]byte(doc))
+			if err != nil {
+				mu.Unlock()
+				sendInternalError(c)
+				return
+			}
+			cachedJSON, cachedYAML = doc, out
+		}
+		out := cachedYAML
+		mu.Unlock()
+
+		c.Data(http.StatusOK, openAPIYAMLContentType, out)
+	}
+}
+
+// WithOpenAPIYAML wraps the gin-swagger handler mounted at /swagger/*any so
+// /swagger/doc.yaml serves the YAML spec; every other path, including
+// doc.json, is left to swagger. gin does not allow a static route next to the
+// catch-all, hence the wrapper.
+func WithOpenAPIYAML(swagger gin.HandlerFunc) gin.HandlerFunc {
+	yamlHandler := OpenAPIYAML()
+	return func(c *gin.Context) {
+		if c.Param("any") == "/doc.yaml" {
+			yamlHandler(c)
+			return
+		}
+		swagger(c)
+	}
+}
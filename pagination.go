@@ -0,0 +1,79 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains the shared pagination envelope for list endpoints.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (apiClient - paginated list responses)
+//   - web/app/src/pages/SettingsPage.tsx (sessions and login history lists)
+//   - web/app/src/pages/AdminPage.tsx (admin audit trail)
+package handlers
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page bounds for list endpoints; maxPage keeps Offset from overflowing
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	maxPage         = math.MaxInt32
+)
+
+// PageParams is a validated page request (Page is 1-based)
+type PageParams struct {
+	Page     int
+	PageSize int
+}
+
+// Offset returns the number of items before the requested page
+func (p PageParams) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+// parsePageParams reads the page and page_size query parameters.
+// Missing or invalid values fall back to page 1 and the default page size;
+// page and page_size are capped at maxPage and maxPageSize.
+func parsePageParams(c *gin.Context) PageParams {
+	params := PageParams{Page: 1, PageSize: defaultPageSize}
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
+		params.Page = min(page, maxPage)
+	}
+	if pageSize, err := strconv.Atoi(c.Query("page_size")); err == nil && pageSize > 0 {
+		params.PageSize = min(pageSize, maxPageSize)
+	}
+	return params
+}
+
+// PaginatedResponse is the response envelope of every list endpoint
+type PaginatedResponse[T any] struct {
+	Items    []T  `json:"items"`
+	Total    int  `json:"total"`
+	Page     int  `json:"page"`
+	PageSize int  `json:"page_size"`
+	HasMore  bool `json:"has_more"`
+}
+
+// NewPaginatedResponse wraps one page of items out of total.
+// Items is never nil so empty pages encode as [].
+func NewPaginatedResponse[T any](items []T, total int, params PageParams) PaginatedResponse[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return PaginatedResponse[T]{
+		Items:    items,
+		Total:    total,
+		Page:     params.Page,
+		PageSize: params.PageSize,
+		HasMore:  params.Offset()+len(items) < total,
+	}
+}
+
+// paginate returns the requested page of a fully loaded list
+func paginate[T any](all []T, params PageParams) PaginatedResponse[T] {
+	start := min(params.Offset(), len(all))
+	end := min(start+params.PageSize, len(all))
+	return NewPaginatedResponse(all[start:end], len(all), params)
+}
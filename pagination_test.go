@@ -0,0 +1,76 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugjb/api-gateway/handlers"
+)
+
+// TestParsePageParams verifies page parameter defaults and bounds
+func TestParsePageParams(t *testing.T) {
+	tests := []struct {
+		query string
+		want  handlers.PageParams
+	}{
+		{"", handlers.PageParams{Page: 1, PageSize: 20}},
+		{"page=3&page_size=50", handlers.PageParams{Page: 3, PageSize: 50}},
+		{"page=0&page_size=0", handlers.PageParams{Page: 1, PageSize: 20}},
+		{"page=-2&page_size=-5", handlers.PageParams{Page: 1, PageSize: 20}},
+		{"page=abc&page_size=xyz", handlers.PageParams{Page: 1, PageSize: 20}},
+		{"page_size=500", handlers.PageParams{Page: 1, PageSize: 100}},
+		{"page=99999999999999", handlers.PageParams{Page: 2147483647, PageSize: 20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request, _ = http.NewRequest(http.MethodGet, "/items?"+tt.query, nil)
+			if got := handlers.ParsePageParams(c); got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestNewPaginatedResponse verifies the envelope at page boundaries
+func TestNewPaginatedResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		items       []int
+		total       int
+		params      handlers.PageParams
+		wantHasMore bool
+	}{
+		{"first page", []int{1, 2}, 5, handlers.PageParams{Page: 1, PageSize: 2}, true},
+		{"last full page", []int{3, 4}, 4, handlers.PageParams{Page: 2, PageSize: 2}, false},
+		{"last partial page", []int{5}, 5, handlers.PageParams{Page: 3, PageSize: 2}, false},
+		{"past the end", nil, 5, handlers.PageParams{Page: 9, PageSize: 2}, false},
+		{"empty result", nil, 0, handlers.PageParams{Page: 1, PageSize: 20}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := handlers.NewPaginatedResponse(tt.items, tt.total, tt.params)
+			if resp.HasMore != tt.wantHasMore {
+				t.Errorf("Expected has_more %v, got %v", tt.wantHasMore, resp.HasMore)
+			}
+			if resp.Total != tt.total || resp.Page != tt.params.Page || resp.PageSize != tt.params.PageSize {
+				t.Errorf("Unexpected envelope %+v", resp)
+			}
+
+			data, _ := json.Marshal(resp)
+			var body map[string]interface{}
+			json.Unmarshal(data, &body)
+			if items, ok := body["items"].([]interface{}); !ok || len(items) != len(tt.items) {
+				t.Errorf("Expected items encoded as an array of %d, got %v", len(tt.items), body["items"])
+			}
+			for _, key := range []string{"total", "page", "page_size", "has_more"} {
+				if _, ok := body[key]; !ok {
+					t.Errorf("Expected '%s' in envelope, got %v", key, body)
+				}
+			}
+		})
+	}
+}
@@ -6,119 +6,268 @@
 package handlers
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
-	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v5"
 	"github.com/ugjb/api-gateway/config"
 	"go.uber.org/zap"
 )
 
 // ProxyHandler handles proxying requests to backend services
 type ProxyHandler struct {
-	config *config.Config
-	logger *zap.Logger
+	// current is swapped as a whole by UpdateConfig; load it once per request
+	current          atomic.Pointer[config.Config]
+	logger           *zap.Logger
+	options          ProxyOptions
+	defaultTransport *http.Transport
+	transports       map[string]*http.Transport
+	// externalTransports is keyed by ExternalServices name, separate from internal services
+	externalTransports map[string]*http.Transport
+	bulkheads          map[string]chan struct{}
+	// bulkheadQueues counts requests waiting for a bulkhead slot, per service
+	bulkheadQueues   map[string]*atomic.Int64
+	balancers        map[string]*loadBalancer
+	retryTransports  map[string]http.RoundTripper
+	retryBudget      *retryBudget
+	schemas          map[string]*jsonschema.Schema
+	activeWebSockets atomic.Int64
+	// webSocketSlots counts upgrades in progress and open, enforcing WSMaxConnections
+	webSocketSlots atomic.Int64
+	// responseCache holds responses of services with a CacheTTL (nil when none has one)
+	responseCache *TTLCache
+	// conns tracks the connections of all transports for PoolStats
+	conns *connTracker
+	// shadowSamplers is keyed like ProxyOptions.ShadowRoutes
+	shadowSamplers map[string]*shadowSampler
+	// shadowSlots bounds mirrored requests in flight (ProxyOptions.ShadowMaxInFlight)
+	shadowSlots chan struct{}
 }
 
-// NewProxyHandler creates a new ProxyHandler
+// NewProxyHandler creates a new ProxyHandler with default options
 func NewProxyHandler(cfg *config.Config, logger *zap.Logger) *ProxyHandler {
-	return &ProxyHandler{
-		config: cfg,
-		logger: logger,
+	// Default options load no certificates, so construction cannot fail
+	p, _ := NewProxyHandlerWithOptions(cfg, logger, DefaultProxyOptions())
+	return p
+}
+
+// NewProxyHandlerWithOptions creates a new ProxyHandler with the given options
+// Returns an error if a service URL is invalid or upstream TLS material or a
+// route schema cannot be loaded
+func NewProxyHandlerWithOptions(cfg *config.Config, logger *zap.Logger, opts ProxyOptions) (*ProxyHandler, error) {
+	p := &ProxyHandler{
+		logger:  logger,
+		options: opts.normalize(),
+		conns:   newConnTracker(),
+	}
+	p.current.Store(cfg)
+	if err := p.validateServiceConfigs(); err != nil {
+		return nil, err
 	}
+	if err := p.buildTransports(); err != nil {
+		return nil, err
+	}
+	p.buildBulkheads()
+	p.buildLoadBalancers()
+	p.buildRetryTransports()
+	p.buildShadowSamplers()
+	if err := p.compileRouteSchemas(); err != nil {
+		return nil, err
+	}
+	for _, service := range p.options.Services {
+		if service.CacheTTL > 0 {
+			p.responseCache = NewTTLCache(TTLCacheOptions{MaxBytes: p.options.ResponseCacheMaxBytes})
+			break
+		}
+	}
+	return p, nil
+}
+
+// UpdateConfig replaces the configuration service URLs are resolved from.
+// Requests already resolved keep their upstream; later requests use cfg.
+// Register it with ConfigStore.OnReload. Only the config is swapped:
+// ProxyOptions and the AutheliaHandler's config are not reloaded.
+func (p *ProxyHandler) UpdateConfig(cfg *config.Config) {
+	p.current.Store(cfg)
 }
 
 // ProxyToService returns a handler that proxies to a backend service
+// If the service has StripPrefix/AddPrefix rules, the upstream path is derived
+// from the request path and targetPath is ignored.
 func (p *ProxyHandler) ProxyToService(serviceName, targetPath string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		serviceURL := p.getServiceURL(serviceName)
+		// ContentTypeRoutes may send this route to another service; serviceName is the default
+		service := p.routeByContentType(c, serviceName)
+
+		serviceURL := p.resolveServiceURL(c, service)
 		if serviceURL == "" {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"error": fmt.Sprintf("Service %s not configured", serviceName),
-			})
+			writeError(c, p.errServiceNotConfigured(c, service))
 			return
 		}
 
-		p.proxyRequest(c, serviceURL, targetPath)
+		path := targetPath
+		if p.hasPathRewrite(service) {
+			path = p.rewriteServicePath(service, c.Request.URL.Path)
+		}
+
+		p.proxyRequest(c, service, serviceURL, path)
 	}
 }
 
 // ProxyToExternalService proxies to external services
+// External targets resolve from ProxyOptions.ExternalServices, never from the
+// internal service URLs. Services not yet in the table fall back to the legacy
+// internal resolution.
 func (p *ProxyHandler) ProxyToExternalService(serviceName, targetPath string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		serviceURL := p.getServiceURL(serviceName)
+		if external, ok := p.options.ExternalServices[serviceName]; ok {
+			p.proxyRequestTo(c, serviceName, external.BaseURL, targetPath, p.externalUpstream(serviceName))
+			return
+		}
+
+		serviceURL := p.resolveServiceURL(c, serviceName)
 		if serviceURL == "" {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"error": fmt.Sprintf("External service %s not configured", serviceName),
-			})
+			writeError(c, p.errServiceNotConfigured(c, serviceName))
 			return
 		}
 
-		p.proxyRequest(c, serviceURL, targetPath)
+		path := targetPath
+		if p.hasPathRewrite(serviceName) {
+			path = p.rewriteServicePath(serviceName, c.Request.URL.Path)
+		}
+
+		p.proxyRequest(c, serviceName, serviceURL, path)
 	}
 }
 
 // ProxyWithWebSocket handles proxying including WebSocket connections
 func (p *ProxyHandler) ProxyWithWebSocket(serviceName string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Return JSON 404 for undefined API routes (don't proxy to frontend)
-		if strings.HasPrefix(c.Request.URL.Path, "/api/") {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": gin.H{
-					"code":    "NOT_FOUND",
-					"message": "API endpoint not found",
-					"path":    c.Request.URL.Path,
-				},
-			})
+		// Return JSON 404 for undefined API routes (don't proxy to frontend);
+		// WebSocket upgrades to WSPaths are let through
+		if p.isUnknownAPIPath(c) {
+			NotFoundHandler(c)
 			return
 		}
 
-		serviceURL := p.getServiceURL(serviceName)
+		serviceURL := p.resolveServiceURL(c, serviceName)
 		if serviceURL == "" {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"error": fmt.Sprintf("Service %s not configured", serviceName),
-			})
+			writeError(c, p.errServiceNotConfigured(c, serviceName))
 			return
 		}
 
 		// Check if this is a WebSocket upgrade request
-		if c.GetHeader("Upgrade") == "websocket" {
-			p.proxyWebSocket(c, serviceURL)
+		if isWebSocketUpgrade(c) {
+			p.proxyWebSocket(c, serviceName, serviceURL)
 			return
 		}
 
-		p.proxyRequest(c, serviceURL, c.Request.URL.Path)
+		p.proxyRequest(c, serviceName, serviceURL, c.Request.URL.Path)
 	}
 }
 
-// proxyRequest proxies a regular HTTP request
-func (p *ProxyHandler) proxyRequest(c *gin.Context, targetURL, targetPath string) {
+// DefaultServiceName is the service name of the catch-all backend; its
+// Services entry (e.g. Timeout) applies to ProxyDefault traffic
+const DefaultServiceName = "default"
+
+// ProxyDefault returns the catch-all handler proxying unmatched non-API requests,
+// full path and query preserved, to ProxyOptions.DefaultServiceURL (e.g. the
+// frontend dev server), WebSocket upgrades included. Unknown API paths (except
+// upgrades to WSPaths), and all paths when no default service is configured, get the JSON 404.
+// Register with router.NoRoute(proxyHandler.ProxyDefault())
+func (p *ProxyHandler) ProxyDefault() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		serviceURL := p.options.DefaultServiceURL
+		if serviceURL == "" || p.isUnknownAPIPath(c) {
+			NotFoundHandler(c)
+			return
+		}
+
+		if isWebSocketUpgrade(c) {
+			p.proxyWebSocket(c, DefaultServiceName, serviceURL)
+			return
+		}
+
+		p.proxyRequest(c, DefaultServiceName, serviceURL, c.Request.URL.Path)
+	}
+}
+
+// upstream describes how a proxied request reaches its target
+type upstream struct {
+	transport http.RoundTripper
+	// headers are set on every upstream request, overriding client values
+	headers map[string]string
+	// timeout bounds the whole upstream exchange (0 means no gateway timeout)
+	timeout time.Duration
+}
+
+// proxyRequest proxies a regular HTTP request to an internal service
+func (p *ProxyHandler) proxyRequest(c *gin.Context, serviceName, targetURL, targetPath string) {
+	p.proxyRequestTo(c, serviceName, targetURL, targetPath, upstream{transport: p.roundTripperFor(serviceName)})
+}
+
+// proxyRequestTo proxies a regular HTTP request through the given upstream
+// The request body is streamed to the upstream as it arrives and never buffered,
+// so large uploads do not grow gateway memory. Features that must replay a body
+// (retries, idempotency) have to buffer it themselves before calling this.
+// Response trailers (e.g. gRPC-Web Grpc-Status) are announced and sent after
+// the body by ReverseProxy; hooks that replace resp.Body must read the
+// original to EOF first, or resp.Trailer stays empty.
+func (p *ProxyHandler) proxyRequestTo(c *gin.Context, serviceName, targetURL, targetPath string, up upstream) {
 	target, err := url.Parse(targetURL)
 	if err != nil {
 		p.logger.Error("Failed to parse target URL", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		writeError(c, err)
 		return
 	}
 
+	if !p.checkAllowedMethod(c, serviceName) {
+		return
+	}
+
+	if !p.checkHeaderSize(c) {
+		return
+	}
+
+	if !p.validateRequestSchema(c) {
+		return
+	}
+
+	cacheKey := p.responseCacheKey(c, serviceName, targetURL)
+	if cacheKey != "" && p.serveCachedResponse(c, cacheKey) {
+		return
+	}
+
+	acquired, err := p.acquireBulkhead(c, serviceName)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	release := sync.OnceFunc(acquired)
+	defer release()
+
+	mirror := p.prepareShadow(c, targetPath)
+
 	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.Transport = rejectionTransport{up.transport}
+	proxy.FlushInterval = p.flushInterval(serviceName)
 
 	// Modify the request
 	originalDirector := proxy.Director
 	proxy.Director = func(req *http.Request) {
 		originalDirector(req)
 
-		// Build the target path
-		if strings.Contains(targetPath, ":id") {
-			// Replace :id with the actual parameter
-			id := c.Param("id")
-			targetPath = strings.Replace(targetPath, ":id", id, 1)
-		}
+		// Build the target path from route params (:id etc.)
+		req.URL.Path, req.URL.RawPath = expandPathParams(targetPath, c.Params)
 
 		// Preserve query parameters
-		req.URL.Path = targetPath
 		req.URL.RawQuery = c.Request.URL.RawQuery
 		req.Host = target.Host
 
@@ -127,16 +276,22 @@ func (p *ProxyHandler) proxyRequest(c *gin.Context, targetURL, targetPath string
 			if len(values) > 0 {
 				req.Header.Set(key, values[0])
 				// Add remaining values if multiple exist
-				for _, value := range values[1:] {
+				for _, value := range p.limitHeaderValues(values)[1:] {
 					req.Header.Add(key, value)
 				}
 			}
 		}
 
 		// Add forwarding headers
-		req.Header.Set("X-Forwarded-For", c.ClientIP())
-		req.Header.Set("X-Forwarded-Proto", "http")
-		req.Header.Set("X-Real-IP", c.ClientIP())
+		setForwardedHeaders(c, req, p.options.ForwardedHeaders)
+		setForwardedPrefix(req, p.strippedPrefix(serviceName, c.Request.URL.Path))
+		p.overrideMethod(c, req)
+
+		// Tenant is derived from the Host header, never trusted from the client
+		req.Header.Del("X-Tenant-ID")
+		if tenantID := c.GetString(tenantIDKey); tenantID != "" {
+			req.Header.Set("X-Tenant-ID", tenantID)
+		}
 
 		// Forward user info from auth middleware
 		if userID, exists := c.Get("user_id"); exists {
@@ -149,67 +304,138 @@ func (p *ProxyHandler) proxyRequest(c *gin.Context, targetURL, targetPath string
 				req.Header.Set("X-User-Email", e)
 			}
 		}
+
+		for key, value := range up.headers {
+			req.Header.Set(key, value)
+		}
+
+		p.setUpstreamAcceptEncoding(serviceName, req)
+		setDeadlineHeaders(req)
+		p.transformRequest(c, serviceName, req)
 	}
 
 	// Handle errors
+	start := time.Now()
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
-		p.logger.Error("Proxy error", zap.Error(err), zap.String("target", targetURL))
-		c.JSON(http.StatusBadGateway, gin.H{
-			"error":   "Service unavailable",
-			"details": err.Error(),
-		})
+		if handleClientCanceled(c, p.logger, r, err) {
+			return
+		}
+		var rejected *jsonBodyError
+		if errors.As(err, &rejected) {
+			sendJSONBodyError(c, rejected)
+			return
+		}
+		if errors.Is(err, errWebSocketNegotiation) {
+			p.logger.Warn("WebSocket negotiation failed", zap.Error(err), zap.String("target", targetURL))
+			sendWebSocketNegotiationError(c, err)
+			return
+		}
+		p.recordUpstreamResult(serviceName, targetURL, true)
+		p.logUpstreamError(c, r, serviceName, targetURL, start, err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			writeError(c, &ProxyError{Service: serviceName, Err: ErrUpstreamTimeout})
+			return
+		}
+		writeError(c, errUpstreamUnavailable(serviceName, err, p.options.ExposeErrorDetails))
+	}
+
+	// Count upgraded (WebSocket) connections for as long as they stay open;
+	// other responses go through the registered ResponseTransformers, then the cache
+	upgraded := false
+	proxy.ModifyResponse = func(resp *http.Response) error {
+		p.recordUpstreamResult(serviceName, targetURL, resp.StatusCode >= http.StatusInternalServerError)
+		if resp.StatusCode == http.StatusSwitchingProtocols {
+			if err := checkWebSocketSubprotocol(c.Request.Header, resp.Header); err != nil {
+				return err
+			}
+			upgraded = true
+			p.webSocketOpened()
+			// A long-lived socket must not hold a bulkhead slot for its lifetime
+			release()
+			return nil
+		}
+		if err := p.restoreClientEncoding(c, serviceName, resp); err != nil {
+			return err
+		}
+		if err := p.transformResponse(serviceName, resp); err != nil {
+			return err
+		}
+		if cacheKey != "" {
+			return p.cacheResponse(serviceName, cacheKey, resp)
+		}
+		return nil
 	}
 
+	if timeout := p.requestTimeout(c, serviceName, up.timeout); timeout > 0 {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+	}
+	c.Request = withUpstreamAttempts(c.Request)
+
+	// Deferred so the count is released however ServeHTTP exits (including aborted copies)
+	defer func() {
+		if upgraded {
+			p.webSocketClosed()
+		}
+	}()
+
+	observeSizes := p.measureBodySizes(c, serviceName)
+	start = time.Now()
 	proxy.ServeHTTP(c.Writer, c.Request)
+	if upgraded {
+		return
+	}
+	observeSizes()
+	latency := time.Since(start)
+	p.logProxyAccess(c, serviceName, latency)
+	p.options.SLO.Record(serviceName, latency, c.Writer.Status() >= http.StatusInternalServerError)
+	if mirror != nil {
+		mirror(c.Writer.Status())
+	}
+}
+
+// ActiveWebSockets returns the number of currently open proxied WebSocket connections
+func (p *ProxyHandler) ActiveWebSockets() int64 {
+	return p.activeWebSockets.Load()
 }
 
 // ProxyToAuthelia returns a handler that proxies requests to internal Authelia
 // Authelia is never exposed publicly - only accessible via internal Docker network
 func (p *ProxyHandler) ProxyToAuthelia() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		autheliaURL := p.config.Authelia.InternalURL
+		autheliaURL := p.current.Load().Authelia.InternalURL
 		if autheliaURL == "" {
-			p.logger.Error("Authelia URL not configured")
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"error": gin.H{
-					"code":    "AUTH_SERVICE_UNAVAILABLE",
-					"message": "Authentication service not configured",
-				},
-			})
+			writeError(c, p.errServiceNotConfigured(c, "authelia"))
 			return
 		}
 
-		// Extract the path after /api/oidc or /api/auth
-		path := c.Param("path")
-		if path == "" {
-			path = "/"
-		}
+		// Correlate the Authelia hop like AutheliaHandler's own calls
+		ensureRequestID(c)
 
-		targetPath := "/api/oidc" + path
-		p.proxyRequest(c, autheliaURL, targetPath)
+		// Map the path after /api/oidc or /api/auth under Authelia's /api/oidc
+		targetPath := joinPath("/api/oidc", c.Param("path"))
+		p.proxyRequest(c, "authelia", autheliaURL, targetPath)
 	}
 }
 
 // proxyWebSocket handles WebSocket proxy
-func (p *ProxyHandler) proxyWebSocket(c *gin.Context, targetURL string) {
-	target, err := url.Parse(targetURL)
-	if err != nil {
-		p.logger.Error("Failed to parse WebSocket target URL", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+// httputil.ReverseProxy switches to a bidirectional byte copy after a
+// 101 Switching Protocols response; proxyRequest tracks the open connection.
+// Handshake headers (including Sec-WebSocket-Protocol) are forwarded as-is and
+// the upstream's choice is relayed back after checkWebSocketSubprotocol.
+func (p *ProxyHandler) proxyWebSocket(c *gin.Context, serviceName, targetURL string) {
+	if !checkWebSocketHandshake(c) {
 		return
 	}
-
-	// Create WebSocket URL
-	wsURL := fmt.Sprintf("ws://%s%s", target.Host, c.Request.URL.Path)
-	if c.Request.URL.RawQuery != "" {
-		wsURL += "?" + c.Request.URL.RawQuery
+	if !p.acquireWebSocketSlot(c) {
+		return
 	}
+	defer p.webSocketSlots.Add(-1)
 
-	p.logger.Info("WebSocket proxy", zap.String("target", wsURL))
-
-	// For now, return an error as WebSocket proxying requires more complex handling
-	// In production, you'd use a proper WebSocket proxy library
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error": "WebSocket proxying not implemented in this version",
+	p.logger.Info("WebSocket proxy", zap.String("target", targetURL), zap.String("path", c.Request.URL.Path))
+	p.proxyRequestTo(c, serviceName, targetURL, c.Request.URL.Path, upstream{
+		transport: p.roundTripperFor(serviceName),
+		headers:   p.options.Services[serviceName].WebSocketHeaders,
 	})
 }
@@ -0,0 +1,38 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains access logging for proxied requests.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (apiClient - all API calls proxied through gateway)
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// logProxyAccess logs a completed proxied request.
+// Requests slower than SlowRequestThreshold are logged at Warn level;
+// other requests are logged at Info level only when LogAllRequests is set.
+func (p *ProxyHandler) logProxyAccess(c *gin.Context, serviceName string, latency time.Duration) {
+	slow := p.options.SlowRequestThreshold > 0 && latency > p.options.SlowRequestThreshold
+	if !slow && !p.options.LogAllRequests {
+		return
+	}
+
+	fields := logFields(c,
+		serviceField(serviceName),
+		zap.String("method", c.Request.Method),
+		zap.String("path", c.Request.URL.Path),
+		durationField(latency),
+	)
+
+	if slow {
+		p.logger.Warn("Slow proxied request",
+			append(fields, zap.Duration("threshold", p.options.SlowRequestThreshold))...)
+		return
+	}
+	p.logger.Info("Proxied request", fields...)
+}
@@ -0,0 +1,277 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains smooth weighted round-robin load balancing over a
+// service's Instances and outlier ejection, which takes a failing instance out of rotation
+// instead of failing the whole service.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (error formatting and display)
+package handlers
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Defaults for unset OutlierDetectionConfig fields
+const (
+	defaultOutlierErrorRate          = 0.5
+	defaultOutlierMinRequests        = 5
+	defaultOutlierWindow             = 10 * time.Second
+	defaultOutlierBaseEjectionTime   = 30 * time.Second
+	defaultOutlierMaxEjectionTime    = 5 * time.Minute
+	defaultOutlierMaxEjectionPercent = 50
+)
+
+// buildLoadBalancers creates a load balancer for every service with Instances
+func (p *ProxyHandler) buildLoadBalancers() {
+	p.balancers = make(map[string]*loadBalancer)
+	for name, service := range p.options.Services {
+		if len(service.Instances) > 0 {
+			p.balancers[name] = newLoadBalancer(name, service.Instances, service.InstanceWeights, service.OutlierDetection, p.logger, p.options.Metrics)
+		}
+	}
+}
+
+// recordUpstreamResult feeds the outcome of a request to targetURL into the
+// service's outlier detection (no-op for services without Instances)
+func (p *ProxyHandler) recordUpstreamResult(serviceName, targetURL string, failed bool) {
+	if lb, ok := p.balancers[serviceName]; ok {
+		lb.record(targetURL, failed)
+	}
+}
+
+// loadBalancer picks a service's instances by smooth weighted round-robin
+// (as in nginx), skipping drained and ejected ones
+type loadBalancer struct {
+	service   string
+	detection *OutlierDetectionConfig
+	logger    *zap.Logger
+	metrics   *GatewayMetrics
+
+	mu        sync.Mutex
+	instances []*upstreamInstance
+}
+
+// upstreamInstance tracks one instance's recent outcomes and ejection state
+type upstreamInstance struct {
+	url    string
+	weight int
+	// current is the smooth weighted round-robin counter
+	current int
+	buckets []outcomeBucket
+	// ejectedUntil is zero while the instance is in rotation
+	ejectedUntil time.Time
+	// ejections counts consecutive ejections; it resets after a healthy window
+	ejections    int
+	readmittedAt time.Time
+}
+
+type outcomeBucket struct {
+	second   int64
+	requests int
+	failures int
+}
+
+// newLoadBalancer creates a load balancer; weights default to 1 and detection
+// may be nil to only balance
+func newLoadBalancer(service string, urls []string, weights map[string]int, detection *OutlierDetectionConfig, logger *zap.Logger, metrics *GatewayMetrics) *loadBalancer {
+	lb := &loadBalancer{
+		service: service,
+		logger:  logger,
+		metrics: metrics,
+	}
+	if detection != nil {
+		d := *detection
+		if d.ErrorRate <= 0 {
+			d.ErrorRate = defaultOutlierErrorRate
+		}
+		if d.MinRequests <= 0 {
+			d.MinRequests = defaultOutlierMinRequests
+		}
+		if d.Window <= 0 {
+			d.Window = defaultOutlierWindow
+		}
+		if d.BaseEjectionTime <= 0 {
+			d.BaseEjectionTime = defaultOutlierBaseEjectionTime
+		}
+		if d.MaxEjectionTime <= 0 {
+			d.MaxEjectionTime = defaultOutlierMaxEjectionTime
+		}
+		if d.MaxEjectionPercent <= 0 {
+			d.MaxEjectionPercent = defaultOutlierMaxEjectionPercent
+		}
+		lb.detection = &d
+	}
+
+	seconds := 1
+	if lb.detection != nil {
+		seconds = max(int(lb.detection.Window/time.Second), 1)
+	}
+	for _, u := range urls {
+		weight, ok := weights[u]
+		if !ok {
+			weight = 1
+		}
+		lb.instances = append(lb.instances, &upstreamInstance{url: u, weight: max(weight, 0), buckets: make([]outcomeBucket, seconds)})
+	}
+	return lb
+}
+
+// hasInstance reports whether targetURL is one of the balanced instances
+func (lb *loadBalancer) hasInstance(targetURL string) bool {
+	for _, inst := range lb.instances {
+		if inst.url == targetURL {
+			return true
+		}
+	}
+	return false
+}
+
+// pick returns the next instance in rotation, readmitting instances whose
+// ejection has expired. Each pick raises every eligible instance's counter by
+// its weight and takes the highest, which interleaves a 2:1 split as a,b,a
+// rather than a,a,b. Returns "" when every instance is drained.
+func (lb *loadBalancer) pick() string {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	now := time.Now()
+	var best *upstreamInstance
+	total := 0
+	for _, inst := range lb.instances {
+		if inst.weight == 0 {
+			continue
+		}
+		if !inst.ejectedUntil.IsZero() {
+			if now.Before(inst.ejectedUntil) {
+				continue
+			}
+			lb.readmit(inst, now)
+		}
+		inst.current += inst.weight
+		total += inst.weight
+		if best == nil || inst.current > best.current {
+			best = inst
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	best.current -= total
+	return best.url
+}
+
+// record adds a request outcome to targetURL's window and ejects the instance
+// once its error rate reaches the threshold. Outcomes of requests that were in
+// flight when their instance was ejected are ignored.
+func (lb *loadBalancer) record(targetURL string, failed bool) {
+	if lb.detection == nil {
+		return
+	}
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	var inst *upstreamInstance
+	for _, candidate := range lb.instances {
+		if candidate.url == targetURL {
+			inst = candidate
+			break
+		}
+	}
+	if inst == nil || !inst.ejectedUntil.IsZero() {
+		return
+	}
+
+	now := time.Now()
+	bucket := inst.bucket(now)
+	bucket.requests++
+	if failed {
+		bucket.failures++
+	}
+
+	requests, failures := inst.totals(now)
+	if requests < lb.detection.MinRequests {
+		return
+	}
+	if float64(failures)/float64(requests) < lb.detection.ErrorRate {
+		// A full healthy window after readmission forgets earlier ejections
+		if inst.ejections > 0 && now.Sub(inst.readmittedAt) >= lb.detection.Window {
+			inst.ejections = 0
+		}
+		return
+	}
+	if lb.ejectedCount(now) >= lb.maxEjected() {
+		return
+	}
+
+	inst.ejections++
+	ejection := lb.detection.BaseEjectionTime << (inst.ejections - 1)
+	if ejection > lb.detection.MaxEjectionTime || ejection <= 0 {
+		ejection = lb.detection.MaxEjectionTime
+	}
+	inst.ejectedUntil = now.Add(ejection)
+	lb.logger.Warn("Upstream instance ejected",
+		zap.String("service", lb.service),
+		zap.String("instance", inst.url),
+		zap.Int("requests", requests),
+		zap.Int("failures", failures),
+		zap.Int("ejections", inst.ejections),
+		zap.Duration("ejection_time", ejection),
+	)
+	lb.metrics.upstreamEjected(lb.service, inst.url)
+}
+
+// readmit returns an ejected instance to rotation with a fresh window (caller holds mu)
+func (lb *loadBalancer) readmit(inst *upstreamInstance, now time.Time) {
+	inst.ejectedUntil = time.Time{}
+	inst.readmittedAt = now
+	inst.current = 0
+	clear(inst.buckets)
+	lb.logger.Info("Upstream instance readmitted",
+		zap.String("service", lb.service),
+		zap.String("instance", inst.url),
+	)
+}
+
+// ejectedCount returns the number of instances currently out of rotation (caller holds mu)
+func (lb *loadBalancer) ejectedCount(now time.Time) int {
+	count := 0
+	for _, inst := range lb.instances {
+		if now.Before(inst.ejectedUntil) {
+			count++
+		}
+	}
+	return count
+}
+
+// maxEjected returns how many instances may be ejected at once, always
+// leaving at least one in rotation
+func (lb *loadBalancer) maxEjected() int {
+	n := len(lb.instances) * lb.detection.MaxEjectionPercent / 100
+	return min(max(n, 1), len(lb.instances)-1)
+}
+
+// bucket returns the bucket for now's second, resetting it if stale
+func (inst *upstreamInstance) bucket(now time.Time) *outcomeBucket {
+	second := now.Unix()
+	bucket := &inst.buckets[int(second%int64(len(inst.buckets)))]
+	if bucket.second != second {
+		*bucket = outcomeBucket{second: second}
+	}
+	return bucket
+}
+
+// totals sums the buckets inside the window ending at now
+func (inst *upstreamInstance) totals(now time.Time) (requests, failures int) {
+	oldest := now.Unix() - int64(len(inst.buckets))
+	for _, bucket := range inst.buckets {
+		if bucket.second > oldest {
+			requests += bucket.requests
+			failures += bucket.failures
+		}
+	}
+	return requests, failures
+}
@@ -0,0 +1,57 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file measures proxied request and response body sizes for the
+// gateway_request_bytes and gateway_response_bytes histograms.
+//
+// Associated Frontend Files:
+//   - None (metrics are scraped by Prometheus, not used by the frontend)
+//
+// Bodies are streamed, never buffered, so sizes are counted as bytes pass
+// through; chunked bodies without a Content-Length are measured the same way.
+package handlers
+
+import (
+	"io"
+
+	"github.com/gin-gonic/gin"
+)
+
+// countingReadCloser counts the bytes read from a request body
+type countingReadCloser struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// measureBodySizes starts counting the request body streamed upstream and the
+// response bytes written to the client. The returned func records both in the
+// size histograms; call it once proxying is done. The request size is the
+// larger of Content-Length and the bytes read, so bodies the upstream did not
+// consume are still reported at their declared size.
+func (p *ProxyHandler) measureBodySizes(c *gin.Context, serviceName string) func() {
+	if p.options.Metrics == nil {
+		return func() {}
+	}
+
+	var body *countingReadCloser
+	if c.Request.Body != nil {
+		body = &countingReadCloser{ReadCloser: c.Request.Body}
+		c.Request.Body = body
+	}
+	declared := c.Request.ContentLength
+	written := max(c.Writer.Size(), 0)
+
+	return func() {
+		requestBytes := max(declared, 0)
+		if body != nil {
+			requestBytes = max(requestBytes, body.n)
+		}
+		responseBytes := int64(max(c.Writer.Size(), 0) - written)
+		p.options.Metrics.observeBodySizes(serviceName, requestBytes, responseBytes)
+	}
+}
@@ -0,0 +1,101 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains the per-service concurrency limiter (bulkhead), which
+// keeps one slow backend from tying up every gateway goroutine.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (error formatting and display)
+package handlers
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// buildBulkheads creates a semaphore and a wait queue counter for every service with MaxConcurrent set
+func (p *ProxyHandler) buildBulkheads() {
+	p.bulkheads = make(map[string]chan struct{})
+	p.bulkheadQueues = make(map[string]*atomic.Int64)
+	for name, service := range p.options.Services {
+		if service.MaxConcurrent > 0 {
+			p.bulkheads[name] = make(chan struct{}, service.MaxConcurrent)
+			p.bulkheadQueues[name] = new(atomic.Int64)
+		}
+	}
+}
+
+// acquireBulkhead takes an in-flight slot for serviceName, waiting up to the
+// service QueueTimeout when fewer than MaxQueue requests already wait.
+// Returns ErrBulkheadFull when no slot frees up in time, or the request context
+// error if the client left while queued; otherwise the returned release func
+// must be called when the request ends.
+func (p *ProxyHandler) acquireBulkhead(c *gin.Context, serviceName string) (func(), error) {
+	sem, ok := p.bulkheads[serviceName]
+	if !ok {
+		return func() {}, nil
+	}
+	release := func() { <-sem }
+
+	select {
+	case sem <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	if timeout := p.options.Services[serviceName].QueueTimeout; timeout > 0 && p.enterBulkheadQueue(serviceName) {
+		defer p.leaveBulkheadQueue(serviceName)
+
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		select {
+		case sem <- struct{}{}:
+			return release, nil
+		case <-timer.C:
+		case <-c.Request.Context().Done():
+			// The client left while queued; there is nobody to respond to
+			return nil, c.Request.Context().Err()
+		}
+	}
+
+	p.options.Metrics.bulkheadRejected(serviceName)
+	p.logger.Warn("Service concurrency limit reached",
+		zap.String("service", serviceName),
+		zap.Int("max_concurrent", cap(sem)),
+	)
+	return nil, &ProxyError{Service: serviceName, Err: ErrBulkheadFull}
+}
+
+// enterBulkheadQueue counts a request waiting for a slot of serviceName,
+// reporting false if the service's MaxQueue waiters are already queued
+func (p *ProxyHandler) enterBulkheadQueue(serviceName string) bool {
+	queue := p.bulkheadQueues[serviceName]
+	limit := int64(p.options.Services[serviceName].MaxQueue)
+	for {
+		n := queue.Load()
+		if limit > 0 && n >= limit {
+			return false
+		}
+		if queue.CompareAndSwap(n, n+1) {
+			p.options.Metrics.bulkheadQueueChanged(serviceName, 1)
+			return true
+		}
+	}
+}
+
+// leaveBulkheadQueue removes a request from the wait queue of serviceName
+func (p *ProxyHandler) leaveBulkheadQueue(serviceName string) {
+	p.bulkheadQueues[serviceName].Add(-1)
+	p.options.Metrics.bulkheadQueueChanged(serviceName, -1)
+}
+
+// BulkheadQueueDepth returns how many requests currently wait for a slot of serviceName
+func (p *ProxyHandler) BulkheadQueueDepth(serviceName string) int64 {
+	if queue, ok := p.bulkheadQueues[serviceName]; ok {
+		return queue.Load()
+	}
+	return 0
+}
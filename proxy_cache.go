@@ -0,0 +1,165 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains the proxy response cache for services with a CacheTTL.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (apiClient - cached GET responses carry X-Cache)
+//   - web/app/src/pages/AdminPage.tsx (cache purge)
+//
+// Only successful GET responses with a known Content-Length are cached, keyed by
+// service, request URI, authenticated user, resolved upstream (tenant, API
+// version or canary) and accepted encodings, so users, tenants and variants
+// never see each other's data. Responses with Set-Cookie, Cache-Control
+// no-store/private or a Vary on anything but Accept-Encoding are skipped.
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCachedResponseBytes bounds the size of a single cached response body
+const maxCachedResponseBytes = 1 << 20
+
+// cacheKeySeparator cannot appear in service names, request URIs or user IDs
+const cacheKeySeparator = "\x00"
+
+// CachePurger evicts cached proxy responses (implemented by ProxyHandler)
+type CachePurger interface {
+	// PurgeCache removes entries of service (all services if empty) whose gateway
+	// request path starts with pathPrefix (all paths if empty) and returns how many were removed
+	PurgeCache(service, pathPrefix string) int
+}
+
+// cachedResponse is the serialized form of a cached upstream response
+type cachedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// responseCacheKey returns the cache key of the request to targetURL, or "" if
+// it is not cacheable. Instances of a load-balanced service share entries;
+// any other upstream (tenant, API version or canary URL) gets its own.
+func (p *ProxyHandler) responseCacheKey(c *gin.Context, serviceName, targetURL string) string {
+	if p.responseCache == nil || p.options.Services[serviceName].CacheTTL <= 0 ||
+		c.Request.Method != http.MethodGet || c.GetHeader("Upgrade") != "" {
+		return ""
+	}
+	upstreamKey := targetURL
+	if lb, ok := p.balancers[serviceName]; ok && lb.hasInstance(targetURL) {
+		upstreamKey = "instances"
+	}
+	return strings.Join([]string{
+		serviceName,
+		c.Request.URL.RequestURI(),
+		c.GetString("user_id"),
+		upstreamKey,
+		acceptedEncodings(c.Request.Header),
+	}, cacheKeySeparator)
+}
+
+// acceptedEncodings normalizes Accept-Encoding to its sorted, lowercased codings
+// with a non-zero weight (e.g. "br, GZIP;q=0.8, deflate;q=0" -> "br,gzip")
+func acceptedEncodings(header http.Header) string {
+	var codings []string
+	for _, value := range header.Values("Accept-Encoding") {
+		for _, part := range strings.Split(value, ",") {
+			coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding == "" {
+				continue
+			}
+			if q, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q="); ok {
+				if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+					continue
+				}
+			}
+			codings = append(codings, coding)
+		}
+	}
+	sort.Strings(codings)
+	return strings.Join(codings, ",")
+}
+
+// varyHonored reports whether every Vary field of resp is part of the cache key
+func varyHonored(resp *http.Response) bool {
+	for _, value := range resp.Header.Values("Vary") {
+		for _, field := range strings.Split(value, ",") {
+			if field = strings.TrimSpace(field); field != "" && !strings.EqualFold(field, "Accept-Encoding") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// serveCachedResponse writes a cached response for key, reporting whether there was one
+func (p *ProxyHandler) serveCachedResponse(c *gin.Context, key string) bool {
+	data, ok := p.responseCache.Get(key)
+	if !ok {
+		return false
+	}
+	var cached cachedResponse
+	if err := json.Unmarshal(data, &cached); err != nil {
+		p.responseCache.Delete(key)
+		return false
+	}
+
+	for name, values := range cached.Header {
+		c.Writer.Header()[name] = values
+	}
+	c.Header("X-Cache", "HIT")
+	c.Status(cached.Status)
+	c.Writer.Write(cached.Body)
+	return true
+}
+
+// cacheResponse stores a cacheable upstream response under key and marks it as a miss
+func (p *ProxyHandler) cacheResponse(serviceName, key string, resp *http.Response) error {
+	resp.Header.Set("X-Cache", "MISS")
+
+	cacheControl := strings.ToLower(resp.Header.Get("Cache-Control"))
+	if resp.StatusCode != http.StatusOK || resp.ContentLength < 0 || resp.ContentLength > maxCachedResponseBytes ||
+		resp.Header.Get("Set-Cookie") != "" || !varyHonored(resp) ||
+		strings.Contains(cacheControl, "no-store") || strings.Contains(cacheControl, "private") {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := resp.Header.Clone()
+	header.Del("X-Cache")
+	data, err := json.Marshal(cachedResponse{Status: resp.StatusCode, Header: header, Body: body})
+	if err != nil {
+		return nil
+	}
+	p.responseCache.Set(key, data, p.options.Services[serviceName].CacheTTL)
+	return nil
+}
+
+// PurgeCache removes matching cached responses; safe for concurrent use with proxying
+func (p *ProxyHandler) PurgeCache(service, pathPrefix string) int {
+	if p.responseCache == nil {
+		return 0
+	}
+	return p.responseCache.DeleteFunc(func(key string) bool {
+		parts := strings.SplitN(key, cacheKeySeparator, 3)
+		if len(parts) != 3 {
+			return false
+		}
+		return (service == "" || parts[0] == service) && strings.HasPrefix(parts[1], pathPrefix)
+	})
+}
@@ -0,0 +1,55 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains percentage-based canary routing between a service's
+// stable upstream and a new version.
+//
+// Associated Frontend Files:
+//   - None (the variant is reported in the X-Served-Variant response header)
+//
+// Users are bucketed by a hash of their user_id (client IP when anonymous)
+// salted with the service name, so a user stays on one variant for as long
+// as Percent does not change, and raising Percent only moves stable users over.
+package handlers
+
+import (
+	"hash/fnv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderServedVariant reports which variant of a canaried service served the response
+const HeaderServedVariant = "X-Served-Variant"
+
+// Variants reported in X-Served-Variant and gateway_variant_requests_total
+const (
+	variantStable = "stable"
+	variantCanary = "canary"
+)
+
+// canaryBuckets is the resolution of CanaryConfig.Percent (0.01%)
+const canaryBuckets = 10000
+
+// routeVariant assigns the request to a variant of serviceName if it has a
+// canary, returning the canary URL and true when the canary was picked
+func (p *ProxyHandler) routeVariant(c *gin.Context, serviceName string) (string, bool) {
+	canary := p.options.Services[serviceName].Canary
+	if canary == nil {
+		return "", false
+	}
+
+	key := c.GetString("user_id")
+	if key == "" {
+		key = RealClientIP(c)
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(serviceName + ":" + key))
+	isCanary := float64(hash.Sum32()%canaryBuckets) < canary.Percent*canaryBuckets/100
+
+	variant := variantStable
+	if isCanary {
+		variant = variantCanary
+	}
+	c.Header(HeaderServedVariant, variant)
+	p.options.Metrics.variantRequest(serviceName, variant)
+	return canary.URL, isCanary
+}
@@ -0,0 +1,148 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains gzip handling for proxied responses whose bodies the
+// gateway rewrites, and the per-service Accept-Encoding sent upstream.
+//
+// Associated Frontend Files:
+//   - None (gateway to backend compression only)
+//
+// Path-rewrite proxying asks upstreams for gzip only, since that is the one
+// encoding the gateway can decode with the standard library. Other encodings
+// (e.g. br) sent anyway are passed through untouched and not rewritten.
+// Plain proxying forwards the client's Accept-Encoding unless the service sets
+// ServiceConfig.AcceptEncoding; gzip then requested for a client that does not
+// accept it is decoded by the gateway.
+//
+// Decoding streams, and bodies are only buffered for rewriting up to
+// defaultMaxJSONBodyBytes (readResponseBody); larger ones pass through as is,
+// so a small compressed body cannot inflate into unbounded gateway memory.
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// upstreamAcceptEncoding is sent upstream when the gateway may rewrite the body
+const upstreamAcceptEncoding = "gzip"
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(header http.Header) bool {
+	for _, value := range header.Values("Accept-Encoding") {
+		for _, part := range strings.Split(value, ",") {
+			coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding != "gzip" && coding != "*" {
+				continue
+			}
+			if q, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q="); ok {
+				if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+					continue
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// setUpstreamAcceptEncoding replaces the Accept-Encoding of req when the service configures one
+func (p *ProxyHandler) setUpstreamAcceptEncoding(serviceName string, req *http.Request) {
+	if encoding := p.options.Services[serviceName].AcceptEncoding; encoding != "" {
+		req.Header.Set("Accept-Encoding", encoding)
+	}
+}
+
+// restoreClientEncoding decodes gzip the gateway requested on the client's behalf
+// when the client itself does not accept it
+func (p *ProxyHandler) restoreClientEncoding(c *gin.Context, serviceName string, resp *http.Response) error {
+	if p.options.Services[serviceName].AcceptEncoding == "" ||
+		responseEncoding(resp) != "gzip" || acceptsGzip(c.Request.Header) {
+		return nil
+	}
+	return decompressResponse(resp)
+}
+
+// responseEncoding returns the normalized Content-Encoding of resp ("" if identity)
+func responseEncoding(resp *http.Response) string {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding == "identity" {
+		return ""
+	}
+	return encoding
+}
+
+// gzipBody decodes a gzipped response body as it is read
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
+
+// decompressResponse replaces a gzipped body with a reader decoding it on the
+// fly and clears Content-Encoding; the decoded length is unknown until read
+func decompressResponse(resp *http.Response) error {
+	reader, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return err
+	}
+	resp.Body = gzipBody{Reader: reader, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	return nil
+}
+
+// readResponseBody buffers resp.Body for rewriting. ok is false when it is
+// larger than defaultMaxJSONBodyBytes; resp.Body then still yields the whole
+// body, which must be passed through unmodified.
+func readResponseBody(resp *http.Response) (body []byte, ok bool, err error) {
+	body, err = io.ReadAll(io.LimitReader(resp.Body, defaultMaxJSONBodyBytes+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, false, err
+	}
+	if len(body) > defaultMaxJSONBodyBytes {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return nil, false, nil
+	}
+	resp.Body.Close()
+	return body, true, nil
+}
+
+// compressResponse gzips the body of resp and sets Content-Encoding; bodies
+// too large to buffer are left uncompressed
+func compressResponse(resp *http.Response) error {
+	body, ok, err := readResponseBody(resp)
+	if err != nil || !ok {
+		return err
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(body); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	resp.Header.Set("Content-Encoding", "gzip")
+	resp.Header.Add("Vary", "Accept-Encoding")
+	setResponseBody(resp, compressed.Bytes())
+	return nil
+}
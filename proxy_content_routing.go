@@ -0,0 +1,58 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains content-type based routing, which sends requests on one
+// gateway path to different services (e.g. REST vs GraphQL, JSON vs multipart).
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (apiClient - sets Content-Type and Accept)
+package handlers
+
+import (
+	"mime"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentTypeRoute sends matching requests of a route to Service
+type ContentTypeRoute struct {
+	// MediaType is matched case-insensitively, ignoring parameters
+	// (e.g. "application/graphql"); "type/*" matches a whole type (e.g. "multipart/*")
+	MediaType string
+	// MatchAccept matches against the Accept header instead of Content-Type
+	MatchAccept bool
+	// Service is the service name to proxy to (e.g. "task_dispatcher")
+	Service string
+}
+
+// routeByContentType returns the service of the first ContentTypeRoutes rule of
+// the matched route that fits the request, or fallback if none does
+func (p *ProxyHandler) routeByContentType(c *gin.Context, fallback string) string {
+	for _, rule := range p.options.ContentTypeRoutes[c.FullPath()] {
+		if rule.MatchAccept {
+			for _, value := range strings.Split(c.GetHeader("Accept"), ",") {
+				if mediaTypeMatches(rule.MediaType, value) {
+					return rule.Service
+				}
+			}
+			continue
+		}
+		if mediaTypeMatches(rule.MediaType, c.GetHeader("Content-Type")) {
+			return rule.Service
+		}
+	}
+	return fallback
+}
+
+// mediaTypeMatches reports whether a header media type (with optional parameters) fits pattern
+func mediaTypeMatches(pattern, value string) bool {
+	mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(value))
+	if err != nil {
+		return false
+	}
+	pattern = strings.ToLower(pattern)
+	if major, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(mediaType, major+"/")
+	}
+	return mediaType == pattern
+}
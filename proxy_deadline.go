@@ -0,0 +1,79 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains request deadline handling for coordinated timeouts.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (may send X-Request-Timeout for latency-sensitive calls)
+//
+// The budget comes from the service (or external service) Timeout, shortened
+// by a client X-Request-Timeout clamped to MaxRequestTimeout. Backends receive
+// the resulting absolute deadline in X-Request-Deadline (RFC 3339, UTC).
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deadline propagation headers
+const (
+	HeaderRequestTimeout  = "X-Request-Timeout"
+	HeaderRequestDeadline = "X-Request-Deadline"
+)
+
+// requestTimeout returns the upstream budget for the request (0 means none).
+// configured is the upstream's own timeout; WebSocket upgrades keep only that,
+// since a client deadline would cut the long-lived connection.
+func (p *ProxyHandler) requestTimeout(c *gin.Context, serviceName string, configured time.Duration) time.Duration {
+	if c.GetHeader("Upgrade") != "" {
+		return configured
+	}
+
+	timeout := configured
+	if timeout <= 0 {
+		timeout = p.options.Services[serviceName].Timeout
+	}
+
+	// Client budgets are only honored when a maximum is configured
+	if p.options.MaxRequestTimeout <= 0 {
+		return timeout
+	}
+	requested, ok := parseRequestTimeout(c.GetHeader(HeaderRequestTimeout))
+	if !ok {
+		return timeout
+	}
+	if requested > p.options.MaxRequestTimeout {
+		requested = p.options.MaxRequestTimeout
+	}
+	if timeout <= 0 || requested < timeout {
+		timeout = requested
+	}
+	return timeout
+}
+
+// parseRequestTimeout parses a Go duration ("1500ms", "2s") or whole seconds ("2")
+func parseRequestTimeout(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, seconds > 0
+	}
+	d, err := time.ParseDuration(value)
+	return d, err == nil && d > 0
+}
+
+// setDeadlineHeaders replaces client-supplied deadline headers with the
+// deadline of the outgoing request context, if any
+func setDeadlineHeaders(req *http.Request) {
+	req.Header.Del(HeaderRequestTimeout)
+	req.Header.Del(HeaderRequestDeadline)
+	if deadline, ok := req.Context().Deadline(); ok {
+		req.Header.Set(HeaderRequestDeadline, deadline.UTC().Format(time.RFC3339Nano))
+	}
+}
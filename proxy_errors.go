@@ -0,0 +1,301 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains shared proxy error handling helpers.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (error formatting and display)
+package handlers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// statusClientClosedRequest is the non-standard status (nginx 499) recorded when the client disconnects
+const statusClientClosedRequest = 499
+
+// handleClientCanceled handles a proxy error caused by the client going away.
+// The upstream call is already canceled through the request context, so this
+// only records the outcome; no 502 is emitted since nobody is listening.
+// Returns false if err is a genuine upstream error.
+func handleClientCanceled(c *gin.Context, logger *zap.Logger, r *http.Request, err error) bool {
+	if !errors.Is(err, context.Canceled) || r.Context().Err() == nil {
+		return false
+	}
+
+	logger.Info("Proxy request canceled by client", logFields(c,
+		zap.String("outcome", "client_canceled"),
+		zap.String("method", r.Method),
+		zap.String("path", c.Request.URL.Path),
+	)...)
+
+	if !c.Writer.Written() {
+		c.Status(statusClientClosedRequest)
+	}
+	c.Abort()
+	return true
+}
+
+// Proxy failures mapped to HTTP responses by writeError. Helpers return them
+// wrapped in a *ProxyError, so callers and tests can match them with errors.Is.
+var (
+	// ErrServiceNotConfigured means the service has no URL (503 SERVICE_NOT_CONFIGURED)
+	ErrServiceNotConfigured = errors.New("service not configured")
+	// ErrUpstreamTimeout means the upstream did not respond within its deadline (504 GATEWAY_TIMEOUT)
+	ErrUpstreamTimeout = errors.New("upstream timed out")
+	// ErrUpstreamUnavailable means the upstream could not be reached or failed
+	// mid-response (502 UPSTREAM_UNAVAILABLE)
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+	// ErrCircuitOpen means the service refuses new requests to protect itself (503 UPSTREAM_BUSY)
+	ErrCircuitOpen = errors.New("service circuit open")
+	// ErrBulkheadFull means the service's MaxConcurrent bulkhead has no free slot (503 UPSTREAM_BUSY)
+	ErrBulkheadFull = errors.New("service bulkhead full")
+	// ErrRateLimited means the client exceeded its rate limit (429 RATE_LIMITED)
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// ProxyError is a proxy failure wrapping one of the Err* sentinels, with the
+// context its response needs
+type ProxyError struct {
+	// Service is the backend the request was for ("" if not service-specific)
+	Service string
+	// Err is the sentinel (e.g. ErrUpstreamTimeout)
+	Err error
+	// RetryAfter is sent in Retry-After when positive
+	RetryAfter time.Duration
+	// ConfiguredServices lists the services that are configured (ErrServiceNotConfigured only)
+	ConfiguredServices []string
+	// Details is the upstream error text, sent as "details" when set (ErrUpstreamUnavailable only)
+	Details string
+}
+
+func (e *ProxyError) Error() string {
+	if e.Service == "" {
+		return e.Err.Error()
+	}
+	return e.Service + ": " + e.Err.Error()
+}
+
+func (e *ProxyError) Unwrap() error {
+	return e.Err
+}
+
+// writeError sends the response for a proxy failure, mapping each Err*
+// sentinel to its status and {"error":{"code","message"}} envelope. A client
+// that went away (context.Canceled) gets no response; unknown errors get 500.
+func writeError(c *gin.Context, err error) {
+	proxyErr := &ProxyError{Err: err}
+	errors.As(err, &proxyErr)
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		c.Abort()
+	case errors.Is(err, ErrServiceNotConfigured):
+		// Names (never URLs) of the configured services help diagnose misrouted requests
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error": gin.H{
+				"code":                "SERVICE_NOT_CONFIGURED",
+				"message":             "Service " + proxyErr.Service + " is not configured",
+				"service":             proxyErr.Service,
+				"configured_services": proxyErr.ConfiguredServices,
+				"request_id":          ensureRequestID(c),
+			},
+		})
+	case errors.Is(err, ErrUpstreamTimeout):
+		c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{
+			"error": gin.H{
+				"code":    "GATEWAY_TIMEOUT",
+				"message": "Upstream service did not respond in time",
+			},
+		})
+	case errors.Is(err, ErrUpstreamUnavailable):
+		body := gin.H{
+			"code":    "UPSTREAM_UNAVAILABLE",
+			"message": "Service unavailable",
+		}
+		if proxyErr.Details != "" {
+			body["details"] = proxyErr.Details
+		}
+		c.AbortWithStatusJSON(http.StatusBadGateway, gin.H{"error": body})
+	case errors.Is(err, ErrCircuitOpen), errors.Is(err, ErrBulkheadFull):
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error": gin.H{
+				"code":    "UPSTREAM_BUSY",
+				"message": "Service " + proxyErr.Service + " is busy, please retry later",
+			},
+		})
+	case errors.Is(err, ErrRateLimited):
+		if proxyErr.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(proxyErr.RetryAfter.Seconds()))))
+		}
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+			"error": gin.H{
+				"code":    "RATE_LIMITED",
+				"message": "Too many requests, please retry later",
+			},
+		})
+	default:
+		sendInternalError(c)
+		c.Abort()
+	}
+}
+
+// errServiceNotConfigured logs and returns the ErrServiceNotConfigured failure of serviceName
+func (p *ProxyHandler) errServiceNotConfigured(c *gin.Context, serviceName string) error {
+	p.logger.Warn("Service not configured", logFields(c, serviceField(serviceName))...)
+	return &ProxyError{
+		Service:            serviceName,
+		Err:                ErrServiceNotConfigured,
+		ConfiguredServices: p.configuredServices(),
+	}
+}
+
+// configuredServices returns the sorted names of services that resolve to a URL
+func (p *ProxyHandler) configuredServices() []string {
+	seen := make(map[string]bool)
+	services := reflect.ValueOf(p.current.Load().ServiceURLs)
+	for i := 0; i < services.NumField(); i++ {
+		if name := serviceNameFromField(services.Type().Field(i).Name); p.resolvedServiceURL(name) != "" {
+			seen[name] = true
+		}
+	}
+	for name, service := range p.options.Services {
+		if service.URL != "" {
+			seen[name] = true
+		}
+	}
+	for name := range p.balancers {
+		seen[name] = true
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// serviceNameFromField converts a config.ServiceURLs field name to the service
+// name used by serviceURL, the way the route generator does
+// (e.g. "TaskDispatcher" -> "task_dispatcher", "KPIEngine" -> "k_p_i_engine")
+func serviceNameFromField(field string) string {
+	var b strings.Builder
+	for i, r := range field {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// errUpstreamUnavailable returns the ErrUpstreamUnavailable failure of
+// serviceName. The error text (which may name internal hosts and addresses) is
+// only carried as Details when exposeDetails is set; callers log the full
+// error server-side either way.
+func errUpstreamUnavailable(serviceName string, err error, exposeDetails bool) error {
+	proxyErr := &ProxyError{Service: serviceName, Err: ErrUpstreamUnavailable}
+	if exposeDetails {
+		proxyErr.Details = err.Error()
+	}
+	return proxyErr
+}
+
+// Upstream error reasons recorded in upstream_errors_total
+const (
+	upstreamErrorDial    = "dial"
+	upstreamErrorTimeout = "timeout"
+	upstreamErrorReset   = "reset"
+	upstreamErrorTLS     = "tls"
+	upstreamErrorOther   = "other"
+)
+
+// classifyUpstreamError maps an upstream transport error to a metrics reason label
+func classifyUpstreamError(err error) string {
+	var netErr net.Error
+	var opErr *net.OpError
+	var recordErr tls.RecordHeaderError
+	var verifyErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return upstreamErrorTimeout
+	case errors.As(err, &recordErr), errors.As(err, &verifyErr), errors.As(err, &authorityErr),
+		errors.As(err, &hostnameErr), errors.As(err, &invalidErr), strings.Contains(err.Error(), "tls: "):
+		return upstreamErrorTLS
+	case errors.Is(err, syscall.ECONNREFUSED), errors.As(err, &opErr) && opErr.Op == "dial":
+		return upstreamErrorDial
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return upstreamErrorReset
+	}
+	return upstreamErrorOther
+}
+
+// upstreamAttemptsKey is the request context key of the upstream attempt counter
+type upstreamAttemptsKey struct{}
+
+// withUpstreamAttempts returns r carrying a counter that retryTransport increments per attempt
+func withUpstreamAttempts(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), upstreamAttemptsKey{}, new(int)))
+}
+
+// countUpstreamAttempt records one upstream attempt on the request's counter, if any.
+// The reverse proxy calls its transport synchronously, so no locking is needed.
+func countUpstreamAttempt(r *http.Request) {
+	if attempts, ok := r.Context().Value(upstreamAttemptsKey{}).(*int); ok {
+		*attempts++
+	}
+}
+
+// upstreamAttempts returns the attempts counted for r (0 when retries are not enabled)
+func upstreamAttempts(r *http.Request) int {
+	if attempts, ok := r.Context().Value(upstreamAttemptsKey{}).(*int); ok {
+		return *attempts
+	}
+	return 0
+}
+
+// logUpstreamError logs a failed upstream call with the standard request
+// fields, its target and (with retries) attempt count, counts it in
+// upstream_errors_total and returns the classified reason.
+func (p *ProxyHandler) logUpstreamError(c *gin.Context, r *http.Request, serviceName, target string, start time.Time, err error) string {
+	reason := classifyUpstreamError(err)
+	p.options.Metrics.upstreamError(serviceName, reason)
+
+	fields := logFields(c,
+		zap.Error(err),
+		serviceField(serviceName),
+		zap.String("target", target),
+		zap.String("reason", reason),
+		durationField(time.Since(start)),
+	)
+	if attempts := upstreamAttempts(r); attempts > 0 {
+		fields = append(fields, zap.Int("attempts", attempts))
+	}
+	p.logger.Error("Proxy error", fields...)
+	return reason
+}
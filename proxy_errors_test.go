@@ -0,0 +1,93 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugjb/api-gateway/handlers"
+)
+
+// TestWriteError verifies each typed proxy error maps to its status and error envelope
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		sentinel       error
+		expectedStatus int
+		expectedCode   string
+		retryAfter     string
+	}{
+		{"service not configured", &handlers.ProxyError{Service: "reports", Err: handlers.ErrServiceNotConfigured},
+			handlers.ErrServiceNotConfigured, http.StatusServiceUnavailable, "SERVICE_NOT_CONFIGURED", ""},
+		{"upstream timeout", &handlers.ProxyError{Service: "reports", Err: handlers.ErrUpstreamTimeout},
+			handlers.ErrUpstreamTimeout, http.StatusGatewayTimeout, "GATEWAY_TIMEOUT", ""},
+		{"circuit open", &handlers.ProxyError{Service: "reports", Err: handlers.ErrCircuitOpen},
+			handlers.ErrCircuitOpen, http.StatusServiceUnavailable, "UPSTREAM_BUSY", ""},
+		{"bulkhead full", &handlers.ProxyError{Service: "reports", Err: handlers.ErrBulkheadFull},
+			handlers.ErrBulkheadFull, http.StatusServiceUnavailable, "UPSTREAM_BUSY", ""},
+		{"upstream unavailable", &handlers.ProxyError{Service: "reports", Err: handlers.ErrUpstreamUnavailable},
+			handlers.ErrUpstreamUnavailable, http.StatusBadGateway, "UPSTREAM_UNAVAILABLE", ""},
+		{"rate limited", &handlers.ProxyError{Err: handlers.ErrRateLimited, RetryAfter: 1500 * time.Millisecond},
+			handlers.ErrRateLimited, http.StatusTooManyRequests, "RATE_LIMITED", "2"},
+		{"rate limited whole seconds", &handlers.ProxyError{Err: handlers.ErrRateLimited, RetryAfter: 2 * time.Second},
+			handlers.ErrRateLimited, http.StatusTooManyRequests, "RATE_LIMITED", "2"},
+		{"wrapped sentinel", fmt.Errorf("fetching report: %w", handlers.ErrUpstreamTimeout),
+			handlers.ErrUpstreamTimeout, http.StatusGatewayTimeout, "GATEWAY_TIMEOUT", ""},
+		{"unknown error", errors.New("boom"), nil, http.StatusInternalServerError, "INTERNAL_ERROR", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.sentinel != nil && !errors.Is(tt.err, tt.sentinel) {
+				t.Fatalf("Expected errors.Is(%v, %v)", tt.err, tt.sentinel)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/api/v1/reports", nil)
+			handlers.WriteError(c, tt.err)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if !c.IsAborted() {
+				t.Error("Expected the context to be aborted")
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.retryAfter {
+				t.Errorf("Expected Retry-After '%s', got '%s'", tt.retryAfter, got)
+			}
+
+			var body struct {
+				Error struct {
+					Code    string `json:"code"`
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if body.Error.Code != tt.expectedCode || body.Error.Message == "" {
+				t.Errorf("Expected code %s with a message, got %+v", tt.expectedCode, body.Error)
+			}
+		})
+	}
+}
+
+// TestWriteErrorClientCanceled verifies no response is written for a client that went away
+func TestWriteErrorClientCanceled(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/v1/reports", nil)
+	handlers.WriteError(c, context.Canceled)
+
+	if !c.IsAborted() || c.Writer.Written() {
+		t.Errorf("Expected an aborted context without a response, got status %d body %q", w.Code, w.Body.String())
+	}
+}
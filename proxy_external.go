@@ -21,16 +21,17 @@ import (
 // Preserves original Host header for CSRF validation
 func (p *ProxyHandler) ProxyBugsink() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		serviceURL := p.getServiceURL("bugsink")
+		serviceURL := p.resolvedServiceURL("bugsink")
 		if serviceURL == "" {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"error": "Bugsink service not configured",
-			})
+			writeError(c, p.errServiceNotConfigured(c, "bugsink"))
 			return
 		}
 
-		// Strip /sentry prefix when forwarding - Bugsink routes at /
+		// Strip the mount prefix when forwarding (default: /sentry) - Bugsink routes at /
 		path := c.Param("path")
+		if p.hasPathRewrite("bugsink") {
+			path = p.rewriteServicePath("bugsink", c.Request.URL.Path)
+		}
 		if path == "" {
 			path = "/"
 		}
@@ -38,11 +39,13 @@ func (p *ProxyHandler) ProxyBugsink() gin.HandlerFunc {
 		target, err := url.Parse(serviceURL)
 		if err != nil {
 			p.logger.Error("Failed to parse target URL", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			writeError(c, err)
 			return
 		}
 
 		proxy := httputil.NewSingleHostReverseProxy(target)
+		proxy.Transport = p.trackedTransportFor("bugsink")
+		proxy.FlushInterval = p.flushInterval("bugsink")
 
 		// Preserve original Host header for CSRF validation
 		originalHost := c.Request.Host
@@ -66,18 +69,17 @@ func (p *ProxyHandler) ProxyBugsink() gin.HandlerFunc {
 				}
 			}
 
-			req.Header.Set("X-Forwarded-For", c.ClientIP())
-			req.Header.Set("X-Forwarded-Proto", "http")
-			req.Header.Set("X-Real-IP", c.ClientIP())
+			setForwardedHeaders(c, req, p.options.ForwardedHeaders)
 			req.Header.Set("X-Forwarded-Host", originalHost)
+			setForwardedPrefix(req, p.strippedPrefix("bugsink", c.Request.URL.Path))
 		}
 
 		proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+			if handleClientCanceled(c, p.logger, r, err) {
+				return
+			}
 			p.logger.Error("Bugsink proxy error", zap.Error(err))
-			c.JSON(http.StatusBadGateway, gin.H{
-				"error":   "Bugsink service unavailable",
-				"details": err.Error(),
-			})
+			writeError(c, errUpstreamUnavailable("bugsink", err, p.options.ExposeErrorDetails))
 		}
 
 		proxy.ServeHTTP(c.Writer, c.Request)
@@ -100,8 +102,14 @@ func (p *ProxyHandler) DirectProxy(targetURL string) gin.HandlerFunc {
 			return
 		}
 
-		// Create new request
-		req, err := http.NewRequest(c.Request.Method, target.String()+c.Request.URL.Path, strings.NewReader(string(body)))
+		// The escaped request path keeps encoded characters (e.g. %2F) intact;
+		// it is cleaned first so ".." cannot climb out of the target's base path,
+		// keeping a trailing slash the upstream may route on
+		endpoint := target.JoinPath(joinPath(c.Request.URL.EscapedPath()))
+		endpoint.RawQuery = c.Request.URL.RawQuery
+
+		// Create new request (tied to the client so a disconnect cancels it)
+		req, err := http.NewRequestWithContext(c.Request.Context(), c.Request.Method, endpoint.String(), strings.NewReader(string(body)))
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
 			return
@@ -121,7 +129,11 @@ func (p *ProxyHandler) DirectProxy(targetURL string) gin.HandlerFunc {
 		client := &http.Client{}
 		resp, err := client.Do(req)
 		if err != nil {
-			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to reach service"})
+			if handleClientCanceled(c, p.logger, req, err) {
+				return
+			}
+			p.logger.Error("Direct proxy error", zap.Error(err), zap.String("target", targetURL))
+			writeError(c, errUpstreamUnavailable("", err, p.options.ExposeErrorDetails))
 			return
 		}
 		defer resp.Body.Close()
@@ -0,0 +1,149 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains inbound header limits and forwarding headers for proxied requests.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (error formatting and display)
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// setForwardedHeaders describes the original request to the upstream with
+// X-Forwarded-For, X-Forwarded-Proto and X-Real-IP. With extended set it also
+// sends X-Forwarded-Host, X-Forwarded-Port and the RFC 7239 Forwarded header
+// (e.g. `Forwarded: for=203.0.113.7;proto=https;host=app.example.com`).
+// Client-supplied values are always replaced, never appended to.
+func setForwardedHeaders(c *gin.Context, req *http.Request, extended bool) {
+	clientIP := RealClientIP(c)
+	proto := forwardedProto(c)
+
+	req.Header.Set("X-Forwarded-For", clientIP)
+	req.Header.Set("X-Forwarded-Proto", proto)
+	req.Header.Set("X-Real-IP", clientIP)
+	if !extended {
+		return
+	}
+
+	host := c.Request.Host
+	req.Header.Set("X-Forwarded-Host", host)
+	req.Header.Set("X-Forwarded-Port", forwardedPort(c))
+
+	// IPv6 nodes are bracketed and, like any value with ':', quoted
+	node := clientIP
+	if strings.Contains(node, ":") {
+		node = "[" + node + "]"
+	}
+	req.Header.Set("Forwarded", "for="+forwardedValue(node)+";proto="+proto+";host="+forwardedValue(host))
+}
+
+// forwardedProto returns the scheme the client used: https on a TLS
+// connection, else X-Forwarded-Proto when a trusted proxy sent it, else http.
+// The header is ignored from untrusted peers, as in RealClientIP.
+func forwardedProto(c *gin.Context) string {
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	if fromTrustedProxy(c) {
+		if proto := strings.ToLower(c.GetHeader("X-Forwarded-Proto")); proto == "https" || proto == "http" {
+			return proto
+		}
+	}
+	return "http"
+}
+
+// setForwardedPrefix sets X-Forwarded-Prefix to the path prefix stripped
+// before proxying, so prefix-aware backends can build external URLs
+// (e.g. "/sentry" for a Bugsink mounted there). A client-supplied value is
+// dropped, and no header is sent when nothing was stripped.
+func setForwardedPrefix(req *http.Request, prefix string) {
+	req.Header.Del("X-Forwarded-Prefix")
+	if prefix != "" {
+		req.Header.Set("X-Forwarded-Prefix", prefix)
+	}
+}
+
+// forwardedPort returns the gateway port the client connected to: the listen
+// address port, else the port in Host, else the default port of the scheme
+func forwardedPort(c *gin.Context) string {
+	if addr, ok := c.Request.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		if _, port, err := net.SplitHostPort(addr.String()); err == nil {
+			return port
+		}
+	}
+	if _, port, err := net.SplitHostPort(c.Request.Host); err == nil && port != "" {
+		return port
+	}
+	if c.Request.TLS != nil {
+		return "443"
+	}
+	return "80"
+}
+
+// forwardedValue returns value as an RFC 7239 token, or as a quoted-string
+// when it contains characters outside the token set (e.g. ':' or '[')
+func forwardedValue(value string) string {
+	for _, r := range value {
+		if !isTokenChar(r) {
+			return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+		}
+	}
+	return value
+}
+
+// isTokenChar reports whether r is an RFC 7230 tchar
+func isTokenChar(r rune) bool {
+	return r < 0x7f && r > 0x20 && !strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r)
+}
+
+// headerSize returns the approximate wire size of h ("Key: value\r\n" per value)
+func headerSize(h http.Header) int {
+	size := 0
+	for key, values := range h {
+		for _, value := range values {
+			size += len(key) + len(value) + 4
+		}
+	}
+	return size
+}
+
+// checkHeaderSize rejects requests whose headers exceed MaxHeaderBytes with 431.
+// Returns false if a response was sent.
+func (p *ProxyHandler) checkHeaderSize(c *gin.Context) bool {
+	limit := p.options.MaxHeaderBytes
+	if limit <= 0 {
+		return true
+	}
+
+	size := headerSize(c.Request.Header)
+	if size <= limit {
+		return true
+	}
+
+	p.logger.Warn("Request headers too large",
+		zap.String("path", c.Request.URL.Path),
+		zap.Int("size", size),
+		zap.Int("limit", limit),
+	)
+	c.JSON(http.StatusRequestHeaderFieldsTooLarge, gin.H{
+		"error": gin.H{
+			"code":    "HEADERS_TOO_LARGE",
+			"message": "Request header fields too large",
+		},
+	})
+	return false
+}
+
+// limitHeaderValues caps the number of values forwarded for a single header
+func (p *ProxyHandler) limitHeaderValues(values []string) []string {
+	if max := p.options.MaxHeaderValues; max > 0 && len(values) > max {
+		return values[:max]
+	}
+	return values
+}
@@ -0,0 +1,101 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains URL rewriting for JSON response bodies.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (follows "self"/"href" links returned by backends)
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// isJSONContentType reports whether contentType is application/json or a +json type
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// rewriteJSONResponse rewrites URLs in a JSON response body and fixes up Content-Length.
+// Bodies that are not valid JSON or over 1 MiB are passed through unchanged.
+func rewriteJSONResponse(resp *http.Response, cfg JSONRewriteConfig, pathPrefix string) error {
+	body, ok, err := readResponseBody(resp)
+	if err != nil || !ok {
+		return err
+	}
+
+	if rewritten, err := rewriteJSONURLs(body, cfg, pathPrefix); err == nil {
+		body = rewritten
+	}
+
+	setResponseBody(resp, body)
+	return nil
+}
+
+// rewriteJSONURLs prepends pathPrefix to string values starting with one of cfg.Prefixes
+// (e.g. {"self":"/v1/tasks/1"} -> {"self":"/gw/v1/tasks/1"}). Numbers keep their
+// original representation and other strings are left untouched.
+func rewriteJSONURLs(body []byte, cfg JSONRewriteConfig, pathPrefix string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]bool, len(cfg.Fields))
+	for _, field := range cfg.Fields {
+		fields[field] = true
+	}
+
+	doc = rewriteJSONValue(doc, "", cfg.Prefixes, fields, pathPrefix)
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	// Keep "&", "<" and ">" in URLs as-is
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// rewriteJSONValue walks a decoded JSON value; key is the enclosing object key ("" for array items and the root)
+func rewriteJSONValue(v interface{}, key string, prefixes []string, fields map[string]bool, pathPrefix string) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = rewriteJSONValue(item, k, prefixes, fields, pathPrefix)
+		}
+		return value
+	case []interface{}:
+		for i, item := range value {
+			// Array items inherit the key of the array (e.g. "links": ["/v1/a", "/v1/b"])
+			value[i] = rewriteJSONValue(item, key, prefixes, fields, pathPrefix)
+		}
+		return value
+	case string:
+		if len(fields) > 0 && !fields[key] {
+			return value
+		}
+		if strings.HasPrefix(value, pathPrefix+"/") {
+			return value
+		}
+		for _, prefix := range prefixes {
+			if prefix != "" && strings.HasPrefix(value, prefix) {
+				return pathPrefix + value
+			}
+		}
+		return value
+	default:
+		return value
+	}
+}
@@ -0,0 +1,114 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugjb/api-gateway/config"
+	"github.com/ugjb/api-gateway/handlers"
+)
+
+// TestRewriteJSONURLsNested verifies prefixed URLs are rewritten at any depth and other values are untouched
+func TestRewriteJSONURLsNested(t *testing.T) {
+	body := []byte(`{
+		"self": "/v1/tasks/1",
+		"title": "/v1/ is our API version",
+		"count": 12345678901234567890,
+		"data": {"links": [{"href": "/v1/tasks/2"}, {"href": "https://example.com/v1/x"}]},
+		"already": "/gw/v1/tasks/3",
+		"query": "/v1/search?a=1&b=<2>"
+	}`)
+
+	t.Run("any field", func(t *testing.T) {
+		out, err := handlers.RewriteJSONURLs(body, handlers.JSONRewriteConfig{Prefixes: []string{"/v1/"}}, "/gw")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var doc map[string]interface{}
+		decoder := json.NewDecoder(bytes.NewReader(out))
+		decoder.UseNumber()
+		if err := decoder.Decode(&doc); err != nil {
+			t.Fatalf("Expected valid JSON, got %v: %s", err, out)
+		}
+
+		if doc["self"] != "/gw/v1/tasks/1" {
+			t.Errorf("Expected self rewritten, got %v", doc["self"])
+		}
+		if doc["already"] != "/gw/v1/tasks/3" {
+			t.Errorf("Expected already-prefixed value unchanged, got %v", doc["already"])
+		}
+		if doc["query"] != "/gw/v1/search?a=1&b=<2>" {
+			t.Errorf("Expected query string preserved, got %v", doc["query"])
+		}
+		if doc["count"] != json.Number("12345678901234567890") {
+			t.Errorf("Expected large number preserved, got %v", doc["count"])
+		}
+		links := doc["data"].(map[string]interface{})["links"].([]interface{})
+		if href := links[0].(map[string]interface{})["href"]; href != "/gw/v1/tasks/2" {
+			t.Errorf("Expected nested href rewritten, got %v", href)
+		}
+		if href := links[1].(map[string]interface{})["href"]; href != "https://example.com/v1/x" {
+			t.Errorf("Expected absolute URL unchanged, got %v", href)
+		}
+	})
+
+	t.Run("restricted fields", func(t *testing.T) {
+		cfg := handlers.JSONRewriteConfig{Prefixes: []string{"/v1/"}, Fields: []string{"href"}}
+		out, err := handlers.RewriteJSONURLs(body, cfg, "/gw")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var doc map[string]interface{}
+		json.Unmarshal(out, &doc)
+		if doc["self"] != "/v1/tasks/1" {
+			t.Errorf("Expected self untouched outside configured fields, got %v", doc["self"])
+		}
+		if doc["title"] != "/v1/ is our API version" {
+			t.Errorf("Expected title untouched, got %v", doc["title"])
+		}
+		links := doc["data"].(map[string]interface{})["links"].([]interface{})
+		if href := links[0].(map[string]interface{})["href"]; href != "/gw/v1/tasks/2" {
+			t.Errorf("Expected href rewritten, got %v", href)
+		}
+	})
+}
+
+// TestProxyPathRewriteJSONContentLength verifies proxied JSON is rewritten with a matching Content-Length
+func TestProxyPathRewriteJSONContentLength(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte(`{"items":[{"self":"/v1/tasks/1"}]}`))
+	}))
+	t.Cleanup(upstream.Close)
+
+	cfg := &config.Config{}
+	cfg.ServiceURLs.TaskDispatcher = upstream.URL
+
+	opts := handlers.DefaultProxyOptions()
+	opts.Services = map[string]handlers.ServiceConfig{
+		"task_dispatcher": {JSONRewrite: &handlers.JSONRewriteConfig{Prefixes: []string{"/v1/"}}},
+	}
+	proxyHandler := newTestProxyHandler(t, cfg, opts)
+
+	router := gin.New()
+	router.GET("/gw/v1/tasks", proxyHandler.ProxyRequestWithPathRewrite("task_dispatcher", "/v1/tasks", "/gw"))
+
+	req, _ := http.NewRequest(http.MethodGet, "/gw/v1/tasks", nil)
+	w := newProxyRecorder()
+	router.ServeHTTP(w, req)
+
+	expected := `{"items":[{"self":"/gw/v1/tasks/1"}]}`
+	if w.Body.String() != expected {
+		t.Errorf("Expected body %s, got %s", expected, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Length"); got != strconv.Itoa(len(expected)) {
+		t.Errorf("Expected Content-Length %d, got %s", len(expected), got)
+	}
+}
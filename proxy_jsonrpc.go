@@ -0,0 +1,209 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains the JSON-RPC batch splitter for backends that only
+// accept single JSON-RPC calls.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (apiClient - batches JSON-RPC calls)
+//
+// A batch (JSON array) is split into one upstream POST per call, sent
+// concurrently up to JSONRPCBatchConcurrency, and the responses are
+// reassembled in request order as required by the JSON-RPC 2.0 spec.
+// Single calls are proxied unchanged.
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// JSON-RPC 2.0 error codes used for batch items the gateway answers itself
+const (
+	jsonRPCInvalidRequest = -32600
+	jsonRPCUpstreamError  = -32603
+)
+
+// jsonRPCErrorResponse is a JSON-RPC 2.0 error response object
+type jsonRPCErrorResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Error   jsonRPCError    `json:"error"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// jsonRPCError is the error member of a JSON-RPC response
+type jsonRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// newJSONRPCError builds an error response for the call with the given id (nil means null)
+func newJSONRPCError(id json.RawMessage, code int, message string) json.RawMessage {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	out, _ := json.Marshal(jsonRPCErrorResponse{
+		JSONRPC: "2.0",
+		Error:   jsonRPCError{Code: code, Message: message},
+		ID:      id,
+	})
+	return out
+}
+
+// ProxyJSONRPCBatch returns a handler proxying JSON-RPC requests to a service
+// that only accepts single calls. Batches are split into concurrent upstream
+// calls and the responses reassembled in request order; a failed call becomes
+// a JSON-RPC error for its id while the other items still succeed.
+// Notifications (calls without an id) get no response item, and a batch of
+// only notifications gets 204. Non-batch requests are proxied as usual.
+func (p *ProxyHandler) ProxyJSONRPCBatch(serviceName, targetPath string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		serviceURL := p.resolveServiceURL(c, serviceName)
+		if serviceURL == "" {
+			writeError(c, p.errServiceNotConfigured(c, serviceName))
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, defaultMaxJSONBodyBytes))
+		if err != nil {
+			sendJSONBodyError(c, classifyJSONError(err, defaultMaxJSONBodyBytes))
+			return
+		}
+
+		var batch []json.RawMessage
+		trimmed := bytes.TrimSpace(body)
+		if len(trimmed) == 0 || trimmed[0] != '[' || json.Unmarshal(trimmed, &batch) != nil {
+			// Not a batch: restore the body and proxy the single call
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			c.Request.ContentLength = int64(len(body))
+			p.proxyRequest(c, serviceName, serviceURL, targetPath)
+			return
+		}
+
+		if len(batch) == 0 {
+			c.JSON(http.StatusOK, newJSONRPCError(nil, jsonRPCInvalidRequest, "Invalid Request"))
+			return
+		}
+		if limit := p.options.JSONRPCMaxBatchSize; limit > 0 && len(batch) > limit {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": gin.H{
+					"code":    "BATCH_TOO_LARGE",
+					"message": "JSON-RPC batch exceeds the maximum size",
+					"max":     limit,
+				},
+			})
+			return
+		}
+
+		target, err := url.Parse(serviceURL)
+		if err != nil {
+			p.logger.Error("Failed to parse target URL", zap.Error(err))
+			sendInternalError(c)
+			return
+		}
+		target.Path, target.RawPath = expandPathParams(targetPath, c.Params)
+		target.RawQuery = c.Request.URL.RawQuery
+
+		ctx := c.Request.Context()
+		if timeout := p.requestTimeout(c, serviceName, 0); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		responses := make([]json.RawMessage, len(batch))
+		slots := make(chan struct{}, p.options.JSONRPCBatchConcurrency)
+		var wg sync.WaitGroup
+		for i, item := range batch {
+			wg.Add(1)
+			slots <- struct{}{}
+			go func(i int, item json.RawMessage) {
+				defer wg.Done()
+				defer func() { <-slots }()
+				responses[i] = p.callJSONRPC(ctx, c, serviceName, serviceURL, target, item)
+			}(i, item)
+		}
+		wg.Wait()
+
+		out := make([]json.RawMessage, 0, len(responses))
+		for _, response := range responses {
+			if response != nil {
+				out = append(out, response)
+			}
+		}
+		if len(out) == 0 {
+			c.Status(http.StatusNoContent)
+			return
+		}
+		c.JSON(http.StatusOK, out)
+	}
+}
+
+// callJSONRPC sends one batch item upstream and returns its response object,
+// a JSON-RPC error if the call failed, or nil for a notification
+func (p *ProxyHandler) callJSONRPC(ctx context.Context, c *gin.Context, serviceName, serviceURL string, target *url.URL, item json.RawMessage) json.RawMessage {
+	var call map[string]json.RawMessage
+	if err := json.Unmarshal(item, &call); err != nil || call == nil {
+		return newJSONRPCError(nil, jsonRPCInvalidRequest, "Invalid Request")
+	}
+	id, hasID := call["id"]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.String(), bytes.NewReader(item))
+	if err != nil {
+		return newJSONRPCError(id, jsonRPCUpstreamError, "Internal error")
+	}
+	for _, name := range []string{"Authorization", "Accept-Language", HeaderRequestID} {
+		if value := c.GetHeader(name); value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setForwardedHeaders(c, req, p.options.ForwardedHeaders)
+	if tenantID := c.GetString(tenantIDKey); tenantID != "" {
+		req.Header.Set("X-Tenant-ID", tenantID)
+	}
+	if userID := c.GetString("user_id"); userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+	if email := c.GetString("email"); email != "" {
+		req.Header.Set("X-User-Email", email)
+	}
+	if err := p.transformRequest(c, serviceName, req); err != nil {
+		if !hasID {
+			return nil
+		}
+		return newJSONRPCError(id, jsonRPCInvalidRequest, "Invalid Request")
+	}
+
+	resp, err := p.roundTripperFor(serviceName).RoundTrip(req)
+	if err != nil {
+		p.recordUpstreamResult(serviceName, serviceURL, true)
+		p.logger.Warn("JSON-RPC batch call failed", logFields(c, serviceField(serviceName), zap.Error(err))...)
+		if !hasID {
+			return nil
+		}
+		return newJSONRPCError(id, jsonRPCUpstreamError, "Upstream unavailable")
+	}
+	defer resp.Body.Close()
+	p.recordUpstreamResult(serviceName, serviceURL, resp.StatusCode >= http.StatusInternalServerError)
+
+	respBody, err := io.ReadAll(resp.Body)
+	if !hasID {
+		return nil
+	}
+	if err != nil || !json.Valid(respBody) || len(bytes.TrimSpace(respBody)) == 0 {
+		p.logger.Warn("Invalid JSON-RPC batch call response", logFields(c,
+			serviceField(serviceName),
+			zap.Int("upstream_status", resp.StatusCode),
+		)...)
+		return newJSONRPCError(id, jsonRPCUpstreamError, "Upstream error")
+	}
+	return json.RawMessage(bytes.TrimSpace(respBody))
+}
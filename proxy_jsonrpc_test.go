@@ -0,0 +1,142 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugjb/api-gateway/config"
+	"github.com/ugjb/api-gateway/handlers"
+)
+
+// newJSONRPCUpstream creates a fake backend accepting single JSON-RPC calls only.
+// "slow" answers after a delay, "fail" breaks with a non-JSON 500 and "missing"
+// returns a JSON-RPC error.
+func newJSONRPCUpstream(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var call struct {
+			Method string          `json:"method"`
+			ID     json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&call); err != nil {
+			http.Error(w, "single calls only", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch call.Method {
+		case "slow":
+			time.Sleep(50 * time.Millisecond)
+			w.Write([]byte(`{"jsonrpc":"2.0","result":"slow done","id":` + string(call.ID) + `}`))
+		case "fail":
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("boom"))
+		case "missing":
+			w.Write([]byte(`{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":` + string(call.ID) + `}`))
+		default:
+			w.Write([]byte(`{"jsonrpc":"2.0","result":"` + call.Method + ` done","id":` + string(call.ID) + `}`))
+		}
+	}))
+	t.Cleanup(upstream.Close)
+	return upstream
+}
+
+// setupJSONRPCRouter creates a router splitting JSON-RPC batches for the task dispatcher
+func setupJSONRPCRouter(t *testing.T, upstreamURL string) *gin.Engine {
+	cfg := &config.Config{}
+	cfg.ServiceURLs.TaskDispatcher = upstreamURL
+	proxyHandler := newTestProxyHandler(t, cfg, handlers.DefaultProxyOptions())
+
+	router := gin.New()
+	router.POST("/api/v1/rpc", proxyHandler.ProxyJSONRPCBatch("task_dispatcher", "/rpc"))
+	return router
+}
+
+// postJSONRPC posts body to the JSON-RPC route
+func postJSONRPC(router *gin.Engine, body string) *closeNotifyRecorder {
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/rpc", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := newProxyRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestProxyJSONRPCBatch verifies batches are split, reassembled in order and keep per-item errors
+func TestProxyJSONRPCBatch(t *testing.T) {
+	var calls atomic.Int32
+	router := setupJSONRPCRouter(t, newJSONRPCUpstream(t, &calls).URL)
+
+	w := postJSONRPC(router, `[
+		{"jsonrpc":"2.0","method":"slow","id":1},
+		{"jsonrpc":"2.0","method":"fail","id":"two"},
+		{"jsonrpc":"2.0","method":"fast","id":3}
+	]`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 upstream calls, got %d", calls.Load())
+	}
+
+	var responses []struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &responses); err != nil {
+		t.Fatalf("Expected a JSON array, got error: %v (%s)", err, w.Body.String())
+	}
+	if len(responses) != 3 {
+		t.Fatalf("Expected 3 responses, got %d", len(responses))
+	}
+	if string(responses[0].ID) != "1" || responses[0].Result != "slow done" {
+		t.Errorf("Expected the slow call first, got %+v", responses[0])
+	}
+	if string(responses[1].ID) != `"two"` || responses[1].Error == nil || responses[1].Error.Code != -32603 {
+		t.Errorf("Expected an internal error for id \"two\", got %s", w.Body.String())
+	}
+	if string(responses[2].ID) != "3" || responses[2].Result != "fast done" {
+		t.Errorf("Expected the fast call last, got %+v", responses[2])
+	}
+}
+
+// TestProxyJSONRPCBatchItems verifies upstream JSON-RPC errors, invalid items and notifications
+func TestProxyJSONRPCBatchItems(t *testing.T) {
+	var calls atomic.Int32
+	router := setupJSONRPCRouter(t, newJSONRPCUpstream(t, &calls).URL)
+
+	tests := []struct {
+		name         string
+		body         string
+		expectStatus int
+		expectBody   string
+	}{
+		{"upstream error kept", `[{"jsonrpc":"2.0","method":"missing","id":1},42]`, http.StatusOK,
+			`[{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":1},` +
+				`{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}]`},
+		{"notification skipped", `[{"jsonrpc":"2.0","method":"log"},{"jsonrpc":"2.0","method":"fast","id":7}]`, http.StatusOK,
+			`[{"jsonrpc":"2.0","result":"fast done","id":7}]`},
+		{"only notifications", `[{"jsonrpc":"2.0","method":"log"}]`, http.StatusNoContent, ``},
+		{"empty batch", `[]`, http.StatusOK, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`},
+		{"single call proxied", `{"jsonrpc":"2.0","method":"fast","id":9}`, http.StatusOK, `{"jsonrpc":"2.0","result":"fast done","id":9}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postJSONRPC(router, tt.body)
+			if w.Code != tt.expectStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectStatus, w.Code, w.Body.String())
+			}
+			if got := strings.TrimSpace(w.Body.String()); got != tt.expectBody {
+				t.Errorf("Expected body %s, got %s", tt.expectBody, got)
+			}
+		})
+	}
+}
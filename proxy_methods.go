@@ -0,0 +1,73 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains the allowed-methods filter for proxied routes, letting
+// operators lock down mutation endpoints at the gateway, and the per-route
+// upstream method override for legacy backends.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (error formatting and display)
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// allowedMethods returns the methods permitted for the matched route, or for
+// serviceName when the route has no entry; empty means every method is allowed
+func (p *ProxyHandler) allowedMethods(c *gin.Context, serviceName string) []string {
+	if methods, ok := p.options.RouteAllowedMethods[c.FullPath()]; ok {
+		return methods
+	}
+	return p.options.Services[serviceName].AllowedMethods
+}
+
+// checkAllowedMethod rejects methods not permitted for the route or service
+// with 405 and an Allow header. HEAD is allowed wherever GET is.
+// Returns false if an error response was sent.
+func (p *ProxyHandler) checkAllowedMethod(c *gin.Context, serviceName string) bool {
+	methods := p.allowedMethods(c, serviceName)
+	if len(methods) == 0 {
+		return true
+	}
+
+	allow := make([]string, 0, len(methods)+1)
+	hasGet, hasHead := false, false
+	for _, method := range methods {
+		method = strings.ToUpper(method)
+		if method == c.Request.Method {
+			return true
+		}
+		hasGet = hasGet || method == http.MethodGet
+		hasHead = hasHead || method == http.MethodHead
+		allow = append(allow, method)
+	}
+	if hasGet && !hasHead {
+		if c.Request.Method == http.MethodHead {
+			return true
+		}
+		allow = append(allow, http.MethodHead)
+	}
+
+	c.Header("Allow", strings.Join(allow, ", "))
+	MethodNotAllowedHandler(c)
+	c.Abort()
+	return false
+}
+
+// HeaderMethodOverride carries the client's method when RouteMethodOverrides changed it
+const HeaderMethodOverride = "X-HTTP-Method-Override"
+
+// overrideMethod applies RouteMethodOverrides to the upstream request: for a
+// configured "METHOD /route" the upstream gets the mapped method and the
+// client's method in X-HTTP-Method-Override. Other routes are left untouched.
+func (p *ProxyHandler) overrideMethod(c *gin.Context, req *http.Request) {
+	method := strings.ToUpper(p.options.RouteMethodOverrides[c.Request.Method+" "+c.FullPath()])
+	if method == "" || method == c.Request.Method {
+		return
+	}
+	req.Method = method
+	req.Header.Set(HeaderMethodOverride, c.Request.Method)
+}
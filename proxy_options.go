@@ -0,0 +1,362 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains gateway-level proxy options that complement config.Config.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (API_BASE_URL must match APIBasePath)
+package handlers
+
+import (
+	"strings"
+	"time"
+)
+
+// ProxyOptions configures proxy behavior for a ProxyHandler
+type ProxyOptions struct {
+	// APIBasePath is the prefix the gateway API is mounted under (e.g. "/api" or "/gw/api")
+	APIBasePath string
+	// Tenants maps a request host (e.g. "app-a.example.com") to tenant-specific upstreams
+	Tenants map[string]TenantConfig
+	// Services holds per-service proxy settings keyed by service name (e.g. "task_dispatcher")
+	Services map[string]ServiceConfig
+	// ExternalServices lists third-party targets used by ProxyToExternalService, keyed by name
+	ExternalServices map[string]ExternalServiceConfig
+	// DefaultServiceURL is the catch-all backend of ProxyDefault (e.g. "http://frontend-dev:5173");
+	// empty makes unmatched routes return JSON 404
+	DefaultServiceURL string
+	// UpstreamTLS applies to services without their own TLS settings (nil uses system defaults)
+	UpstreamTLS *UpstreamTLSConfig
+	// AllowInsecureUpstreamTLS permits InsecureSkipVerify; never enable outside development
+	AllowInsecureUpstreamTLS bool
+	// ConnectionPool tunes keep-alive connection reuse of every upstream transport
+	ConnectionPool ConnectionPoolConfig
+	// ForwardedHeaders also sends X-Forwarded-Host, X-Forwarded-Port and the RFC 7239
+	// Forwarded header upstream, alongside X-Forwarded-For/Proto and X-Real-IP
+	ForwardedHeaders bool
+	// ExposeErrorDetails adds the upstream error text as "details" to 502 responses.
+	// It can reveal internal hostnames and addresses; never enable in production
+	ExposeErrorDetails bool
+	// SlowRequestThreshold logs proxied requests slower than this at Warn level (0 disables)
+	SlowRequestThreshold time.Duration
+	// LogAllRequests logs every other proxied request at Info level
+	LogAllRequests bool
+	// MaxHeaderBytes rejects requests whose headers exceed this size with 431 (0 disables)
+	MaxHeaderBytes int
+	// MaxHeaderValues caps the number of values forwarded per header (0 forwards all)
+	MaxHeaderValues int
+	// RetryBudgetPercent caps retries at this percentage of requests in the rolling window
+	RetryBudgetPercent float64
+	// RetryBudgetMinRetries always allows this many retries per window (low-traffic floor)
+	RetryBudgetMinRetries int
+	// RetryBudgetWindow is the rolling window of the retry budget
+	RetryBudgetWindow time.Duration
+	// WSMaxConnections caps concurrent proxied WebSocket connections; further
+	// upgrades get 503 WS_CAPACITY (0 means unlimited)
+	WSMaxConnections int
+	// WSPaths lists API paths (e.g. "/api/ws", matching its subpaths too) where
+	// ProxyWithWebSocket and ProxyDefault accept WebSocket upgrades; other API
+	// paths, and non-upgrade requests to these, still get the JSON 404
+	WSPaths []string
+	// Metrics records WebSocket connections, upstream errors, bulkhead queues and body sizes (nil disables metrics)
+	Metrics *GatewayMetrics
+	// RequestTransformers run in order on every upstream request, before service-specific ones
+	RequestTransformers []RequestTransformer
+	// ResponseTransformers run in order on every proxied response, before service-specific ones
+	ResponseTransformers []ResponseTransformer
+	// MaxRequestTimeout clamps client X-Request-Timeout budgets (0 ignores the header)
+	MaxRequestTimeout time.Duration
+	// SelfTestTimeout bounds SelfTest as a whole (0 relies on the caller's context)
+	SelfTestTimeout time.Duration
+	// ResponseCacheMaxBytes bounds the response cache shared by services with a CacheTTL
+	ResponseCacheMaxBytes int64
+	// RouteSchemas maps "METHOD /route/pattern" (e.g. "POST /api/v1/tasks") to a JSON Schema
+	// file; matching request bodies are validated before they are proxied
+	RouteSchemas map[string]string
+	// RouteAllowedMethods maps a route pattern (e.g. "/api/v1/tasks/:id") to the methods it
+	// may proxy, overriding the service's AllowedMethods; others get 405 with an Allow header
+	RouteAllowedMethods map[string][]string
+	// JSONRPCBatchConcurrency caps the calls of one batch ProxyJSONRPCBatch sends upstream at once
+	JSONRPCBatchConcurrency int
+	// JSONRPCMaxBatchSize rejects larger JSON-RPC batches with 413 BATCH_TOO_LARGE (0 means unlimited)
+	JSONRPCMaxBatchSize int
+	// RouteMethodOverrides maps "METHOD /route/pattern" (e.g. "DELETE /api/v1/tasks/:id") to the
+	// method sent upstream (e.g. "POST") for backends that only accept some methods; the
+	// client's method is sent in X-HTTP-Method-Override. Other routes keep their method
+	RouteMethodOverrides map[string]string
+	// ContentTypeRoutes maps a route pattern (e.g. "/api/v1/query") to rules choosing the
+	// service by Content-Type or Accept for ProxyToService; the first match wins and
+	// requests matching no rule go to the service given to ProxyToService
+	ContentTypeRoutes map[string][]ContentTypeRoute
+	// ShadowRoutes maps "METHOD /route/pattern" (e.g. "GET /api/v1/tasks") to a shadow
+	// upstream receiving a copy of a sample of the route's requests; shadow responses
+	// are discarded and never affect the client
+	ShadowRoutes map[string]ShadowConfig
+	// ShadowMaxInFlight caps mirrored requests in flight across all shadow routes;
+	// further samples are dropped (default 100)
+	ShadowMaxInFlight int
+	// SLO records every proxied request's latency and outcome per service for the
+	// admin SLO report (nil disables SLO tracking)
+	SLO *SLOTracker
+	// APIVersions maps a path version segment (e.g. "v2" in /api/v2/tasks) to its
+	// upstreams and lifecycle; APIVersioning rejects versions not listed. Empty
+	// disables version-based routing
+	APIVersions map[string]APIVersionConfig
+}
+
+// ServiceConfig holds proxy settings for a single backend service
+type ServiceConfig struct {
+	// URL is the service's base URL (e.g. "http://task-dispatcher:8080"); it
+	// overrides the config.ServiceURLs entry, which is used when empty
+	URL string
+	// HealthPath is the service's health endpoint (e.g. "/healthz") for health
+	// checks built on LookupService; empty means the service has none
+	HealthPath string
+	// TLS configures certificates used when dialing the service (mTLS)
+	TLS *UpstreamTLSConfig
+	// Protocol selects the upstream HTTP version: "http1" (default), "h2" or "h2c"
+	Protocol string
+	// JSONRewrite enables URL rewriting in JSON response bodies (path-rewrite proxying only)
+	JSONRewrite *JSONRewriteConfig
+	// AcceptEncoding replaces the client's Accept-Encoding toward the service on
+	// plain proxying: "identity" for services that compress poorly, "gzip" to save
+	// bandwidth. Empty forwards the client's header
+	AcceptEncoding string
+	// CompressRewrites gzips bodies rewritten by path-rewrite proxying again for
+	// clients that accept gzip; otherwise they are sent uncompressed
+	CompressRewrites bool
+	// MaxConcurrent caps in-flight requests to the service (0 means unlimited)
+	MaxConcurrent int
+	// Timeout bounds each proxied request; backends get the deadline in X-Request-Deadline (0 means none)
+	Timeout time.Duration
+	// QueueTimeout is how long a request waits for a free slot before 503 (0 rejects immediately)
+	QueueTimeout time.Duration
+	// MaxQueue caps requests waiting for a free slot; further ones get 503 at once (0 means unlimited)
+	MaxQueue int
+	// MaxRetries retries idempotent bodiless requests on connection errors and 502/503/504
+	MaxRetries int
+	// RetryBackoff is the wait before each retry
+	RetryBackoff time.Duration
+	// WebSocketHeaders are added to the WebSocket handshake sent to the service
+	WebSocketHeaders map[string]string
+	// StripPrefix is removed from the request path before proxying (e.g. "/sentry")
+	StripPrefix string
+	// AddPrefix is prepended to the (stripped) request path (e.g. "/api/v2")
+	AddPrefix string
+	// RequestTransformers run in order on the service's upstream requests
+	RequestTransformers []RequestTransformer
+	// ResponseTransformers run in order on the service's proxied responses
+	ResponseTransformers []ResponseTransformer
+	// CacheTTL caches successful GET responses for this long (0 disables caching)
+	CacheTTL time.Duration
+	// AllowedMethods restricts the methods proxied to the service (e.g. GET, POST);
+	// others get 405 with an Allow header. Empty allows every method
+	AllowedMethods []string
+	// Instances load-balances the service round-robin over these base URLs
+	// (e.g. "http://task-dispatcher-0:8080") instead of its configured URL
+	Instances []string
+	// InstanceWeights weights Instances by URL for smooth weighted round-robin;
+	// unlisted instances weigh 1 and weight 0 drains an instance (no new requests)
+	InstanceWeights map[string]int
+	// OutlierDetection temporarily ejects Instances with a high error rate (nil disables)
+	OutlierDetection *OutlierDetectionConfig
+	// BufferResponses keeps ReverseProxy's default buffered copy; when false each
+	// upstream write is flushed to the client immediately (lower latency)
+	BufferResponses bool
+	// Canary sends a share of the service's users to a new version (nil disables)
+	Canary *CanaryConfig
+}
+
+// CanaryConfig splits a service's traffic between its stable upstream and a canary
+type CanaryConfig struct {
+	// URL is the canary's base URL (e.g. "http://task-dispatcher-canary:8080")
+	URL string
+	// Percent of users routed to the canary, from 0 to 100. Users are assigned
+	// by a hash of their user_id (client IP when anonymous), so each one
+	// consistently hits the same variant
+	Percent float64
+}
+
+// JSONRewriteConfig selects which JSON string values get the gateway path prefix
+type JSONRewriteConfig struct {
+	// Prefixes lists upstream path prefixes to rewrite (e.g. "/v1/"); values must start with one
+	Prefixes []string
+	// Fields restricts rewriting to these object keys (e.g. "self", "href"); empty means any key
+	Fields []string
+}
+
+// OutlierDetectionConfig ejects load-balanced instances whose error rate
+// (connection errors and 5xx responses) over Window reaches ErrorRate.
+// Each consecutive ejection of an instance doubles its ejection time.
+type OutlierDetectionConfig struct {
+	// ErrorRate is the failed share of requests that ejects an instance, in (0, 1] (default 0.5)
+	ErrorRate float64
+	// MinRequests is the number of requests in Window before an instance can be ejected (default 5)
+	MinRequests int
+	// Window is the rolling window error rates are measured over (default 10s)
+	Window time.Duration
+	// BaseEjectionTime is the length of an instance's first ejection (default 30s)
+	BaseEjectionTime time.Duration
+	// MaxEjectionTime caps the doubled ejection time (default 5m)
+	MaxEjectionTime time.Duration
+	// MaxEjectionPercent caps the share of instances ejected at once (default 50);
+	// at least one instance always stays in rotation
+	MaxEjectionPercent int
+}
+
+// Upstream protocols for ServiceConfig.Protocol
+const (
+	UpstreamProtocolHTTP1 = "http1"
+	UpstreamProtocolH2    = "h2"
+	UpstreamProtocolH2C   = "h2c"
+)
+
+// Upstream encodings for ServiceConfig.AcceptEncoding
+const (
+	UpstreamEncodingIdentity = "identity"
+	UpstreamEncodingGzip     = "gzip"
+)
+
+// ExternalServiceConfig describes a third-party service reached through the gateway
+type ExternalServiceConfig struct {
+	// BaseURL is the service origin (e.g. "https://api.vendor.example")
+	BaseURL string
+	// Timeout bounds each proxied request (0 means no gateway timeout)
+	Timeout time.Duration
+	// TLS configures certificates used when dialing the service
+	TLS *UpstreamTLSConfig
+	// Headers are injected into every request (e.g. an API key header)
+	Headers map[string]string
+}
+
+// UpstreamTLSConfig configures TLS for gateway to backend connections
+type UpstreamTLSConfig struct {
+	// CertFile and KeyFile are the PEM client certificate presented to the upstream
+	CertFile string
+	KeyFile  string
+	// CAFile is a PEM bundle used to verify the upstream certificate
+	CAFile string
+	// InsecureSkipVerify disables verification; requires AllowInsecureUpstreamTLS
+	InsecureSkipVerify bool
+}
+
+// ConnectionPoolConfig tunes upstream connection pooling; zero values keep the
+// net/http defaults (100 idle connections, 2 per host, no per-host cap, 90s idle timeout)
+type ConnectionPoolConfig struct {
+	// MaxIdleConns caps idle keep-alive connections across all hosts
+	MaxIdleConns int
+	// MaxIdleConnsPerHost caps idle keep-alive connections kept per host
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost caps dialing, active and idle connections per host; further requests wait
+	MaxConnsPerHost int
+	// IdleConnTimeout closes keep-alive connections idle for longer
+	IdleConnTimeout time.Duration
+	// WarmUpConns is the number of idle connections WarmUp opens to each upstream,
+	// capped by the per-host limits (0 disables warm-up)
+	WarmUpConns int
+	// WarmUpTimeout bounds WarmUp as a whole (default 5s)
+	WarmUpTimeout time.Duration
+}
+
+// ShadowConfig mirrors a route's traffic to a shadow service
+type ShadowConfig struct {
+	// Service is the service name receiving the mirrored requests
+	Service string
+	// SampleRate is the fraction of requests mirrored, from 0 (none) to 1 (all)
+	SampleRate float64
+	// Timeout bounds each mirrored request (0 uses DefaultShadowTimeout)
+	Timeout time.Duration
+}
+
+// TenantConfig describes the upstreams of one tenant
+type TenantConfig struct {
+	// ID is forwarded upstream in the X-Tenant-ID header
+	ID string
+	// ServiceURLs overrides service URLs by service name; missing services use the default config
+	ServiceURLs map[string]string
+}
+
+// APIVersionConfig configures one API version
+type APIVersionConfig struct {
+	// ServiceURLs overrides service URLs by service name for this version;
+	// missing services use the default config
+	ServiceURLs map[string]string
+	// Deprecated adds a Deprecation header to the version's responses
+	Deprecated bool
+	// DeprecatedAt is the date sent in the Deprecation header (zero sends "true")
+	DeprecatedAt time.Time
+	// Sunset is sent in the Sunset header of deprecated versions; once passed
+	// the version is removed
+	Sunset time.Time
+	// Removed answers every request to the version with 410 API_VERSION_GONE
+	Removed bool
+}
+
+// DefaultProxyOptions returns the options used by NewProxyHandler
+func DefaultProxyOptions() ProxyOptions {
+	return ProxyOptions{
+		APIBasePath:      "/api",
+		MaxHeaderBytes:   32 << 10,
+		MaxHeaderValues:  16,
+		ForwardedHeaders: true,
+		// Retries may add at most 20% load on top of regular traffic
+		RetryBudgetPercent:      20,
+		RetryBudgetMinRetries:   10,
+		RetryBudgetWindow:       10 * time.Second,
+		ResponseCacheMaxBytes:   64 << 20,
+		MaxRequestTimeout:       30 * time.Second,
+		SelfTestTimeout:         3 * time.Second,
+		JSONRPCBatchConcurrency: 4,
+		JSONRPCMaxBatchSize:     100,
+		ShadowMaxInFlight:       100,
+		Services: map[string]ServiceConfig{
+			// Bugsink is mounted at /sentry but routes at /
+			"bugsink": {StripPrefix: "/sentry"},
+		},
+	}
+}
+
+// normalize fills in defaults and cleans up user-provided values
+func (o ProxyOptions) normalize() ProxyOptions {
+	o.APIBasePath = "/" + strings.Trim(o.APIBasePath, "/")
+	if o.APIBasePath == "/" {
+		o.APIBasePath = DefaultProxyOptions().APIBasePath
+	}
+
+	// Hosts are matched case-insensitively
+	tenants := make(map[string]TenantConfig, len(o.Tenants))
+	for host, tenant := range o.Tenants {
+		tenants[strings.ToLower(host)] = tenant
+	}
+	o.Tenants = tenants
+
+	if o.JSONRPCBatchConcurrency <= 0 {
+		o.JSONRPCBatchConcurrency = DefaultProxyOptions().JSONRPCBatchConcurrency
+	}
+	if o.ShadowMaxInFlight <= 0 {
+		o.ShadowMaxInFlight = DefaultProxyOptions().ShadowMaxInFlight
+	}
+
+	return o
+}
+
+// APIPath joins path onto the configured API base path (e.g. "/v1/auth" -> "/api/v1/auth")
+func (p *ProxyHandler) APIPath(path string) string {
+	if path == "" {
+		return p.options.APIBasePath
+	}
+	return p.options.APIBasePath + "/" + strings.TrimPrefix(path, "/")
+}
+
+// isAPIPath reports whether path is under the configured API base path
+func (p *ProxyHandler) isAPIPath(path string) bool {
+	return strings.HasPrefix(path, p.options.APIBasePath+"/")
+}
+
+// flushInterval returns the ReverseProxy FlushInterval for a service:
+// 0 buffers responses, -1 flushes after every write
+func (p *ProxyHandler) flushInterval(serviceName string) time.Duration {
+	if p.options.Services[serviceName].BufferResponses {
+		return 0
+	}
+	return -1
+}
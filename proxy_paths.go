@@ -0,0 +1,90 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains upstream path building: route param substitution and
+// per-service path rewriting (prefix stripping/adding).
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (apiClient - all API calls proxied through gateway)
+package handlers
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// expandPathParams replaces every ":name" segment of targetPath with the route
+// param of that name, for any HTTP method (e.g. PATCH "/tasks/:id" -> "/tasks/42").
+// It returns the decoded path and its escaped form, so values containing "/" or
+// other reserved characters stay a single upstream path segment.
+// Params missing from the route are replaced with an empty segment.
+func expandPathParams(targetPath string, params gin.Params) (path, rawPath string) {
+	if !strings.Contains(targetPath, "/:") {
+		return targetPath, ""
+	}
+
+	segments := strings.Split(targetPath, "/")
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = segment
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		value := params.ByName(segment[1:])
+		segments[i] = value
+		escaped[i] = url.PathEscape(value)
+	}
+	return strings.Join(segments, "/"), strings.Join(escaped, "/")
+}
+
+// hasPathRewrite reports whether serviceName has StripPrefix or AddPrefix configured
+func (p *ProxyHandler) hasPathRewrite(serviceName string) bool {
+	service := p.options.Services[serviceName]
+	return service.StripPrefix != "" || service.AddPrefix != ""
+}
+
+// rewriteServicePath maps a gateway request path to the upstream path using the
+// service's StripPrefix and AddPrefix rules
+// (e.g. strip "/sentry", add "/bugsink": "/sentry/api/1" -> "/bugsink/api/1").
+func (p *ProxyHandler) rewriteServicePath(serviceName, requestPath string) string {
+	service := p.options.Services[serviceName]
+
+	path := strings.TrimPrefix(requestPath, p.strippedPrefix(serviceName, requestPath))
+	if path == "" {
+		path = "/"
+	}
+
+	if service.AddPrefix != "" {
+		path = joinPath(service.AddPrefix, path)
+	}
+	return path
+}
+
+// joinPath joins unescaped URL path elements with single slashes into an
+// absolute, cleaned path, keeping the trailing slash of the last element
+// (e.g. "api/v2/", "/tasks/" -> "/api/v2/tasks/"). Each element is cleaned on
+// its own, so ".." in a request path cannot climb out of a configured prefix.
+func joinPath(elems ...string) string {
+	cleaned := make([]string, len(elems))
+	for i, elem := range elems {
+		cleaned[i] = path.Clean("/" + elem)
+	}
+	joined := path.Join(cleaned...)
+	if last := elems[len(elems)-1]; strings.HasSuffix(last, "/") && joined != "/" {
+		joined += "/"
+	}
+	return joined
+}
+
+// strippedPrefix returns the service's StripPrefix (without trailing slash)
+// when it applies to requestPath, or "" when nothing is stripped. Only whole
+// segments match ("/sentry" must not strip "/sentryx").
+func (p *ProxyHandler) strippedPrefix(serviceName, requestPath string) string {
+	strip := strings.TrimSuffix(p.options.Services[serviceName].StripPrefix, "/")
+	if strip == "" || (requestPath != strip && !strings.HasPrefix(requestPath, strip+"/")) {
+		return ""
+	}
+	return strip
+}
@@ -0,0 +1,170 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains upstream connection pool tuning and the per-host pool
+// statistics reported by the admin API.
+//
+// Associated Frontend Files:
+//   - web/app/src/pages/AdminPage.tsx (connection pool stats)
+//
+// net/http does not expose its pool, so connections are tracked by wrapping
+// the transport dialer: a connection is active from the moment a request gets
+// it until it is returned to the idle pool. Multiplexed HTTP/2 connections are
+// never returned and count as active once used.
+package handlers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// PoolHostStats reports the upstream connections open to one host
+type PoolHostStats struct {
+	Host   string `json:"host"`
+	Open   int    `json:"open"`
+	Active int    `json:"active"`
+	Idle   int    `json:"idle"`
+}
+
+// PoolStatsReporter reports upstream connection pool usage (implemented by ProxyHandler)
+type PoolStatsReporter interface {
+	PoolStats() []PoolHostStats
+}
+
+// connTracker records the open upstream connections of every transport, by dialed host
+type connTracker struct {
+	mu    sync.Mutex
+	conns map[string]map[*trackedConn]struct{}
+}
+
+// trackedConn is an upstream connection registered with a connTracker
+type trackedConn struct {
+	net.Conn
+	tracker *connTracker
+	host    string
+	active  atomic.Bool
+	once    sync.Once
+}
+
+func newConnTracker() *connTracker {
+	return &connTracker{conns: make(map[string]map[*trackedConn]struct{})}
+}
+
+// wrapDialer returns dial registering every connection it opens
+func (t *connTracker) wrapDialer(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		tc := &trackedConn{Conn: conn, tracker: t, host: addr}
+		t.mu.Lock()
+		if t.conns[addr] == nil {
+			t.conns[addr] = make(map[*trackedConn]struct{})
+		}
+		t.conns[addr][tc] = struct{}{}
+		t.mu.Unlock()
+		return tc, nil
+	}
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(func() {
+		c.tracker.mu.Lock()
+		delete(c.tracker.conns[c.host], c)
+		if len(c.tracker.conns[c.host]) == 0 {
+			delete(c.tracker.conns, c.host)
+		}
+		c.tracker.mu.Unlock()
+	})
+	return c.Conn.Close()
+}
+
+// trackedConnOf unwraps conn (e.g. a *tls.Conn) down to its trackedConn, if any
+func trackedConnOf(conn net.Conn) *trackedConn {
+	for conn != nil {
+		if tc, ok := conn.(*trackedConn); ok {
+			return tc
+		}
+		inner, ok := conn.(interface{ NetConn() net.Conn })
+		if !ok {
+			return nil
+		}
+		conn = inner.NetConn()
+	}
+	return nil
+}
+
+// trackConnUsage returns a round tripper marking connections active while base uses them for a request
+func trackConnUsage(base http.RoundTripper) http.RoundTripper {
+	return &trackedTransport{base: base}
+}
+
+// trackedTransport traces which pooled connection each request gets
+type trackedTransport struct {
+	base http.RoundTripper
+}
+
+func (t *trackedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var conn *trackedConn
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if conn = trackedConnOf(info.Conn); conn != nil {
+				conn.active.Store(true)
+			}
+		},
+		PutIdleConn: func(error) {
+			if conn != nil {
+				conn.active.Store(false)
+			}
+		},
+	}
+	return t.base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+}
+
+// stats returns the open, active and idle connections per host, sorted by host
+func (t *connTracker) stats() []PoolHostStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := make([]PoolHostStats, 0, len(t.conns))
+	for host, conns := range t.conns {
+		hostStats := PoolHostStats{Host: host, Open: len(conns)}
+		for conn := range conns {
+			if conn.active.Load() {
+				hostStats.Active++
+			}
+		}
+		hostStats.Idle = hostStats.Open - hostStats.Active
+		stats = append(stats, hostStats)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Host < stats[j].Host })
+	return stats
+}
+
+// applyConnectionPool sets the configured pool limits on transport and tracks its connections
+func (p *ProxyHandler) applyConnectionPool(transport *http.Transport) {
+	pool := p.options.ConnectionPool
+	if pool.MaxIdleConns != 0 {
+		transport.MaxIdleConns = pool.MaxIdleConns
+	}
+	if pool.MaxIdleConnsPerHost != 0 {
+		transport.MaxIdleConnsPerHost = pool.MaxIdleConnsPerHost
+	}
+	if pool.MaxConnsPerHost != 0 {
+		transport.MaxConnsPerHost = pool.MaxConnsPerHost
+	}
+	if pool.IdleConnTimeout != 0 {
+		transport.IdleConnTimeout = pool.IdleConnTimeout
+	}
+	transport.DialContext = p.conns.wrapDialer(transport.DialContext)
+}
+
+// PoolStats reports the upstream connections currently open per host
+func (p *ProxyHandler) PoolStats() []PoolHostStats {
+	return p.conns.stats()
+}
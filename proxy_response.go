@@ -7,29 +7,41 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 // proxyRequestWithPathRewrite proxies a request and rewrites URLs in responses
-func (p *ProxyHandler) proxyRequestWithPathRewrite(c *gin.Context, targetURL, targetPath, pathPrefix string) {
+func (p *ProxyHandler) proxyRequestWithPathRewrite(c *gin.Context, serviceName, targetURL, targetPath, pathPrefix string) {
 	target, err := url.Parse(targetURL)
 	if err != nil {
 		p.logger.Error("Failed to parse target URL", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		writeError(c, err)
+		return
+	}
+
+	if !p.checkAllowedMethod(c, serviceName) {
+		return
+	}
+
+	if !p.checkHeaderSize(c) {
 		return
 	}
 
 	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.Transport = rejectionTransport{p.trackedTransportFor(serviceName)}
+	proxy.FlushInterval = p.flushInterval(serviceName)
 
-	// Modify the request - disable compression to allow body rewriting
+	// Modify the request - only accept compression the gateway can undo for body rewriting
 	originalDirector := proxy.Director
 	proxy.Director = func(req *http.Request) {
 		originalDirector(req)
@@ -37,8 +49,8 @@ func (p *ProxyHandler) proxyRequestWithPathRewrite(c *gin.Context, targetURL, ta
 		req.URL.RawQuery = c.Request.URL.RawQuery
 		req.Host = target.Host
 
-		// Disable compression so we can rewrite the body
-		req.Header.Del("Accept-Encoding")
+		// Only gzip can be decoded (and re-encoded) around body rewriting
+		req.Header.Set("Accept-Encoding", upstreamAcceptEncoding)
 
 		// Forward other headers
 		for key, values := range c.Request.Header {
@@ -47,19 +59,23 @@ func (p *ProxyHandler) proxyRequestWithPathRewrite(c *gin.Context, targetURL, ta
 			}
 			if len(values) > 0 {
 				req.Header.Set(key, values[0])
-				for _, value := range values[1:] {
+				for _, value := range p.limitHeaderValues(values)[1:] {
 					req.Header.Add(key, value)
 				}
 			}
 		}
 
-		req.Header.Set("X-Forwarded-For", c.ClientIP())
-		req.Header.Set("X-Forwarded-Proto", "http")
-		req.Header.Set("X-Real-IP", c.ClientIP())
+		setForwardedHeaders(c, req, p.options.ForwardedHeaders)
+		setForwardedPrefix(req, p.strippedPrefix(serviceName, c.Request.URL.Path))
+		p.overrideMethod(c, req)
+
+		p.transformRequest(c, serviceName, req)
 	}
 
-	// Rewrite Location headers and HTML body URLs
+	// Rewrite Location headers, HTML body URLs and configured JSON URLs
 	proxy.ModifyResponse = func(resp *http.Response) error {
+		p.recordUpstreamResult(serviceName, targetURL, resp.StatusCode >= http.StatusInternalServerError)
+
 		// Rewrite Location header
 		if location := resp.Header.Get("Location"); location != "" {
 			if strings.HasPrefix(location, "/") && !strings.HasPrefix(location, pathPrefix) {
@@ -67,14 +83,33 @@ func (p *ProxyHandler) proxyRequestWithPathRewrite(c *gin.Context, targetURL, ta
 			}
 		}
 
-		// Rewrite HTML body for text/html responses
 		contentType := resp.Header.Get("Content-Type")
-		if strings.Contains(contentType, "text/html") {
-			body, err := io.ReadAll(resp.Body)
-			resp.Body.Close()
+		jsonRewrite := p.options.Services[serviceName].JSONRewrite
+		rewriteHTML := strings.Contains(contentType, "text/html")
+		rewriteJSON := jsonRewrite != nil && isJSONContentType(contentType)
+
+		// Decode gzip when the body is rewritten or the client cannot take gzip
+		encoding := responseEncoding(resp)
+		if encoding == "gzip" && (rewriteHTML || rewriteJSON || !acceptsGzip(c.Request.Header)) {
+			if err := decompressResponse(resp); err != nil {
+				return err
+			}
+			encoding = ""
+		}
+		if encoding != "" {
+			// Encodings the gateway cannot decode are passed through unrewritten
+			return p.transformResponse(serviceName, resp)
+		}
+
+		// Rewrite HTML body for text/html responses (bodies over 1 MiB pass through)
+		if rewriteHTML {
+			body, ok, err := readResponseBody(resp)
 			if err != nil {
 				return err
 			}
+			if !ok {
+				return p.transformResponse(serviceName, resp)
+			}
 
 			// Rewrite common URL patterns in HTML
 			bodyStr := string(body)
@@ -90,17 +125,40 @@ func (p *ProxyHandler) proxyRequestWithPathRewrite(c *gin.Context, targetURL, ta
 			resp.Header.Set("Content-Length", fmt.Sprintf("%d", len(newBody)))
 		}
 
+		// Rewrite URLs inside JSON bodies when configured for the service
+		if rewriteJSON {
+			if err := rewriteJSONResponse(resp, *jsonRewrite, pathPrefix); err != nil {
+				return err
+			}
+		}
+
+		if err := p.transformResponse(serviceName, resp); err != nil {
+			return err
+		}
+		if (rewriteHTML || rewriteJSON) && p.options.Services[serviceName].CompressRewrites && acceptsGzip(c.Request.Header) {
+			return compressResponse(resp)
+		}
 		return nil
 	}
 
 	// Handle errors
+	start := time.Now()
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
-		p.logger.Error("Proxy error", zap.Error(err), zap.String("target", targetURL))
-		c.JSON(http.StatusBadGateway, gin.H{
-			"error":   "Service unavailable",
-			"details": err.Error(),
-		})
+		if handleClientCanceled(c, p.logger, r, err) {
+			return
+		}
+		var rejected *jsonBodyError
+		if errors.As(err, &rejected) {
+			sendJSONBodyError(c, rejected)
+			return
+		}
+		p.recordUpstreamResult(serviceName, targetURL, true)
+		p.logUpstreamError(c, r, serviceName, targetURL, start, err)
+		writeError(c, errUpstreamUnavailable(serviceName, err, p.options.ExposeErrorDetails))
 	}
 
+	c.Request = withUpstreamAttempts(c.Request)
+	observeSizes := p.measureBodySizes(c, serviceName)
 	proxy.ServeHTTP(c.Writer, c.Request)
+	observeSizes()
 }
@@ -0,0 +1,186 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains upstream retries and the global retry budget that keeps
+// retries from amplifying load on a struggling backend (retry storms).
+//
+// Associated Frontend Files:
+//   - None (gateway to backend connections only)
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// buildRetryTransports wraps the transport of every service with MaxRetries set
+func (p *ProxyHandler) buildRetryTransports() {
+	p.retryBudget = newRetryBudget(p.options.RetryBudgetPercent, p.options.RetryBudgetMinRetries, p.options.RetryBudgetWindow)
+	p.retryTransports = make(map[string]http.RoundTripper)
+	for name, service := range p.options.Services {
+		if service.MaxRetries <= 0 {
+			continue
+		}
+		p.retryTransports[name] = &retryTransport{
+			base:       p.trackedTransportFor(name),
+			maxRetries: service.MaxRetries,
+			backoff:    service.RetryBackoff,
+			budget:     p.retryBudget,
+			logger:     p.logger,
+			service:    name,
+		}
+	}
+}
+
+// roundTripperFor returns the round tripper used to reach serviceName, with retries if configured
+func (p *ProxyHandler) roundTripperFor(serviceName string) http.RoundTripper {
+	if rt, ok := p.retryTransports[serviceName]; ok {
+		return rt
+	}
+	return p.trackedTransportFor(serviceName)
+}
+
+// retryTransport retries idempotent requests without a body within the retry budget
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	backoff    time.Duration
+	budget     *retryBudget
+	logger     *zap.Logger
+	service    string
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.budget.recordRequest()
+	countUpstreamAttempt(req)
+	resp, err := t.base.RoundTrip(req)
+
+	for attempt := 1; attempt <= t.maxRetries && isRetryable(req, resp, err); attempt++ {
+		if !t.budget.tryRetry() {
+			t.logger.Warn("Retry budget exhausted, failing fast",
+				zap.String("service", t.service),
+				zap.String("path", req.URL.Path),
+			)
+			break
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if t.backoff > 0 {
+			select {
+			case <-time.After(t.backoff):
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+		}
+
+		t.logger.Debug("Retrying upstream request",
+			zap.String("service", t.service),
+			zap.Int("attempt", attempt),
+		)
+		countUpstreamAttempt(req)
+		resp, err = t.base.RoundTrip(req)
+	}
+
+	return resp, err
+}
+
+// isRetryable reports whether a failed attempt can be safely replayed
+func isRetryable(req *http.Request, resp *http.Response, err error) bool {
+	// Bodies are streamed and cannot be replayed
+	if req.Body != nil && req.Body != http.NoBody {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		return false
+	}
+
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryBudget allows retries while they stay under a percentage of recent requests.
+// Counts are kept in one-second buckets over a rolling window.
+type retryBudget struct {
+	mu         sync.Mutex
+	percent    float64
+	minRetries int
+	buckets    []retryBucket
+}
+
+type retryBucket struct {
+	second   int64
+	requests int
+	retries  int
+}
+
+// newRetryBudget creates a retry budget; percent <= 0 disables the cap
+func newRetryBudget(percent float64, minRetries int, window time.Duration) *retryBudget {
+	seconds := int(window / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return &retryBudget{
+		percent:    percent,
+		minRetries: minRetries,
+		buckets:    make([]retryBucket, seconds),
+	}
+}
+
+// bucket returns the bucket for the current second, resetting it if stale (caller holds mu)
+func (b *retryBudget) bucket() *retryBucket {
+	second := time.Now().Unix()
+	bucket := &b.buckets[int(second%int64(len(b.buckets)))]
+	if bucket.second != second {
+		*bucket = retryBucket{second: second}
+	}
+	return bucket
+}
+
+// totals sums the buckets inside the window (caller holds mu)
+func (b *retryBudget) totals() (requests, retries int) {
+	oldest := time.Now().Unix() - int64(len(b.buckets))
+	for _, bucket := range b.buckets {
+		if bucket.second > oldest {
+			requests += bucket.requests
+			retries += bucket.retries
+		}
+	}
+	return requests, retries
+}
+
+func (b *retryBudget) recordRequest() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bucket().requests++
+}
+
+// tryRetry reserves a retry if the budget allows it
+func (b *retryBudget) tryRetry() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.percent > 0 {
+		requests, retries := b.totals()
+		allowed := float64(requests) * b.percent / 100
+		if retries >= b.minRetries && float64(retries) >= allowed {
+			return false
+		}
+	}
+
+	b.bucket().retries++
+	return true
+}
@@ -0,0 +1,98 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugjb/api-gateway/config"
+	"github.com/ugjb/api-gateway/handlers"
+)
+
+const taskSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["title"],
+	"properties": {
+		"title": {"type": "string", "minLength": 1},
+		"priority": {"type": "integer", "minimum": 1, "maximum": 5}
+	}
+}`
+
+// TestProxyRouteSchemaValidation verifies configured routes validate bodies and replay them upstream
+func TestProxyRouteSchemaValidation(t *testing.T) {
+	schemaFile := filepath.Join(t.TempDir(), "task.json")
+	if err := os.WriteFile(schemaFile, []byte(taskSchema), 0o600); err != nil {
+		t.Fatalf("Failed to write schema: %v", err)
+	}
+
+	upstream := newEchoUpstream(t, "default")
+	cfg := &config.Config{}
+	cfg.ServiceURLs.TaskDispatcher = upstream.URL
+
+	opts := handlers.DefaultProxyOptions()
+	opts.RouteSchemas = map[string]string{"POST /api/v1/tasks": schemaFile}
+	proxyHandler := newTestProxyHandler(t, cfg, opts)
+
+	router := gin.New()
+	router.POST("/api/v1/tasks", proxyHandler.ProxyToService("task_dispatcher", "/tasks"))
+
+	post := func(body string) *closeNotifyRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := newProxyRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("valid payload", func(t *testing.T) {
+		payload := `{"title":"Write docs","priority":2}`
+		w := post(payload)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if echo := decodeEcho(t, w); echo.Body != payload {
+			t.Errorf("Expected body replayed upstream, got '%s'", echo.Body)
+		}
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		w := post(`{"priority":9}`)
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusUnprocessableEntity, w.Code, w.Body.String())
+		}
+
+		var body struct {
+			Error struct {
+				Code   string                     `json:"code"`
+				Errors []handlers.SchemaViolation `json:"errors"`
+			} `json:"error"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		if body.Error.Code != "SCHEMA_INVALID" {
+			t.Errorf("Expected error code SCHEMA_INVALID, got '%s'", body.Error.Code)
+		}
+		if len(body.Error.Errors) < 2 {
+			t.Errorf("Expected missing title and priority range violations, got %+v", body.Error.Errors)
+		}
+	})
+
+	t.Run("oversized payload", func(t *testing.T) {
+		w := post(`{"title":"` + strings.Repeat("a", 1<<20) + `"}`)
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("Expected status %d, got %d", http.StatusRequestEntityTooLarge, w.Code)
+		}
+	})
+
+	t.Run("invalid schema file", func(t *testing.T) {
+		opts := handlers.DefaultProxyOptions()
+		opts.RouteSchemas = map[string]string{"POST /api/v1/tasks": filepath.Join(t.TempDir(), "missing.json")}
+		if _, err := handlers.NewProxyHandlerWithOptions(cfg, nil, opts); err == nil {
+			t.Error("Expected error for missing schema file")
+		}
+	})
+}
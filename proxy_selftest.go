@@ -0,0 +1,122 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains the upstream self-test run at startup and from the admin API.
+//
+// Associated Frontend Files:
+//   - web/app/src/pages/AdminPage.tsx (upstream reachability)
+//
+// The check is a TCP connect to each configured service, Authelia and every
+// external service, run concurrently and bounded by SelfTestTimeout.
+package handlers
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ServiceCheckResult reports the reachability of one upstream
+type ServiceCheckResult struct {
+	Service   string `json:"service"`
+	URL       string `json:"url"`
+	Reachable bool   `json:"reachable"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// SelfTester checks upstream reachability (implemented by ProxyHandler)
+type SelfTester interface {
+	SelfTest(ctx context.Context) []ServiceCheckResult
+}
+
+// SelfTest connects to every configured upstream and returns per-service results
+// sorted by name. It returns within SelfTestTimeout even if upstreams hang.
+func (p *ProxyHandler) SelfTest(ctx context.Context) []ServiceCheckResult {
+	if p.options.SelfTestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.options.SelfTestTimeout)
+		defer cancel()
+	}
+
+	targets := p.selfTestTargets()
+	results := make([]ServiceCheckResult, 0, len(targets))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for service, rawURL := range targets {
+		wg.Add(1)
+		go func(service, rawURL string) {
+			defer wg.Done()
+			result := checkUpstream(ctx, service, rawURL)
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}(service, rawURL)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Service < results[j].Service })
+	return results
+}
+
+// selfTestTargets returns configured upstream URLs keyed by service name
+func (p *ProxyHandler) selfTestTargets() map[string]string {
+	targets := make(map[string]string)
+	cfg := p.current.Load()
+	if cfg.Authelia.InternalURL != "" {
+		targets["authelia"] = cfg.Authelia.InternalURL
+	}
+
+	services := reflect.ValueOf(cfg.ServiceURLs)
+	for i := 0; i < services.NumField(); i++ {
+		value := services.Field(i)
+		if value.Kind() == reflect.String && value.String() != "" {
+			targets[services.Type().Field(i).Name] = value.String()
+		}
+	}
+
+	if p.options.DefaultServiceURL != "" {
+		targets[DefaultServiceName] = p.options.DefaultServiceURL
+	}
+
+	for name, external := range p.options.ExternalServices {
+		if external.BaseURL != "" {
+			targets["external:"+name] = external.BaseURL
+		}
+	}
+	return targets
+}
+
+// checkUpstream opens (and closes) a TCP connection to the URL's host
+func checkUpstream(ctx context.Context, service, rawURL string) ServiceCheckResult {
+	result := ServiceCheckResult{Service: service, URL: redactURLCredentials(rawURL)}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		result.Error = "invalid URL"
+		return result
+	}
+	address := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		address = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	start := time.Now()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	result.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	conn.Close()
+	result.Reachable = true
+	return result
+}
@@ -0,0 +1,72 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains service lookup: the typed ServiceConfig of a service
+// with its base URL resolved.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (apiClient - all API calls proxied through gateway)
+//
+// URLs come from ServiceConfig.URL when set, else from the generated
+// config.ServiceURLs table (serviceURL), so existing deployments keep
+// working without per-service settings.
+package handlers
+
+import (
+	"fmt"
+)
+
+// LookupService returns the settings of serviceName with URL resolved. The
+// result is a copy; changing it does not affect the handler. ok is false when
+// the service has neither ProxyOptions.Services settings nor a configured URL.
+func (p *ProxyHandler) LookupService(serviceName string) (*ServiceConfig, bool) {
+	service, configured := p.options.Services[serviceName]
+	if service.URL == "" {
+		service.URL = serviceURL(p.current.Load(), serviceName)
+	}
+	if !configured && service.URL == "" {
+		return nil, false
+	}
+	return &service, true
+}
+
+// resolvedServiceURL returns the resolved base URL of serviceName ("" if not configured)
+func (p *ProxyHandler) resolvedServiceURL(serviceName string) string {
+	if service, ok := p.LookupService(serviceName); ok {
+		return service.URL
+	}
+	return ""
+}
+
+// validateServiceConfigs checks the URL overrides, canary URLs, outlier error
+// rates and upstream encodings of ProxyOptions.Services, and the API version
+// service URLs
+func (p *ProxyHandler) validateServiceConfigs() error {
+	for name, service := range p.options.Services {
+		if service.URL != "" {
+			if err := validateServiceURL(service.URL); err != nil {
+				return fmt.Errorf("service %s: %w", name, err)
+			}
+		}
+		if service.Canary != nil {
+			if err := validateServiceURL(service.Canary.URL); err != nil {
+				return fmt.Errorf("service %s canary: %w", name, err)
+			}
+		}
+		if detection := service.OutlierDetection; detection != nil && (detection.ErrorRate < 0 || detection.ErrorRate > 1) {
+			return fmt.Errorf("service %s: outlier error rate %v outside (0, 1]", name, detection.ErrorRate)
+		}
+		switch service.AcceptEncoding {
+		case "", UpstreamEncodingIdentity, UpstreamEncodingGzip:
+		default:
+			return fmt.Errorf("service %s: unknown accept encoding %q (expected identity or gzip)", name, service.AcceptEncoding)
+		}
+	}
+	for version, versionConfig := range p.options.APIVersions {
+		for name, serviceURL := range versionConfig.ServiceURLs {
+			if err := validateServiceURL(serviceURL); err != nil {
+				return fmt.Errorf("API version %s service %s: %w", version, name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -2,7 +2,7 @@
 // Generated at: 2026-01-06 00:25:16
 // Source: services.json
 //
-// getServiceURL switch cases for all services
+// serviceURL switch cases for all services
 //
 // To regenerate, run:
 //   python3 agent/src/fuel2/utils/generate_api_gateway_routes.py
@@ -14,89 +14,91 @@
 
 package handlers
 
-// getServiceURL returns the URL for a given microservice name
+import "github.com/ugjb/api-gateway/config"
+
+// serviceURL returns the URL for a given microservice name in cfg
 // This function is auto-generated from services.json
 // Note: Only microservices have URLs - bounded contexts are logical groupings, not deployable services
-func (p *ProxyHandler) getServiceURL(serviceName string) string {
+func serviceURL(cfg *config.Config, serviceName string) string {
 	switch serviceName {
 	// Infrastructure Services
 	case "frontend":
-		return p.config.ServiceURLs.Frontend
+		return cfg.ServiceURLs.Frontend
 	case "ollama":
-		return p.config.ServiceURLs.Ollama
+		return cfg.ServiceURLs.Ollama
 	case "docker_registry":
-		return p.config.ServiceURLs.DockerRegistry
+		return cfg.ServiceURLs.DockerRegistry
 	case "bugsink":
-		return p.config.ServiceURLs.Bugsink
+		return cfg.ServiceURLs.Bugsink
 
 
 	// Engineering-Analytics Bounded Context
 
 	case "metrics_collector":
-		return p.config.ServiceURLs.MetricsCollector
+		return cfg.ServiceURLs.MetricsCollector
 
 	case "k_p_i_engine":
-		return p.config.ServiceURLs.KPIEngine
+		return cfg.ServiceURLs.KPIEngine
 
 	case "insights_dashboard":
-		return p.config.ServiceURLs.InsightsDashboard
+		return cfg.ServiceURLs.InsightsDashboard
 
 
 
 	// Goal-Management Bounded Context
 
 	case "objective_service":
-		return p.config.ServiceURLs.ObjectiveService
+		return cfg.ServiceURLs.ObjectiveService
 
 	case "key_result_tracker":
-		return p.config.ServiceURLs.KeyResultTracker
+		return cfg.ServiceURLs.KeyResultTracker
 
 
 
 	// HR-Management Bounded Context
 
 	case "employee_registry":
-		return p.config.ServiceURLs.EmployeeRegistry
+		return cfg.ServiceURLs.EmployeeRegistry
 
 	case "allocation_engine":
-		return p.config.ServiceURLs.AllocationEngine
+		return cfg.ServiceURLs.AllocationEngine
 
 
 
 	// Project-Management Bounded Context
 
 	case "sprint_coordinator":
-		return p.config.ServiceURLs.SprintCoordinator
+		return cfg.ServiceURLs.SprintCoordinator
 
 	case "task_dispatcher":
-		return p.config.ServiceURLs.TaskDispatcher
+		return cfg.ServiceURLs.TaskDispatcher
 
 
 
 	// System-Integration Bounded Context
 
 	case "data_pipeline":
-		return p.config.ServiceURLs.DataPipeline
+		return cfg.ServiceURLs.DataPipeline
 
 	case "api_gateway":
-		return p.config.ServiceURLs.ApiGateway
+		return cfg.ServiceURLs.ApiGateway
 
 
 
 	// User-Preferences Bounded Context
 
 	case "preferences_service":
-		return p.config.ServiceURLs.PreferencesService
+		return cfg.ServiceURLs.PreferencesService
 
 
 
 	// Workforce-Wellbeing Bounded Context
 
 	case "wellbeing_monitor":
-		return p.config.ServiceURLs.WellbeingMonitor
+		return cfg.ServiceURLs.WellbeingMonitor
 
 	case "burnout_predictor":
-		return p.config.ServiceURLs.BurnoutPredictor
+		return cfg.ServiceURLs.BurnoutPredictor
 
 
 
@@ -0,0 +1,191 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains request mirroring (traffic shadowing) to a shadow
+// upstream, used to try a new backend on live traffic.
+//
+// Associated Frontend Files:
+//   - None (shadow responses never reach clients)
+//
+// For routes in ProxyOptions.ShadowRoutes, a sample of requests is buffered
+// and, once the primary response has been sent, replayed asynchronously
+// against the shadow service. Its response is discarded; a status differing
+// from the primary one is logged and counted in gateway_shadow_requests_total.
+// At most ShadowMaxInFlight mirrored requests run at once; samples beyond that
+// are dropped (result "dropped") so a slow shadow cannot pile up goroutines.
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// HeaderShadowRequest marks mirrored requests so shadow backends can skip side effects
+const HeaderShadowRequest = "X-Shadow-Request"
+
+// DefaultShadowTimeout bounds mirrored requests without a ShadowConfig.Timeout
+const DefaultShadowTimeout = 10 * time.Second
+
+// maxShadowBodyBytes caps buffered request bodies; larger requests are not mirrored (1 MiB)
+const maxShadowBodyBytes = 1 << 20
+
+// Shadow request results recorded in gateway_shadow_requests_total
+const (
+	shadowResultMatch    = "match"
+	shadowResultMismatch = "mismatch"
+	shadowResultError    = "error"
+	shadowResultDropped  = "dropped"
+)
+
+// shadowSampler selects requests so exactly SampleRate of them are mirrored
+// over time, spread evenly rather than at random
+type shadowSampler struct {
+	rate  float64
+	count atomic.Uint64
+}
+
+func (s *shadowSampler) sample() bool {
+	n := s.count.Add(1)
+	return uint64(float64(n)*s.rate) > uint64(float64(n-1)*s.rate)
+}
+
+// buildShadowSamplers creates a sampler per shadowed route and the in-flight limit
+func (p *ProxyHandler) buildShadowSamplers() {
+	p.shadowSlots = make(chan struct{}, p.options.ShadowMaxInFlight)
+	p.shadowSamplers = make(map[string]*shadowSampler)
+	for route, shadow := range p.options.ShadowRoutes {
+		if shadow.Service == "" || shadow.SampleRate <= 0 {
+			continue
+		}
+		p.shadowSamplers[route] = &shadowSampler{rate: min(shadow.SampleRate, 1)}
+	}
+}
+
+// prepareShadow buffers the request body and builds the mirrored request when
+// the route is shadowed and this request is sampled. It returns nil when
+// nothing is mirrored; otherwise the returned func sends the copy in the
+// background and compares its status with primaryStatus.
+func (p *ProxyHandler) prepareShadow(c *gin.Context, targetPath string) func(primaryStatus int) {
+	route := c.Request.Method + " " + c.FullPath()
+	sampler, ok := p.shadowSamplers[route]
+	if !ok || c.GetHeader("Upgrade") != "" || !sampler.sample() {
+		return nil
+	}
+	shadow := p.options.ShadowRoutes[route]
+	fields := logFields(c, zap.String("route", route), zap.String("shadow_service", shadow.Service))
+
+	shadowURL, _ := p.getServiceURLForHost(shadow.Service, c.Request.Host)
+	target, err := url.Parse(shadowURL)
+	if shadowURL == "" || err != nil {
+		p.logger.Warn("Shadow service not configured", fields...)
+		return nil
+	}
+	target.Path, target.RawPath = expandPathParams(targetPath, c.Params)
+	target.RawQuery = c.Request.URL.RawQuery
+
+	body, ok := bufferShadowBody(c)
+	if !ok {
+		p.logger.Debug("Request body too large to mirror", fields...)
+		return nil
+	}
+
+	req, err := http.NewRequest(c.Request.Method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		p.logger.Warn("Failed to create shadow request", append(fields, zap.Error(err))...)
+		return nil
+	}
+	req.Header = c.Request.Header.Clone()
+	setForwardedHeaders(c, req, p.options.ForwardedHeaders)
+	req.Header.Del("X-Tenant-ID")
+	if tenantID := c.GetString(tenantIDKey); tenantID != "" {
+		req.Header.Set("X-Tenant-ID", tenantID)
+	}
+	if userID := c.GetString("user_id"); userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+	if email := c.GetString("email"); email != "" {
+		req.Header.Set("X-User-Email", email)
+	}
+	req.Header.Set(HeaderShadowRequest, "true")
+	if err := p.transformRequest(c, shadow.Service, req); err != nil {
+		// The primary request is rejected the same way; nothing to compare
+		return nil
+	}
+
+	timeout := shadow.Timeout
+	if timeout <= 0 {
+		timeout = DefaultShadowTimeout
+	}
+	transport := p.trackedTransportFor(shadow.Service)
+
+	return func(primaryStatus int) {
+		select {
+		case p.shadowSlots <- struct{}{}:
+		default:
+			p.logger.Debug("Too many shadow requests in flight, dropping", fields...)
+			p.options.Metrics.shadowRequest(shadow.Service, shadowResultDropped)
+			return
+		}
+		go func() {
+			defer func() { <-p.shadowSlots }()
+			p.sendShadow(transport, req, shadow.Service, timeout, primaryStatus, fields)
+		}()
+	}
+}
+
+// bufferShadowBody reads the request body so it can be sent twice, restoring
+// it for the primary upstream. Returns false if the body exceeds maxShadowBodyBytes.
+func bufferShadowBody(c *gin.Context) ([]byte, bool) {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil, true
+	}
+
+	original := c.Request.Body
+	body, err := io.ReadAll(io.LimitReader(original, maxShadowBodyBytes+1))
+	if err != nil || len(body) > maxShadowBodyBytes {
+		// Hand the primary upstream what was read followed by the rest
+		c.Request.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), original), original}
+		return nil, false
+	}
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{bytes.NewReader(body), original}
+	return body, true
+}
+
+// sendShadow sends a mirrored request, discards the response and records
+// whether its status matched the primary one
+func (p *ProxyHandler) sendShadow(transport http.RoundTripper, req *http.Request, serviceName string, timeout time.Duration, primaryStatus int, fields []zap.Field) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	resp, err := transport.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		p.logger.Warn("Shadow request failed", append(fields, zap.Error(err))...)
+		p.options.Metrics.shadowRequest(serviceName, shadowResultError)
+		return
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxShadowBodyBytes))
+	resp.Body.Close()
+
+	if resp.StatusCode != primaryStatus {
+		p.logger.Warn("Shadow response status differs from primary", append(fields,
+			zap.Int("primary_status", primaryStatus),
+			zap.Int("shadow_status", resp.StatusCode),
+		)...)
+		p.options.Metrics.shadowRequest(serviceName, shadowResultMismatch)
+		return
+	}
+	p.options.Metrics.shadowRequest(serviceName, shadowResultMatch)
+}
@@ -0,0 +1,69 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains host-based (multi-tenant) service resolution.
+//
+// Associated Frontend Files:
+//   - None (tenants are selected by the hostname the frontend is served from)
+package handlers
+
+import (
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tenantIDKey is the gin context key holding the resolved tenant ID
+const tenantIDKey = "tenant_id"
+
+// getServiceURLForHost returns the service URL for the tenant owning host,
+// falling back to the default (non-tenant) configuration.
+// The second return value is the tenant ID, empty when no tenant matched.
+func (p *ProxyHandler) getServiceURLForHost(serviceName, host string) (string, string) {
+	if tenant, ok := p.tenantForHost(host); ok {
+		if serviceURL := tenant.ServiceURLs[serviceName]; serviceURL != "" {
+			return serviceURL, tenant.ID
+		}
+		return p.defaultServiceURL(serviceName), tenant.ID
+	}
+
+	return p.defaultServiceURL(serviceName), ""
+}
+
+// defaultServiceURL returns the next load-balanced instance of serviceName if it
+// has Instances, else its configured URL
+func (p *ProxyHandler) defaultServiceURL(serviceName string) string {
+	if lb, ok := p.balancers[serviceName]; ok {
+		return lb.pick()
+	}
+	return p.resolvedServiceURL(serviceName)
+}
+
+// tenantForHost returns the tenant owning host (which may include a port)
+func (p *ProxyHandler) tenantForHost(host string) (TenantConfig, bool) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	tenant, ok := p.options.Tenants[strings.ToLower(host)]
+	return tenant, ok
+}
+
+// resolveServiceURL resolves the service URL for the request's host and records the tenant in the context.
+// Requests not pinned to a tenant URL use their API version's URL for the
+// service, if any, and may otherwise be routed to the service's canary.
+func (p *ProxyHandler) resolveServiceURL(c *gin.Context, serviceName string) string {
+	tenant, ok := p.tenantForHost(c.Request.Host)
+	if ok && tenant.ID != "" {
+		c.Set(tenantIDKey, tenant.ID)
+	}
+	if !ok || tenant.ServiceURLs[serviceName] == "" {
+		if versionURL := p.versionServiceURL(c, serviceName); versionURL != "" {
+			return versionURL
+		}
+		if canaryURL, ok := p.routeVariant(c, serviceName); ok {
+			return canaryURL
+		}
+	}
+	serviceURL, _ := p.getServiceURLForHost(serviceName, c.Request.Host)
+	return serviceURL
+}
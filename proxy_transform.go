@@ -0,0 +1,358 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains registerable transformation hooks for proxied traffic,
+// so service-specific tweaks do not need new handler files.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (apiClient - receives transformed responses)
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestTransformer rewrites the outgoing upstream request (path, query,
+// headers or body) in the proxy Director. c is the incoming gateway request,
+// whose route params and context values (e.g. user_id) may be used.
+type RequestTransformer func(c *gin.Context, req *http.Request)
+
+// ResponseTransformer mutates a proxied response (headers and/or body) in
+// ModifyResponse. Returning an error fails the request with 502.
+type ResponseTransformer func(resp *http.Response) error
+
+// transformResponse runs the global, then the service-specific response transformers in order
+func (p *ProxyHandler) transformResponse(serviceName string, resp *http.Response) error {
+	for _, transformers := range [][]ResponseTransformer{
+		p.options.ResponseTransformers,
+		p.options.Services[serviceName].ResponseTransformers,
+	} {
+		for _, transform := range transformers {
+			if err := transform(resp); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// transformRequest runs the global, then the service-specific request
+// transformers in order. It returns the error a transformer rejected the
+// request with, in which case req must not be sent upstream.
+func (p *ProxyHandler) transformRequest(c *gin.Context, serviceName string, req *http.Request) error {
+	for _, transformers := range [][]RequestTransformer{
+		p.options.RequestTransformers,
+		p.options.Services[serviceName].RequestTransformers,
+	} {
+		for _, transform := range transformers {
+			transform(c, req)
+		}
+	}
+	return requestRejection(req)
+}
+
+// rejectedBody replaces the body of a request a RequestTransformer rejected.
+// Transformers run in the proxy Director, where no response can be written
+// yet, so the rejection travels with the request until rejectionTransport.
+type rejectedBody struct {
+	err error
+}
+
+func (b rejectedBody) Read([]byte) (int, error) { return 0, b.err }
+func (b rejectedBody) Close() error             { return nil }
+
+// rejectRequest marks req so it fails with err instead of reaching the upstream
+func rejectRequest(req *http.Request, err error) {
+	req.Body = rejectedBody{err: err}
+	req.GetBody = nil
+}
+
+// requestRejection returns the error req was rejected with, or nil
+func requestRejection(req *http.Request) error {
+	if body, ok := req.Body.(rejectedBody); ok {
+		return body.err
+	}
+	return nil
+}
+
+// rejectionTransport fails requests rejected by a RequestTransformer without
+// sending them; the proxy ErrorHandler answers with the rejection error
+type rejectionTransport struct {
+	http.RoundTripper
+}
+
+func (t rejectionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := requestRejection(req); err != nil {
+		return nil, err
+	}
+	return t.RoundTripper.RoundTrip(req)
+}
+
+// SetRequestHeader returns a transformer setting a header on the upstream request
+func SetRequestHeader(name, value string) RequestTransformer {
+	return func(c *gin.Context, req *http.Request) {
+		req.Header.Set(name, value)
+	}
+}
+
+// InjectQueryParam returns a transformer setting a query parameter on the upstream request
+func InjectQueryParam(name, value string) RequestTransformer {
+	return func(c *gin.Context, req *http.Request) {
+		query := req.URL.Query()
+		query.Set(name, value)
+		req.URL.RawQuery = query.Encode()
+	}
+}
+
+// PathTemplate returns a transformer replacing the upstream path with template,
+// where each {name} is filled from the route param, or else the context value,
+// of that name (e.g. "/v2/users/{user_id}/tasks/{id}").
+func PathTemplate(template string) RequestTransformer {
+	return func(c *gin.Context, req *http.Request) {
+		var path strings.Builder
+		rest := template
+		for {
+			start := strings.Index(rest, "{")
+			end := strings.Index(rest, "}")
+			if start < 0 || end < start {
+				path.WriteString(rest)
+				break
+			}
+			name := rest[start+1 : end]
+			value := c.Param(name)
+			if value == "" {
+				value = c.GetString(name)
+			}
+			path.WriteString(rest[:start])
+			path.WriteString(value)
+			rest = rest[end+1:]
+		}
+		req.URL.Path = path.String()
+		req.URL.RawPath = ""
+	}
+}
+
+// SetRequestBody replaces the upstream request body, keeping it replayable
+// (for retries and redirects) and recomputing Content-Length
+func SetRequestBody(req *http.Request, body []byte) {
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+}
+
+// InjectAuthBodyFields returns a transformer adding auth context values to JSON
+// object request bodies, for backends that expect the caller in the body rather
+// than in headers. fields maps body field to context key (e.g.
+// {"_gateway_user_id": "user_id", "_gateway_roles": "roles"}); client-sent
+// values of these fields, in any letter case, are always removed, and unset
+// context values are left out. When routes ("METHOD /route" keys, as in
+// RouteSchemas) are given, only those routes are transformed. Bodies that
+// cannot be stripped are rejected rather than forwarded: 415 for compressed
+// or non-JSON bodies, 413 over 1 MiB and 400 for invalid JSON. Valid JSON
+// that is not an object is forwarded unchanged.
+func InjectAuthBodyFields(fields map[string]string, routes ...string) RequestTransformer {
+	routeSet := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		routeSet[route] = true
+	}
+
+	return func(c *gin.Context, req *http.Request) {
+		if len(routeSet) > 0 && !routeSet[c.Request.Method+" "+c.FullPath()] {
+			return
+		}
+		if req.Body == nil || req.Body == http.NoBody {
+			return
+		}
+		if !isJSONContentType(req.Header.Get("Content-Type")) || req.Header.Get("Content-Encoding") != "" {
+			rejectRequest(req, &jsonBodyError{
+				status:  http.StatusUnsupportedMediaType,
+				code:    "UNSUPPORTED_MEDIA_TYPE",
+				message: "Request body must be uncompressed application/json",
+			})
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(req.Body, defaultMaxJSONBodyBytes+1))
+		if err == nil && len(body) > defaultMaxJSONBodyBytes {
+			err = &http.MaxBytesError{Limit: defaultMaxJSONBodyBytes}
+		}
+		if err != nil {
+			rejectRequest(req, classifyJSONError(err, defaultMaxJSONBodyBytes))
+			return
+		}
+		req.Body.Close()
+
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var doc interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			rejectRequest(req, classifyJSONError(err, defaultMaxJSONBodyBytes))
+			return
+		}
+		object, ok := doc.(map[string]interface{})
+		if !ok {
+			SetRequestBody(req, body)
+			return
+		}
+
+		// Client-supplied values are never forwarded, even when the context has none
+		for name := range object {
+			for field := range fields {
+				if strings.EqualFold(name, field) {
+					delete(object, name)
+				}
+			}
+		}
+		for field, key := range fields {
+			if value, ok := c.Get(key); ok {
+				object[field] = value
+			}
+		}
+		encoded, err := json.Marshal(object)
+		if err != nil {
+			rejectRequest(req, &jsonBodyError{
+				status:  http.StatusInternalServerError,
+				code:    "INTERNAL_ERROR",
+				message: "Internal server error",
+				err:     err,
+			})
+			return
+		}
+		SetRequestBody(req, encoded)
+	}
+}
+
+// AddResponseHeader returns a transformer adding a header value to the response
+func AddResponseHeader(name, value string) ResponseTransformer {
+	return func(resp *http.Response) error {
+		resp.Header.Add(name, value)
+		return nil
+	}
+}
+
+// RemoveResponseHeader returns a transformer deleting the named response headers
+func RemoveResponseHeader(names ...string) ResponseTransformer {
+	return func(resp *http.Response) error {
+		for _, name := range names {
+			resp.Header.Del(name)
+		}
+		return nil
+	}
+}
+
+// InjectJSONField returns a transformer setting a top-level field in JSON object
+// responses (e.g. {"id":1} -> {"id":1,"served_by":"gateway"}). Compressed bodies,
+// bodies over 1 MiB, non-object documents and invalid JSON are passed through unchanged.
+func InjectJSONField(field string, value interface{}) ResponseTransformer {
+	return func(resp *http.Response) error {
+		if !isJSONContentType(resp.Header.Get("Content-Type")) || resp.Header.Get("Content-Encoding") != "" {
+			return nil
+		}
+
+		body, ok, err := readResponseBody(resp)
+		if err != nil || !ok {
+			return err
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err == nil && doc != nil {
+			doc[field] = value
+			if encoded, err := json.Marshal(doc); err == nil {
+				body = encoded
+			}
+		}
+
+		setResponseBody(resp, body)
+		return nil
+	}
+}
+
+// ErrorEnvelopeMapping locates a backend's error code and message in its JSON
+// error bodies. Fields are dot-separated object keys (e.g. "error.detail").
+type ErrorEnvelopeMapping struct {
+	// CodeField holds the error code; when empty or missing the code is derived
+	// from the status (e.g. 404 -> "NOT_FOUND")
+	CodeField string
+	// MessageField holds the human-readable message; when empty or missing the
+	// status text is used
+	MessageField string
+}
+
+// NormalizeErrorEnvelope returns a transformer rewriting 4xx/5xx JSON responses
+// into the gateway envelope {"error":{"code","message"}} using mapping, so the
+// frontend sees one error shape for every backend. Other fields are dropped.
+// Success responses, compressed bodies, bodies over 1 MiB and invalid JSON are
+// passed through unchanged.
+func NormalizeErrorEnvelope(mapping ErrorEnvelopeMapping) ResponseTransformer {
+	return func(resp *http.Response) error {
+		if resp.StatusCode < http.StatusBadRequest ||
+			!isJSONContentType(resp.Header.Get("Content-Type")) || resp.Header.Get("Content-Encoding") != "" {
+			return nil
+		}
+
+		body, ok, err := readResponseBody(resp)
+		if err != nil || !ok {
+			return err
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err == nil && doc != nil {
+			code := jsonFieldString(doc, mapping.CodeField)
+			if code == "" {
+				code = strings.ToUpper(strings.ReplaceAll(http.StatusText(resp.StatusCode), " ", "_"))
+			}
+			message := jsonFieldString(doc, mapping.MessageField)
+			if message == "" {
+				message = http.StatusText(resp.StatusCode)
+			}
+			if encoded, err := json.Marshal(gin.H{"error": gin.H{"code": code, "message": message}}); err == nil {
+				body = encoded
+			}
+		}
+
+		setResponseBody(resp, body)
+		return nil
+	}
+}
+
+// jsonFieldString returns the string or number at the dot-separated field of doc ("" if absent)
+func jsonFieldString(doc map[string]interface{}, field string) string {
+	if field == "" {
+		return ""
+	}
+	var value interface{} = doc
+	for _, key := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		value = object[key]
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	return ""
+}
+
+// setResponseBody replaces the response body and recomputes Content-Length
+func setResponseBody(resp *http.Response, body []byte) {
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+}
@@ -0,0 +1,359 @@
+package handlers_test
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugjb/api-gateway/config"
+	"github.com/ugjb/api-gateway/handlers"
+)
+
+// TestProxyResponseTransformers verifies chained transformers apply in order and Content-Length follows body edits
+func TestProxyResponseTransformers(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Internal-Trace", "abc")
+		w.Write([]byte(`{"id":1}`))
+	}))
+	t.Cleanup(upstream.Close)
+
+	var order []string
+	record := func(name string) handlers.ResponseTransformer {
+		return func(resp *http.Response) error {
+			order = append(order, name+":"+resp.Header.Get("X-Gateway-Tag"))
+			return nil
+		}
+	}
+
+	cfg := &config.Config{}
+	cfg.ServiceURLs.TaskDispatcher = upstream.URL
+	opts := handlers.DefaultProxyOptions()
+	opts.ResponseTransformers = []handlers.ResponseTransformer{
+		handlers.AddResponseHeader("X-Gateway-Tag", "global"),
+		record("global"),
+	}
+	opts.Services["task_dispatcher"] = handlers.ServiceConfig{
+		ResponseTransformers: []handlers.ResponseTransformer{
+			handlers.RemoveResponseHeader("X-Internal-Trace", "X-Gateway-Tag"),
+			handlers.InjectJSONField("source", "gateway"),
+			record("service"),
+		},
+	}
+	proxyHandler := newTestProxyHandler(t, cfg, opts)
+
+	router := gin.New()
+	router.GET("/api/v1/tasks/:id", proxyHandler.ProxyToService("task_dispatcher", "/tasks/:id"))
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks/1", nil)
+	w := newProxyRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if len(order) != 2 || order[0] != "global:global" || order[1] != "service:" {
+		t.Errorf("Expected global transformers before service ones, got %v", order)
+	}
+	if got := w.Header().Get("X-Internal-Trace"); got != "" {
+		t.Errorf("Expected X-Internal-Trace removed, got '%s'", got)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Expected JSON body, got error: %v", err)
+	}
+	if body["id"] != float64(1) || body["source"] != "gateway" {
+		t.Errorf("Expected injected field next to original ones, got %v", body)
+	}
+	if got := w.Header().Get("Content-Length"); got != strconv.Itoa(w.Body.Len()) {
+		t.Errorf("Expected Content-Length %d, got '%s'", w.Body.Len(), got)
+	}
+}
+
+// TestProxyResponseTransformersSizeCap verifies a gzipped body inflating past 1 MiB is decoded as a stream and passed through unrewritten
+func TestProxyResponseTransformersSizeCap(t *testing.T) {
+	original := `{"data":"` + strings.Repeat("a", 2<<20) + `"}`
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(original))
+		gz.Close()
+	}))
+	t.Cleanup(upstream.Close)
+
+	cfg := &config.Config{}
+	cfg.ServiceURLs.TaskDispatcher = upstream.URL
+	opts := handlers.DefaultProxyOptions()
+	opts.Services["task_dispatcher"] = handlers.ServiceConfig{
+		AcceptEncoding:       handlers.UpstreamEncodingGzip,
+		ResponseTransformers: []handlers.ResponseTransformer{handlers.InjectJSONField("source", "gateway")},
+	}
+	proxyHandler := newTestProxyHandler(t, cfg, opts)
+
+	router := gin.New()
+	router.GET("/api/v1/export", proxyHandler.ProxyToService("task_dispatcher", "/export"))
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/export", nil)
+	req.Header.Set("Accept-Encoding", "identity")
+	w := newProxyRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if got := w.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("Expected decoded body, got Content-Encoding '%s'", got)
+	}
+	if w.Body.String() != original {
+		t.Errorf("Expected the %d byte body passed through unmodified, got %d bytes", len(original), w.Body.Len())
+	}
+}
+
+// TestProxyRequestTransformers verifies injected query params, headers, paths and bodies reach the upstream
+func TestProxyRequestTransformers(t *testing.T) {
+	var received *http.Request
+	var receivedBody string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ := io.ReadAll(r.Body)
+		receivedBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(upstream.Close)
+
+	cfg := &config.Config{}
+	cfg.ServiceURLs.TaskDispatcher = upstream.URL
+	opts := handlers.DefaultProxyOptions()
+	opts.RequestTransformers = []handlers.RequestTransformer{
+		handlers.SetRequestHeader("X-Gateway-Route", "tasks"),
+	}
+	opts.Services["task_dispatcher"] = handlers.ServiceConfig{
+		RequestTransformers: []handlers.RequestTransformer{
+			handlers.InjectQueryParam("tenant", "acme"),
+			handlers.PathTemplate("/v2/users/{user_id}/tasks/{id}"),
+			func(c *gin.Context, req *http.Request) {
+				handlers.SetRequestBody(req, []byte(`{"wrapped":true}`))
+			},
+		},
+	}
+	proxyHandler := newTestProxyHandler(t, cfg, opts)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", "jane") })
+	router.POST("/api/v1/tasks/:id", proxyHandler.ProxyToService("task_dispatcher", "/tasks/:id"))
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tasks/42?page=2", strings.NewReader(`{"original":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := newProxyRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if got := received.URL.Path; got != "/v2/users/jane/tasks/42" {
+		t.Errorf("Expected templated path, got '%s'", got)
+	}
+	if got := received.URL.Query(); got.Get("tenant") != "acme" || got.Get("page") != "2" {
+		t.Errorf("Expected injected and original query params, got %v", got)
+	}
+	if got := received.Header.Get("X-Gateway-Route"); got != "tasks" {
+		t.Errorf("Expected injected header, got '%s'", got)
+	}
+	if receivedBody != `{"wrapped":true}` || received.ContentLength != int64(len(receivedBody)) {
+		t.Errorf("Expected replaced body with matching length, got %q (length %d)", receivedBody, received.ContentLength)
+	}
+}
+
+// TestProxyNormalizeErrorEnvelope verifies backend error shapes are mapped into the gateway envelope
+func TestProxyNormalizeErrorEnvelope(t *testing.T) {
+	tests := []struct {
+		name          string
+		mapping       handlers.ErrorEnvelopeMapping
+		status        int
+		body          string
+		expectCode    string
+		expectMessage string
+	}{
+		{"flat shape", handlers.ErrorEnvelopeMapping{CodeField: "error_code", MessageField: "message"},
+			http.StatusConflict, `{"error_code":"TASK_EXISTS","message":"Task already exists","trace":"x"}`,
+			"TASK_EXISTS", "Task already exists"},
+		{"nested shape with numeric code", handlers.ErrorEnvelopeMapping{CodeField: "error.status", MessageField: "error.detail"},
+			http.StatusBadGateway, `{"error":{"status":5021,"detail":"Model backend down"}}`,
+			"5021", "Model backend down"},
+		{"missing fields fall back to status", handlers.ErrorEnvelopeMapping{CodeField: "code", MessageField: "message"},
+			http.StatusNotFound, `{"detail":"no such task"}`,
+			"NOT_FOUND", "Not Found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(upstream.Close)
+
+			cfg := &config.Config{}
+			cfg.ServiceURLs.TaskDispatcher = upstream.URL
+			opts := handlers.DefaultProxyOptions()
+			opts.Services["task_dispatcher"] = handlers.ServiceConfig{
+				ResponseTransformers: []handlers.ResponseTransformer{handlers.NormalizeErrorEnvelope(tt.mapping)},
+			}
+			proxyHandler := newTestProxyHandler(t, cfg, opts)
+			router := gin.New()
+			router.GET("/api/v1/tasks", proxyHandler.ProxyToService("task_dispatcher", "/tasks"))
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+			w := newProxyRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("Expected status %d preserved, got %d", tt.status, w.Code)
+			}
+			var body map[string]map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Expected JSON body, got error: %v", err)
+			}
+			if len(body) != 1 || body["error"]["code"] != tt.expectCode || body["error"]["message"] != tt.expectMessage {
+				t.Errorf("Expected envelope {code:%s message:%s}, got %s", tt.expectCode, tt.expectMessage, w.Body.String())
+			}
+			if got := w.Header().Get("Content-Length"); got != strconv.Itoa(w.Body.Len()) {
+				t.Errorf("Expected Content-Length %d, got '%s'", w.Body.Len(), got)
+			}
+		})
+	}
+
+	t.Run("success untouched", func(t *testing.T) {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"message":"ok"}`))
+		}))
+		t.Cleanup(upstream.Close)
+
+		cfg := &config.Config{}
+		cfg.ServiceURLs.TaskDispatcher = upstream.URL
+		opts := handlers.DefaultProxyOptions()
+		opts.Services["task_dispatcher"] = handlers.ServiceConfig{
+			ResponseTransformers: []handlers.ResponseTransformer{
+				handlers.NormalizeErrorEnvelope(handlers.ErrorEnvelopeMapping{MessageField: "message"}),
+			},
+		}
+		proxyHandler := newTestProxyHandler(t, cfg, opts)
+		router := gin.New()
+		router.GET("/api/v1/tasks", proxyHandler.ProxyToService("task_dispatcher", "/tasks"))
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+		w := newProxyRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Body.String() != `{"message":"ok"}` {
+			t.Errorf("Expected success body untouched, got %s", w.Body.String())
+		}
+	})
+}
+
+// TestProxyInjectAuthBodyFields verifies auth context values are injected into object bodies of configured routes only
+func TestProxyInjectAuthBodyFields(t *testing.T) {
+	upstream := newEchoUpstream(t, "tasks")
+
+	cfg := &config.Config{}
+	cfg.ServiceURLs.TaskDispatcher = upstream.URL
+	opts := handlers.DefaultProxyOptions()
+	opts.Services["task_dispatcher"] = handlers.ServiceConfig{
+		RequestTransformers: []handlers.RequestTransformer{
+			handlers.InjectAuthBodyFields(map[string]string{
+				"_gateway_user_id": "user_id",
+				"_gateway_roles":   "roles",
+				"_gateway_email":   "email",
+			}, "POST /api/v1/tasks"),
+		},
+	}
+	proxyHandler := newTestProxyHandler(t, cfg, opts)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "jane")
+		c.Set("roles", []string{"user", "admin"})
+	})
+	router.POST("/api/v1/tasks", proxyHandler.ProxyToService("task_dispatcher", "/tasks"))
+	router.POST("/api/v1/tasks/import", proxyHandler.ProxyToService("task_dispatcher", "/tasks/import"))
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		expectBody string
+	}{
+		{"object body", "/api/v1/tasks", `{"title":"Write docs","priority":2}`,
+			`{"_gateway_roles":["user","admin"],"_gateway_user_id":"jane","priority":2,"title":"Write docs"}`},
+		{"client-supplied fields replaced or removed", "/api/v1/tasks",
+			`{"_gateway_email":"root@example.com","_gateway_user_id":"root","title":"Write docs"}`,
+			`{"_gateway_roles":["user","admin"],"_gateway_user_id":"jane","title":"Write docs"}`},
+		{"client-supplied fields removed in any case", "/api/v1/tasks",
+			`{"_GATEWAY_USER_ID":"root","_Gateway_Email":"root@example.com","title":"Write docs"}`,
+			`{"_gateway_roles":["user","admin"],"_gateway_user_id":"jane","title":"Write docs"}`},
+		{"array body unchanged", "/api/v1/tasks", `[{"title":"Write docs"}]`, `[{"title":"Write docs"}]`},
+		{"unconfigured route unchanged", "/api/v1/tasks/import", `{"title":"Write docs"}`, `{"title":"Write docs"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := newProxyRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+			}
+			echo := decodeEcho(t, w)
+			if echo.Body != tt.expectBody {
+				t.Errorf("Expected upstream body %s, got %s", tt.expectBody, echo.Body)
+			}
+			if got := echo.Headers.Get("Content-Length"); got != strconv.Itoa(len(tt.expectBody)) {
+				t.Errorf("Expected Content-Length %d, got '%s'", len(tt.expectBody), got)
+			}
+		})
+	}
+
+	// Bodies the fields cannot be stripped from never reach the upstream
+	rejected := []struct {
+		name            string
+		contentType     string
+		contentEncoding string
+		body            string
+		expectStatus    int
+	}{
+		{"oversized body", "application/json", "",
+			`{"_gateway_user_id":"root","title":"` + strings.Repeat("a", 1<<20) + `"}`, http.StatusRequestEntityTooLarge},
+		{"encoded body", "application/json", "gzip", `{"_gateway_user_id":"root"}`, http.StatusUnsupportedMediaType},
+		{"non-JSON content type", "text/plain", "", `{"_gateway_user_id":"root"}`, http.StatusUnsupportedMediaType},
+		{"invalid JSON", "application/json", "", `{"_gateway_user_id":"root",`, http.StatusBadRequest},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.contentEncoding != "" {
+				req.Header.Set("Content-Encoding", tt.contentEncoding)
+			}
+			w := newProxyRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectStatus, w.Code, w.Body.String())
+			}
+			if strings.Contains(w.Body.String(), "root") {
+				t.Errorf("Expected the body not forwarded upstream, got %s", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains upstream transport construction (TLS / mTLS per service).
+//
+// Associated Frontend Files:
+//   - None (gateway to backend connections only)
+package handlers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// buildTransports creates the shared default transport and one transport per
+// service with its own TLS settings, so connection pools are reused across requests.
+func (p *ProxyHandler) buildTransports() error {
+	defaultTransport, err := p.newTransport(p.options.UpstreamTLS, "")
+	if err != nil {
+		return fmt.Errorf("default upstream transport: %w", err)
+	}
+	p.defaultTransport = defaultTransport
+
+	p.transports = make(map[string]*http.Transport)
+	for name, service := range p.options.Services {
+		if service.TLS == nil && service.Protocol == "" {
+			continue
+		}
+		tlsCfg := service.TLS
+		if tlsCfg == nil {
+			tlsCfg = p.options.UpstreamTLS
+		}
+		transport, err := p.newTransport(tlsCfg, service.Protocol)
+		if err != nil {
+			return fmt.Errorf("upstream transport for service %s: %w", name, err)
+		}
+		p.transports[name] = transport
+	}
+
+	p.externalTransports = make(map[string]*http.Transport)
+	for name, service := range p.options.ExternalServices {
+		if service.TLS == nil {
+			continue
+		}
+		transport, err := p.newTransport(service.TLS, "")
+		if err != nil {
+			return fmt.Errorf("upstream transport for external service %s: %w", name, err)
+		}
+		p.externalTransports[name] = transport
+	}
+
+	return nil
+}
+
+// transportFor returns the transport used to reach serviceName
+func (p *ProxyHandler) transportFor(serviceName string) *http.Transport {
+	if transport, ok := p.transports[serviceName]; ok {
+		return transport
+	}
+	return p.defaultTransport
+}
+
+// trackedTransportFor returns the transport of serviceName reporting connection use to PoolStats
+func (p *ProxyHandler) trackedTransportFor(serviceName string) http.RoundTripper {
+	return trackConnUsage(p.transportFor(serviceName))
+}
+
+// externalUpstream returns how to reach the external service serviceName
+func (p *ProxyHandler) externalUpstream(serviceName string) upstream {
+	service := p.options.ExternalServices[serviceName]
+	transport, ok := p.externalTransports[serviceName]
+	if !ok {
+		transport = p.defaultTransport
+	}
+	return upstream{
+		transport: trackConnUsage(transport),
+		headers:   service.Headers,
+		timeout:   service.Timeout,
+	}
+}
+
+// newTransport clones the default HTTP transport and applies the connection pool, tlsCfg and protocol
+func (p *ProxyHandler) newTransport(tlsCfg *UpstreamTLSConfig, protocol string) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	p.applyConnectionPool(transport)
+
+	protocols, err := upstreamProtocols(protocol)
+	if err != nil {
+		return nil, err
+	}
+	transport.Protocols = protocols
+	if tlsCfg == nil {
+		return transport, nil
+	}
+
+	clientTLS, err := p.buildTLSConfig(tlsCfg)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = clientTLS
+	return transport, nil
+}
+
+// upstreamProtocols maps a ServiceConfig.Protocol to the HTTP versions a transport may use
+func upstreamProtocols(protocol string) (*http.Protocols, error) {
+	protocols := new(http.Protocols)
+	switch protocol {
+	case UpstreamProtocolHTTP1, "":
+		protocols.SetHTTP1(true)
+	case UpstreamProtocolH2:
+		// HTTP/2 over TLS (negotiated via ALPN); HTTP/1.1 remains the fallback
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(true)
+	case UpstreamProtocolH2C:
+		// Cleartext HTTP/2 with prior knowledge (e.g. gRPC backends)
+		protocols.SetUnencryptedHTTP2(true)
+	default:
+		return nil, fmt.Errorf("unknown upstream protocol %q (expected http1, h2 or h2c)", protocol)
+	}
+	return protocols, nil
+}
+
+// buildTLSConfig loads the client certificate and CA bundle for an upstream
+func (p *ProxyHandler) buildTLSConfig(tlsCfg *UpstreamTLSConfig) (*tls.Config, error) {
+	clientTLS := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if tlsCfg.CertFile != "" || tlsCfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(tlsCfg.CertFile, tlsCfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		clientTLS.Certificates = []tls.Certificate{cert}
+	}
+
+	if tlsCfg.CAFile != "" {
+		caPEM, err := os.ReadFile(tlsCfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("CA bundle contains no valid certificates")
+		}
+		clientTLS.RootCAs = pool
+	}
+
+	if tlsCfg.InsecureSkipVerify {
+		if !p.options.AllowInsecureUpstreamTLS {
+			return nil, errors.New("InsecureSkipVerify requires AllowInsecureUpstreamTLS (development only)")
+		}
+		p.logger.Warn("Upstream TLS certificate verification disabled (development only)")
+		clientTLS.InsecureSkipVerify = true
+	}
+
+	return clientTLS, nil
+}
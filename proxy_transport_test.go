@@ -0,0 +1,293 @@
+package handlers_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugjb/api-gateway/config"
+	"github.com/ugjb/api-gateway/handlers"
+)
+
+// writePEM writes a PEM block to dir/name and returns the path
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// newClientCertificate creates a CA and a client certificate signed by it.
+// Returns the CA pool and the paths of the client cert and key files.
+func newClientCertificate(t *testing.T) (*x509.CertPool, string, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-client-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("Failed to create CA: %v", err)
+	}
+	caCert, _ := x509.ParseCertificate(caDER)
+
+	clientKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	clientTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "api-gateway"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientDER, err := x509.CreateCertificate(rand.Reader, clientTemplate, caCert, &clientKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("Failed to create client certificate: %v", err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(clientKey)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+
+	return pool, writePEM(t, dir, "client.crt", "CERTIFICATE", clientDER), writePEM(t, dir, "client.key", "EC PRIVATE KEY", keyDER)
+}
+
+// TestProxyUpstreamMutualTLS verifies the proxy presents the configured client certificate
+func TestProxyUpstreamMutualTLS(t *testing.T) {
+	clientCAs, certFile, keyFile := newClientCertificate(t)
+
+	upstream := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello " + r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	upstream.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	upstream.StartTLS()
+	t.Cleanup(upstream.Close)
+
+	caFile := writePEM(t, t.TempDir(), "upstream-ca.crt", "CERTIFICATE", upstream.Certificate().Raw)
+
+	cfg := &config.Config{}
+	cfg.ServiceURLs.EmployeeRegistry = upstream.URL
+	cfg.ServiceURLs.TaskDispatcher = upstream.URL
+
+	opts := handlers.DefaultProxyOptions()
+	opts.Services = map[string]handlers.ServiceConfig{
+		"employee_registry": {TLS: &handlers.UpstreamTLSConfig{CertFile: certFile, KeyFile: keyFile, CAFile: caFile}},
+		"task_dispatcher":   {TLS: &handlers.UpstreamTLSConfig{CAFile: caFile}},
+	}
+	proxyHandler := newTestProxyHandler(t, cfg, opts)
+
+	router := gin.New()
+	router.GET("/with-cert", proxyHandler.ProxyToService("employee_registry", "/"))
+	router.GET("/without-cert", proxyHandler.ProxyToService("task_dispatcher", "/"))
+
+	req, _ := http.NewRequest(http.MethodGet, "/with-cert", nil)
+	w := newProxyRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d with client certificate, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if w.Body.String() != "hello api-gateway" {
+		t.Errorf("Expected upstream to see the gateway client certificate, got '%s'", w.Body.String())
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/without-cert", nil)
+	w = newProxyRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("Expected status %d without client certificate, got %d", http.StatusBadGateway, w.Code)
+	}
+}
+
+// TestProxyInsecureSkipVerifyRequiresDevFlag verifies InsecureSkipVerify is rejected unless explicitly allowed
+func TestProxyInsecureSkipVerifyRequiresDevFlag(t *testing.T) {
+	opts := handlers.DefaultProxyOptions()
+	opts.UpstreamTLS = &handlers.UpstreamTLSConfig{InsecureSkipVerify: true}
+
+	if _, err := handlers.NewProxyHandlerWithOptions(&config.Config{}, nil, opts); err == nil {
+		t.Error("Expected error for InsecureSkipVerify without AllowInsecureUpstreamTLS")
+	}
+}
+
+// newProtoUpstream creates an upstream reporting the HTTP version it was reached with
+func newProtoUpstream(t *testing.T, tlsServer bool) *httptest.Server {
+	upstream := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	if tlsServer {
+		upstream.EnableHTTP2 = true
+		upstream.StartTLS()
+	} else {
+		upstream.Config.Protocols = new(http.Protocols)
+		upstream.Config.Protocols.SetHTTP1(true)
+		upstream.Config.Protocols.SetUnencryptedHTTP2(true)
+		upstream.Start()
+	}
+	t.Cleanup(upstream.Close)
+	return upstream
+}
+
+// TestProxyUpstreamHTTP2 verifies h2/h2c services are reached over HTTP/2 and others over HTTP/1.1
+func TestProxyUpstreamHTTP2(t *testing.T) {
+	tests := []struct {
+		name          string
+		tlsServer     bool
+		protocol      string
+		expectedProto string
+	}{
+		{"default stays on HTTP/1.1", true, "", "HTTP/1.1"},
+		{"h2 over TLS", true, handlers.UpstreamProtocolH2, "HTTP/2.0"},
+		{"h2c cleartext", false, handlers.UpstreamProtocolH2C, "HTTP/2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := newProtoUpstream(t, tt.tlsServer)
+
+			service := handlers.ServiceConfig{Protocol: tt.protocol}
+			if tt.tlsServer {
+				caFile := writePEM(t, t.TempDir(), "ca.pem", "CERTIFICATE", upstream.Certificate().Raw)
+				service.TLS = &handlers.UpstreamTLSConfig{CAFile: caFile}
+			}
+
+			cfg := &config.Config{}
+			cfg.ServiceURLs.TaskDispatcher = upstream.URL
+			opts := handlers.DefaultProxyOptions()
+			opts.Services = map[string]handlers.ServiceConfig{"task_dispatcher": service}
+			proxyHandler := newTestProxyHandler(t, cfg, opts)
+
+			router := gin.New()
+			router.GET("/api/v1/tasks", proxyHandler.ProxyToService("task_dispatcher", "/tasks"))
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+			w := newProxyRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			if got := w.Body.String(); got != tt.expectedProto {
+				t.Errorf("Expected upstream request over %s, got %s", tt.expectedProto, got)
+			}
+		})
+	}
+
+	t.Run("unknown protocol", func(t *testing.T) {
+		opts := handlers.DefaultProxyOptions()
+		opts.Services = map[string]handlers.ServiceConfig{"task_dispatcher": {Protocol: "spdy"}}
+		if _, err := handlers.NewProxyHandlerWithOptions(&config.Config{}, nil, opts); err == nil {
+			t.Error("Expected error for unknown upstream protocol")
+		}
+	})
+}
+
+// TestProxyConnectionPoolConfig verifies pool limits apply to the shared and per-service transports
+func TestProxyConnectionPoolConfig(t *testing.T) {
+	cfg := &config.Config{}
+	opts := handlers.DefaultProxyOptions()
+	opts.ConnectionPool = handlers.ConnectionPoolConfig{
+		MaxIdleConns:        200,
+		MaxIdleConnsPerHost: 32,
+		MaxConnsPerHost:     64,
+		IdleConnTimeout:     45 * time.Second,
+	}
+	opts.Services["grpc_backend"] = handlers.ServiceConfig{Protocol: handlers.UpstreamProtocolH2C}
+	proxyHandler := newTestProxyHandler(t, cfg, opts)
+
+	for _, service := range []string{"task_dispatcher", "grpc_backend"} {
+		transport := proxyHandler.UpstreamTransport(service)
+		if transport.MaxIdleConns != 200 || transport.MaxIdleConnsPerHost != 32 ||
+			transport.MaxConnsPerHost != 64 || transport.IdleConnTimeout != 45*time.Second {
+			t.Errorf("Expected %s transport configured from ConnectionPool, got MaxIdleConns=%d MaxIdleConnsPerHost=%d MaxConnsPerHost=%d IdleConnTimeout=%v",
+				service, transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.MaxConnsPerHost, transport.IdleConnTimeout)
+		}
+	}
+
+	defaults := newTestProxyHandler(t, cfg, handlers.DefaultProxyOptions()).UpstreamTransport("task_dispatcher")
+	stdlib := http.DefaultTransport.(*http.Transport)
+	if defaults.MaxIdleConns != stdlib.MaxIdleConns || defaults.IdleConnTimeout != stdlib.IdleConnTimeout ||
+		defaults.MaxIdleConnsPerHost != 0 || defaults.MaxConnsPerHost != 0 {
+		t.Errorf("Expected net/http pool defaults without ConnectionPool, got MaxIdleConns=%d MaxIdleConnsPerHost=%d MaxConnsPerHost=%d IdleConnTimeout=%v",
+			defaults.MaxIdleConns, defaults.MaxIdleConnsPerHost, defaults.MaxConnsPerHost, defaults.IdleConnTimeout)
+	}
+}
+
+// TestProxyWarmUp verifies warm-up probes every configured upstream and leaves the connections idle
+func TestProxyWarmUp(t *testing.T) {
+	type probe struct {
+		path   string
+		warmUp string
+	}
+	var mu sync.Mutex
+	probes := make(map[string][]probe)
+	newUpstream := func(name string) *httptest.Server {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			probes[name] = append(probes[name], probe{r.URL.Path, r.Header.Get(handlers.HeaderWarmUp)})
+			mu.Unlock()
+			w.Write([]byte("ok"))
+		}))
+		t.Cleanup(upstream.Close)
+		return upstream
+	}
+	tasks := newUpstream("tasks")
+	reports := newUpstream("reports")
+
+	cfg := &config.Config{}
+	cfg.ServiceURLs.TaskDispatcher = tasks.URL
+	opts := handlers.DefaultProxyOptions()
+	opts.ConnectionPool = handlers.ConnectionPoolConfig{MaxIdleConnsPerHost: 5, WarmUpConns: 3}
+	opts.Services["reports"] = handlers.ServiceConfig{URL: reports.URL + "/base", HealthPath: "/healthz"}
+	proxyHandler := newTestProxyHandler(t, cfg, opts)
+
+	select {
+	case <-proxyHandler.WarmUp(context.Background()):
+	case <-time.After(handlers.DefaultWarmUpTimeout + time.Second):
+		t.Fatal("Expected warm-up to finish within its timeout")
+	}
+
+	mu.Lock()
+	expected := map[string][]probe{
+		"tasks":   {{"/", "true"}, {"/", "true"}, {"/", "true"}},
+		"reports": {{"/base/healthz", "true"}, {"/base/healthz", "true"}, {"/base/healthz", "true"}},
+	}
+	if !reflect.DeepEqual(probes, expected) {
+		t.Errorf("Expected probes %v, got %v", expected, probes)
+	}
+	mu.Unlock()
+
+	for _, stats := range proxyHandler.PoolStats() {
+		if stats.Open != 3 || stats.Idle != 3 {
+			t.Errorf("Expected 3 idle connections to %s, got %+v", stats.Host, stats)
+		}
+	}
+	if hosts := len(proxyHandler.PoolStats()); hosts != 2 {
+		t.Errorf("Expected connections to 2 hosts, got %d", hosts)
+	}
+}
@@ -0,0 +1,88 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains version-based routing for APIs served side by side
+// (e.g. /api/v1 and /api/v2) by different backends.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (API_BASE_URL selects the API version)
+//
+// The version is the first path segment under APIBasePath ("v" followed by
+// digits). resolveServiceURL picks the version's service URL, and the
+// APIVersioning middleware rejects unknown and removed versions and announces
+// deprecation with the Deprecation (RFC 9745) and Sunset (RFC 8594) headers.
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiVersion returns the version segment of an API path (e.g. "v2" for
+// "/api/v2/tasks"), or "" for unversioned paths
+func (p *ProxyHandler) apiVersion(path string) string {
+	if !p.isAPIPath(path) {
+		return ""
+	}
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, p.options.APIBasePath+"/"), "/")
+	if len(segment) < 2 || segment[0] != 'v' {
+		return ""
+	}
+	if _, err := strconv.ParseUint(segment[1:], 10, 32); err != nil {
+		return ""
+	}
+	return segment
+}
+
+// versionServiceURL returns the URL of serviceName for the request's API version ("" if none)
+func (p *ProxyHandler) versionServiceURL(c *gin.Context, serviceName string) string {
+	if len(p.options.APIVersions) == 0 {
+		return ""
+	}
+	return p.options.APIVersions[p.apiVersion(c.Request.URL.Path)].ServiceURLs[serviceName]
+}
+
+// APIVersioning returns a middleware enforcing ProxyOptions.APIVersions: requests
+// to unlisted versions get 404, removed (or sunset) versions 410 API_VERSION_GONE,
+// and deprecated versions the Deprecation and Sunset headers. Unversioned paths
+// pass through. Register it on the API routes before the proxy handlers.
+func (p *ProxyHandler) APIVersioning() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version := p.apiVersion(c.Request.URL.Path)
+		if version == "" || len(p.options.APIVersions) == 0 {
+			c.Next()
+			return
+		}
+
+		versionConfig, ok := p.options.APIVersions[version]
+		if !ok {
+			NotFoundHandler(c)
+			c.Abort()
+			return
+		}
+		if versionConfig.Removed || (!versionConfig.Sunset.IsZero() && !time.Now().Before(versionConfig.Sunset)) {
+			c.AbortWithStatusJSON(http.StatusGone, gin.H{
+				"error": gin.H{
+					"code":    "API_VERSION_GONE",
+					"message": "API version " + version + " is no longer available",
+				},
+			})
+			return
+		}
+
+		if versionConfig.Deprecated {
+			deprecation := "true"
+			if !versionConfig.DeprecatedAt.IsZero() {
+				deprecation = "@" + strconv.FormatInt(versionConfig.DeprecatedAt.Unix(), 10)
+			}
+			c.Header("Deprecation", deprecation)
+			if !versionConfig.Sunset.IsZero() {
+				c.Header("Sunset", versionConfig.Sunset.UTC().Format(http.TimeFormat))
+			}
+		}
+		c.Next()
+	}
+}
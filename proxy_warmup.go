@@ -0,0 +1,170 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains the connection pool warm-up run after startup, so the
+// first requests to each backend do not pay connection setup latency.
+//
+// Associated Frontend Files:
+//   - None (gateway to backend connections only)
+//
+// WarmUp sends ConnectionPoolConfig.WarmUpConns concurrent GET probes to the
+// HealthPath (or "/") of every configured upstream. Each response is held until
+// all probes to that upstream have one, so every probe gets its own connection;
+// they are then returned to the idle pool together.
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// HeaderWarmUp marks warm-up probes so backends can leave them out of logs and metrics
+const HeaderWarmUp = "X-Gateway-Warm-Up"
+
+// DefaultWarmUpTimeout bounds WarmUp without a ConnectionPoolConfig.WarmUpTimeout
+const DefaultWarmUpTimeout = 5 * time.Second
+
+// maxWarmUpBodyBytes caps the probe response read; larger bodies close the connection instead
+const maxWarmUpBodyBytes = 64 << 10
+
+// warmUpTarget is one upstream base URL to prime and the service it belongs to
+type warmUpTarget struct {
+	service string
+	url     string
+}
+
+// WarmUp primes the upstream connection pools in the background and returns a
+// channel closed once it is done. It never blocks the caller and gives up after
+// WarmUpTimeout or when ctx ends; failed probes are logged and skipped.
+func (p *ProxyHandler) WarmUp(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	if p.options.ConnectionPool.WarmUpConns <= 0 {
+		close(done)
+		return done
+	}
+
+	go func() {
+		defer close(done)
+		timeout := p.options.ConnectionPool.WarmUpTimeout
+		if timeout <= 0 {
+			timeout = DefaultWarmUpTimeout
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		for _, target := range p.warmUpTargets() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.warmUpUpstream(ctx, target)
+			}()
+		}
+		wg.Wait()
+	}()
+	return done
+}
+
+// warmUpTargets returns every configured service URL and instance, once per transport and host
+func (p *ProxyHandler) warmUpTargets() []warmUpTarget {
+	type poolKey struct {
+		transport *http.Transport
+		host      string
+	}
+	seen := make(map[poolKey]bool)
+	var targets []warmUpTarget
+	for _, service := range p.configuredServices() {
+		urls := []string{p.resolvedServiceURL(service)}
+		if lb, ok := p.balancers[service]; ok {
+			urls = urls[:0]
+			for _, inst := range lb.instances {
+				urls = append(urls, inst.url)
+			}
+		}
+		for _, rawURL := range urls {
+			u, err := url.Parse(rawURL)
+			if err != nil || u.Host == "" {
+				continue
+			}
+			key := poolKey{p.transportFor(service), u.Host}
+			if !seen[key] {
+				seen[key] = true
+				targets = append(targets, warmUpTarget{service: service, url: rawURL})
+			}
+		}
+	}
+	return targets
+}
+
+// warmUpUpstream opens up to WarmUpConns connections to target and leaves them idle
+func (p *ProxyHandler) warmUpUpstream(ctx context.Context, target warmUpTarget) {
+	transport := p.transportFor(target.service)
+	conns := p.options.ConnectionPool.WarmUpConns
+	// Connections beyond the idle limit would be closed, and probes beyond
+	// MaxConnsPerHost would wait for a connection held by another probe
+	idlePerHost := transport.MaxIdleConnsPerHost
+	if idlePerHost <= 0 {
+		idlePerHost = http.DefaultMaxIdleConnsPerHost
+	}
+	conns = min(conns, idlePerHost)
+	if transport.MaxConnsPerHost > 0 {
+		conns = min(conns, transport.MaxConnsPerHost)
+	}
+
+	probe, err := url.Parse(target.url)
+	if err != nil {
+		return
+	}
+	healthPath := "/"
+	if service, ok := p.LookupService(target.service); ok && service.HealthPath != "" {
+		healthPath = service.HealthPath
+	}
+	probe.Path = joinPath(probe.Path, healthPath)
+	probeURL := probe.String()
+
+	roundTripper := trackConnUsage(transport)
+	responses := make([]*http.Response, conns)
+	var wg sync.WaitGroup
+	for i := range responses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, probeURL, nil)
+			if err != nil {
+				return
+			}
+			req.Header.Set(HeaderWarmUp, "true")
+			resp, err := roundTripper.RoundTrip(req)
+			if err != nil {
+				p.logger.Warn("Warm-up probe failed",
+					serviceField(target.service),
+					zap.String("target", redactURLCredentials(target.url)),
+					zap.Error(err),
+				)
+				return
+			}
+			responses[i] = resp
+		}()
+	}
+	wg.Wait()
+
+	opened := 0
+	for _, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxWarmUpBodyBytes))
+		resp.Body.Close()
+		opened++
+	}
+	p.logger.Info("Upstream connection pool warmed",
+		serviceField(target.service),
+		zap.String("target", redactURLCredentials(target.url)),
+		zap.Int("connections", opened),
+	)
+}
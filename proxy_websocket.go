@@ -0,0 +1,146 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains WebSocket handshake validation, subprotocol negotiation
+// and connection accounting (WSMaxConnections, connection metrics).
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (WebSocket connections proxied through gateway)
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// webSocketVersion is the only protocol version defined by RFC 6455
+const webSocketVersion = "13"
+
+// errWebSocketNegotiation marks an upstream handshake the client did not agree to
+var errWebSocketNegotiation = errors.New("websocket negotiation failed")
+
+// isWebSocketUpgrade reports whether the client asks to upgrade to WebSocket
+// (the Upgrade token is case-insensitive)
+func isWebSocketUpgrade(c *gin.Context) bool {
+	return strings.EqualFold(c.GetHeader("Upgrade"), "websocket")
+}
+
+// isWebSocketPath reports whether path is one of WSPaths or below one
+func (p *ProxyHandler) isWebSocketPath(path string) bool {
+	for _, wsPath := range p.options.WSPaths {
+		wsPath = strings.TrimSuffix(wsPath, "/")
+		if path == wsPath || strings.HasPrefix(path, wsPath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnknownAPIPath reports whether a catch-all handler must answer the request
+// with the JSON 404: API paths, except WebSocket upgrades to WSPaths
+func (p *ProxyHandler) isUnknownAPIPath(c *gin.Context) bool {
+	path := c.Request.URL.Path
+	if !p.isAPIPath(path) {
+		return false
+	}
+	return !(isWebSocketUpgrade(c) && p.isWebSocketPath(path))
+}
+
+// checkWebSocketHandshake validates the client's upgrade request before it is proxied.
+// Returns false if an error response was sent.
+func checkWebSocketHandshake(c *gin.Context) bool {
+	if version := c.GetHeader("Sec-WebSocket-Version"); version != "" && version != webSocketVersion {
+		c.Header("Sec-WebSocket-Version", webSocketVersion)
+		c.JSON(http.StatusUpgradeRequired, gin.H{
+			"error": gin.H{
+				"code":    "UNSUPPORTED_WEBSOCKET_VERSION",
+				"message": "Only WebSocket version " + webSocketVersion + " is supported",
+			},
+		})
+		return false
+	}
+
+	if c.GetHeader("Sec-WebSocket-Key") == "" {
+		sendWebSocketNegotiationError(c, fmt.Errorf("%w: missing Sec-WebSocket-Key", errWebSocketNegotiation))
+		return false
+	}
+	return true
+}
+
+// checkWebSocketSubprotocol verifies the upstream selected one of the client's
+// requested subprotocols (or none, if the client requested none).
+func checkWebSocketSubprotocol(requestHeader, responseHeader http.Header) error {
+	selected := strings.TrimSpace(responseHeader.Get("Sec-WebSocket-Protocol"))
+	requested := webSocketSubprotocols(requestHeader)
+
+	if selected == "" {
+		return nil
+	}
+	for _, protocol := range requested {
+		if protocol == selected {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: upstream selected subprotocol %q, client requested %v", errWebSocketNegotiation, selected, requested)
+}
+
+// webSocketSubprotocols returns the subprotocols requested by the client, in preference order
+func webSocketSubprotocols(h http.Header) []string {
+	var protocols []string
+	for _, value := range h.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(value, ",") {
+			if protocol = strings.TrimSpace(protocol); protocol != "" {
+				protocols = append(protocols, protocol)
+			}
+		}
+	}
+	return protocols
+}
+
+// sendWebSocketNegotiationError sends the standardized 400 for a failed WebSocket handshake
+func sendWebSocketNegotiationError(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": gin.H{
+			"code":    "WEBSOCKET_NEGOTIATION_FAILED",
+			"message": strings.TrimPrefix(err.Error(), errWebSocketNegotiation.Error()+": "),
+		},
+	})
+}
+
+// acquireWebSocketSlot reserves one of WSMaxConnections for an upgrade request.
+// Returns false if the gateway is at capacity and an error response was sent.
+func (p *ProxyHandler) acquireWebSocketSlot(c *gin.Context) bool {
+	limit := int64(p.options.WSMaxConnections)
+	for {
+		n := p.webSocketSlots.Load()
+		if limit > 0 && n >= limit {
+			p.logger.Warn("WebSocket capacity reached", zap.Int64("limit", limit))
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": gin.H{
+					"code":    "WS_CAPACITY",
+					"message": "Too many WebSocket connections, please retry later",
+				},
+			})
+			return false
+		}
+		if p.webSocketSlots.CompareAndSwap(n, n+1) {
+			return true
+		}
+	}
+}
+
+// webSocketOpened counts a connection that completed the upgrade
+func (p *ProxyHandler) webSocketOpened() {
+	p.activeWebSockets.Add(1)
+	p.options.Metrics.webSocketOpened()
+}
+
+// webSocketClosed releases the count of an upgraded connection
+func (p *ProxyHandler) webSocketClosed() {
+	p.activeWebSockets.Add(-1)
+	p.options.Metrics.webSocketClosed()
+}
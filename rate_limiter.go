@@ -0,0 +1,101 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains a fixed-window rate limiter backed by a Store, so limits
+// are shared across gateway replicas when the Redis backend is used.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (error formatting and display)
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimiter allows up to limit requests per key in each fixed window
+type RateLimiter struct {
+	store  Store
+	limit  int64
+	window time.Duration
+}
+
+// NewRateLimiter creates a RateLimiter storing counters in store
+func NewRateLimiter(store Store, limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{store: store, limit: int64(limit), window: window}
+}
+
+// Allow counts a request for key and reports whether it is within the limit,
+// along with the time until the current window resets
+func (l *RateLimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	now := time.Now()
+	windowStart := now.Truncate(l.window)
+	retryAfter := windowStart.Add(l.window).Sub(now)
+
+	counterKey := "ratelimit:" + key + ":" + strconv.FormatInt(windowStart.Unix(), 10)
+	count, err := l.store.Incr(ctx, counterKey)
+	if err != nil {
+		return false, 0, err
+	}
+	if count == 1 {
+		if err := l.store.Expire(ctx, counterKey, l.window); err != nil {
+			return false, 0, err
+		}
+	}
+	return count <= l.limit, retryAfter, nil
+}
+
+// RateLimit returns a middleware rejecting requests over the limit with 429.
+// keyFunc selects the limited identity (e.g. RealClientIP). Store errors fail open.
+func RateLimit(limiter *RateLimiter, keyFunc func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limitRequest(c, limiter, keyFunc(c))
+	}
+}
+
+// UserRateLimit returns a middleware limiting authenticated requests per user_id
+// with authenticated, and anonymous requests per client IP with anonymous, so
+// users behind a shared NAT get independent budgets. It must be registered after
+// the auth middleware that sets user_id. A nil limiter disables that class.
+func UserRateLimit(authenticated, anonymous *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limiter := anonymous
+		if c.GetString("user_id") != "" {
+			limiter = authenticated
+		}
+		if limiter == nil {
+			c.Next()
+			return
+		}
+		limitRequest(c, limiter, UserOrIPKey(c))
+	}
+}
+
+// UserOrIPKey keys on the authenticated user_id, falling back to the client IP
+func UserOrIPKey(c *gin.Context) string {
+	if userID := c.GetString("user_id"); userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + RealClientIP(c)
+}
+
+// limitRequest counts the request against key and aborts with 429 when over the limit
+func limitRequest(c *gin.Context, limiter *RateLimiter, key string) {
+	if err := checkRateLimit(c.Request.Context(), limiter, key); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Next()
+}
+
+// checkRateLimit counts a request against key, returning ErrRateLimited when
+// over the limit. Store errors fail open.
+func checkRateLimit(ctx context.Context, limiter *RateLimiter, key string) error {
+	allowed, retryAfter, err := limiter.Allow(ctx, key)
+	if err != nil || allowed {
+		return nil
+	}
+	return &ProxyError{Err: ErrRateLimited, RetryAfter: retryAfter}
+}
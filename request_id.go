@@ -0,0 +1,54 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains request ID handling, which correlates one client request
+// across the gateway's logs and the services it calls.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (error formatting and display - shows X-Request-ID)
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderRequestID carries the request correlation ID
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied request IDs
+const maxRequestIDLength = 128
+
+// ensureRequestID returns the request's correlation ID, generating one when the
+// client sent none or an unusable one. The ID is stored in the inbound request
+// headers, so logFields and proxied requests see it, and echoed to the client.
+func ensureRequestID(c *gin.Context) string {
+	id := c.GetHeader(HeaderRequestID)
+	if !validRequestID(id) {
+		id = newRequestID()
+		c.Request.Header.Set(HeaderRequestID, id)
+	}
+	c.Header(HeaderRequestID, id)
+	return id
+}
+
+// validRequestID accepts short IDs of visible ASCII, which are safe to log and forward
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID generates a random request ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
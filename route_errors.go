@@ -0,0 +1,40 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains gateway-wide fallback handlers for unmatched routes.
+//
+// Associated Frontend Files:
+//   - web/app/src/lib/api.ts (error formatting and display)
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotFoundHandler returns a standardized JSON 404 for unknown routes
+// Register with router.NoRoute(handlers.NotFoundHandler)
+func NotFoundHandler(c *gin.Context) {
+	c.JSON(http.StatusNotFound, gin.H{
+		"error": gin.H{
+			"code":    "NOT_FOUND",
+			"message": "API endpoint not found",
+			"path":    c.Request.URL.Path,
+			"method":  c.Request.Method,
+		},
+	})
+}
+
+// MethodNotAllowedHandler returns a standardized JSON 405 for known routes called with the wrong method
+// Register with router.NoMethod(handlers.MethodNotAllowedHandler) and set
+// router.HandleMethodNotAllowed = true
+func MethodNotAllowedHandler(c *gin.Context) {
+	c.JSON(http.StatusMethodNotAllowed, gin.H{
+		"error": gin.H{
+			"code":    "METHOD_NOT_ALLOWED",
+			"message": "Method not allowed for this endpoint",
+			"path":    c.Request.URL.Path,
+			"method":  c.Request.Method,
+		},
+	})
+}
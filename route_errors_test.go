@@ -0,0 +1,69 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugjb/api-gateway/handlers"
+	"go.uber.org/zap"
+)
+
+// setupRouteErrorsRouter creates a router with the gateway fallback handlers registered
+func setupRouteErrorsRouter() *gin.Engine {
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(handlers.NotFoundHandler)
+	router.NoMethod(handlers.MethodNotAllowedHandler)
+
+	healthHandler := handlers.NewHealthHandler(zap.NewNop())
+	router.GET("/health", healthHandler.Health)
+
+	return router
+}
+
+// TestRouteErrorHandlers verifies the error envelope for unknown routes and wrong methods
+func TestRouteErrorHandlers(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"unknown route", http.MethodGet, "/api/v1/does-not-exist", http.StatusNotFound, "NOT_FOUND"},
+		{"wrong method", http.MethodPost, "/health", http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED"},
+	}
+
+	router := setupRouteErrorsRouter()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			var body map[string]map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Expected JSON body, got error: %v", err)
+			}
+
+			errBody := body["error"]
+			if errBody["code"] != tt.expectedCode {
+				t.Errorf("Expected code '%s', got '%v'", tt.expectedCode, errBody["code"])
+			}
+			if errBody["path"] != tt.path {
+				t.Errorf("Expected path '%s', got '%v'", tt.path, errBody["path"])
+			}
+			if errBody["method"] != tt.method {
+				t.Errorf("Expected method '%s', got '%v'", tt.method, errBody["method"])
+			}
+		})
+	}
+}
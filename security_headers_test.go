@@ -0,0 +1,102 @@
+package handlers_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugjb/api-gateway/handlers"
+	"go.uber.org/zap"
+)
+
+// setupSecurityHeadersRouter creates a router serving a gateway-originated JSON response
+func setupSecurityHeadersRouter() *gin.Engine {
+	router := gin.New()
+	router.Use(handlers.SecurityHeaders(handlers.DefaultSecurityHeadersConfig()))
+
+	healthHandler := handlers.NewHealthHandler(zap.NewNop())
+	router.GET("/health", healthHandler.Health)
+	router.GET("/custom", func(c *gin.Context) {
+		c.Header("X-Frame-Options", "SAMEORIGIN")
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	return router
+}
+
+// TestSecurityHeadersOnGatewayResponse verifies security headers on a JSON response
+func TestSecurityHeadersOnGatewayResponse(t *testing.T) {
+	router := setupSecurityHeadersRouter()
+
+	req, _ := http.NewRequest(http.MethodGet, "/health", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	expected := map[string]string{
+		"X-Content-Type-Options":  "nosniff",
+		"X-Frame-Options":         "DENY",
+		"Referrer-Policy":         "strict-origin-when-cross-origin",
+		"Content-Security-Policy": "default-src 'none'; frame-ancestors 'none'",
+	}
+	for header, value := range expected {
+		if got := w.Header().Get(header); got != value {
+			t.Errorf("Expected %s '%s', got '%s'", header, value, got)
+		}
+	}
+
+	if hsts := w.Header().Get("Strict-Transport-Security"); hsts != "" {
+		t.Errorf("Expected no Strict-Transport-Security over HTTP, got '%s'", hsts)
+	}
+}
+
+// TestSecurityHeadersHSTSOverHTTPS verifies HSTS is only sent over HTTPS
+func TestSecurityHeadersHSTSOverHTTPS(t *testing.T) {
+	router := setupSecurityHeadersRouter()
+
+	req, _ := http.NewRequest(http.MethodGet, "/health", nil)
+	req.TLS = &tls.ConnectionState{}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if hsts := w.Header().Get("Strict-Transport-Security"); hsts != "max-age=31536000; includeSubDomains" {
+		t.Errorf("Expected HSTS header over HTTPS, got '%s'", hsts)
+	}
+}
+
+// TestSecurityHeadersIgnoreSpoofedProto verifies a client-sent X-Forwarded-Proto does not enable HSTS
+func TestSecurityHeadersIgnoreSpoofedProto(t *testing.T) {
+	router := gin.New()
+	if err := handlers.ConfigureTrustedProxies(router, nil); err != nil {
+		t.Fatalf("Failed to configure trusted proxies: %v", err)
+	}
+	router.Use(handlers.SecurityHeaders(handlers.DefaultSecurityHeadersConfig()))
+	router.GET("/health", handlers.NewHealthHandler(zap.NewNop()).Health)
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if hsts := w.Header().Get("Strict-Transport-Security"); hsts != "" {
+		t.Errorf("Expected no HSTS for a spoofed X-Forwarded-Proto, got '%s'", hsts)
+	}
+}
+
+// TestSecurityHeadersDoNotOverride verifies handler-set headers take precedence
+func TestSecurityHeadersDoNotOverride(t *testing.T) {
+	router := setupSecurityHeadersRouter()
+
+	req, _ := http.NewRequest(http.MethodGet, "/custom", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if got := w.Header().Values("X-Frame-Options"); len(got) != 1 || got[0] != "SAMEORIGIN" {
+		t.Errorf("Expected X-Frame-Options [SAMEORIGIN], got %v", got)
+	}
+}
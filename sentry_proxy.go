@@ -24,8 +24,9 @@ import (
 
 // SentryProxyHandler handles proxying to Bugsink/Sentry
 type SentryProxyHandler struct {
-	config *config.Config
-	logger *zap.Logger
+	config        *config.Config
+	logger        *zap.Logger
+	exposeDetails bool
 }
 
 // NewSentryProxyHandler creates a new SentryProxyHandler
@@ -36,6 +37,12 @@ func NewSentryProxyHandler(cfg *config.Config, logger *zap.Logger) *SentryProxyH
 	}
 }
 
+// SetExposeErrorDetails includes upstream error text in 502 responses (development only;
+// it can reveal internal hostnames)
+func (h *SentryProxyHandler) SetExposeErrorDetails(expose bool) {
+	h.exposeDetails = expose
+}
+
 // ProxySentryStore handles /sentry/api/{project_id}/store/ endpoint
 // This is the main endpoint for receiving error events
 // Accepts sentry_key from either X-Sentry-Auth header or query string
@@ -162,21 +169,19 @@ func (h *SentryProxyHandler) proxyToBugsink(c *gin.Context) {
 			}
 		}
 
-		req.Header.Set("X-Forwarded-For", c.ClientIP())
-		req.Header.Set("X-Forwarded-Proto", "http")
-		req.Header.Set("X-Real-IP", c.ClientIP())
+		setForwardedHeaders(c, req, false)
 	}
 
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		if handleClientCanceled(c, h.logger, r, err) {
+			return
+		}
 		h.logger.Error("Bugsink proxy error",
 			zap.Error(err),
 			zap.String("target", bugsinkURL),
 			zap.String("path", c.Request.URL.Path),
 		)
-		c.JSON(http.StatusBadGateway, gin.H{
-			"error":   "Bugsink service unavailable",
-			"details": err.Error(),
-		})
+		writeError(c, errUpstreamUnavailable("bugsink", err, h.exposeDetails))
 	}
 
 	if c.Request.Method == "POST" {
@@ -0,0 +1,296 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains the gateway session store used to track issued JWTs.
+//
+// Associated Frontend Files:
+//   - web/app/src/pages/SettingsPage.tsx (security settings - active sessions)
+//
+// The gateway does not access the user database (ADR-0010). Sessions only
+// record which gateway JWTs were issued so they can be listed and revoked;
+// credentials and Authelia sessions remain owned by Authelia.
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Session represents an issued gateway JWT (identified by its jti claim)
+type Session struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"-"`
+	UserAgent string    `json:"user_agent"`
+	IP        string    `json:"ip"`
+	CreatedAt time.Time `json:"created_at"`
+	LastSeen  time.Time `json:"last_seen"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SessionStore persists gateway sessions and the revocation denylist
+type SessionStore interface {
+	// Save stores a newly issued session
+	Save(session Session) error
+	// ListByUser returns the user's active (unexpired, unrevoked) sessions, newest first
+	ListByUser(userID string) ([]Session, error)
+	// Touch updates the last-seen time of a session
+	Touch(id string, at time.Time) error
+	// Revoke denylists a session owned by userID, returning false if no such session exists
+	Revoke(userID, id string) (bool, error)
+	// Replace marks a session owned by userID as refreshed: its token stays valid
+	// until graceUntil and is denylisted afterwards. Returns false if no such
+	// session exists or it was already replaced.
+	Replace(userID, id string, graceUntil time.Time) (bool, error)
+	// IsRevoked reports whether the session has been denylisted
+	IsRevoked(id string) (bool, error)
+}
+
+// MemorySessionStore is an in-process SessionStore (not shared across replicas).
+// With a shared denylist Store, each session's owner and expiry are recorded
+// there too, so any replica can revoke a session and every replica sees it.
+type MemorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]Session
+	revoked  map[string]time.Time // session ID -> token expiry (denylist entry lifetime)
+	replaced map[string]replacedSession
+	denylist Store
+}
+
+// replacedSession is a session whose token was refreshed; the token stays
+// usable until graceUntil so requests already in flight with it do not fail
+type replacedSession struct {
+	graceUntil time.Time
+	expiresAt  time.Time // token expiry (record lifetime)
+}
+
+// NewMemorySessionStore creates an empty MemorySessionStore
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{
+		sessions: make(map[string]Session),
+		revoked:  make(map[string]time.Time),
+		replaced: make(map[string]replacedSession),
+	}
+}
+
+// NewMemorySessionStoreWithDenylist creates a MemorySessionStore that also
+// records revocations in denylist (e.g. a RedisStore shared by all replicas)
+func NewMemorySessionStoreWithDenylist(denylist Store) *MemorySessionStore {
+	s := NewMemorySessionStore()
+	s.denylist = denylist
+	return s
+}
+
+// Save stores a newly issued session
+func (s *MemorySessionStore) Save(session Session) error {
+	if s.denylist != nil {
+		owner := session.UserID + "\n" + strconv.FormatInt(session.ExpiresAt.Unix(), 10)
+		if err := s.denylist.Set(context.Background(), sessionOwnerKey(session.ID), []byte(owner), sessionTTL(session.ExpiresAt)); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked(time.Now())
+	s.sessions[session.ID] = session
+	return nil
+}
+
+// ListByUser returns the user's active sessions, newest first
+func (s *MemorySessionStore) ListByUser(userID string) ([]Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	sessions := make([]Session, 0)
+	for _, session := range s.sessions {
+		if session.UserID == userID && now.Before(session.ExpiresAt) {
+			sessions = append(sessions, session)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+// Touch updates the last-seen time of a session
+func (s *MemorySessionStore) Touch(id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, ok := s.sessions[id]; ok {
+		session.LastSeen = at
+		s.sessions[id] = session
+	}
+	return nil
+}
+
+// Revoke denylists a session owned by userID. Sessions issued by another
+// replica are found through the shared owner record.
+func (s *MemorySessionStore) Revoke(userID, id string) (bool, error) {
+	s.mu.RLock()
+	session, ok := s.sessions[id]
+	s.mu.RUnlock()
+
+	owner, expiresAt := session.UserID, session.ExpiresAt
+	if !ok && s.denylist != nil {
+		var err error
+		if owner, expiresAt, ok, err = s.sharedOwner(id); err != nil {
+			return false, err
+		}
+	}
+	if !ok || owner != userID {
+		return false, nil
+	}
+
+	if s.denylist != nil {
+		// Keep the entry until the token would have expired anyway
+		if err := s.denylist.Set(context.Background(), denylistKey(id), []byte("1"), sessionTTL(expiresAt)); err != nil {
+			return false, err
+		}
+	}
+
+	s.mu.Lock()
+	delete(s.sessions, id)
+	s.revoked[id] = expiresAt
+	s.mu.Unlock()
+	return true, nil
+}
+
+// Replace marks a session owned by userID as refreshed and drops it from the
+// session list. With a shared denylist Store the mark is claimed there, so a
+// session is replaced at most once across replicas.
+func (s *MemorySessionStore) Replace(userID, id string, graceUntil time.Time) (bool, error) {
+	s.mu.RLock()
+	session, ok := s.sessions[id]
+	s.mu.RUnlock()
+
+	owner, expiresAt := session.UserID, session.ExpiresAt
+	if !ok && s.denylist != nil {
+		var err error
+		if owner, expiresAt, ok, err = s.sharedOwner(id); err != nil {
+			return false, err
+		}
+	}
+	if !ok || owner != userID {
+		return false, nil
+	}
+	if graceUntil.After(expiresAt) {
+		graceUntil = expiresAt
+	}
+
+	if s.denylist != nil {
+		grace := strconv.FormatInt(graceUntil.Unix(), 10)
+		claimed, err := s.denylist.SetNX(context.Background(), replacedKey(id), []byte(grace), sessionTTL(expiresAt))
+		if err != nil || !claimed {
+			return false, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, replaced := s.replaced[id]; replaced {
+		return false, nil
+	}
+	delete(s.sessions, id)
+	s.replaced[id] = replacedSession{graceUntil: graceUntil, expiresAt: expiresAt}
+	return true, nil
+}
+
+// sharedOwner reads the owner and expiry recorded in the denylist Store for session id
+func (s *MemorySessionStore) sharedOwner(id string) (string, time.Time, bool, error) {
+	value, ok, err := s.denylist.Get(context.Background(), sessionOwnerKey(id))
+	if err != nil || !ok {
+		return "", time.Time{}, false, err
+	}
+	owner, expiry, found := strings.Cut(string(value), "\n")
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if !found || err != nil {
+		return "", time.Time{}, false, nil
+	}
+	return owner, time.Unix(unix, 0), true, nil
+}
+
+// sessionTTL returns how long records of a token expiring at expiresAt are kept (at least 1s)
+func sessionTTL(expiresAt time.Time) time.Duration {
+	return max(time.Until(expiresAt), time.Second)
+}
+
+// IsRevoked reports whether the session has been denylisted
+func (s *MemorySessionStore) IsRevoked(id string) (bool, error) {
+	now := time.Now()
+	s.mu.RLock()
+	_, revoked := s.revoked[id]
+	replaced, ok := s.replaced[id]
+	s.mu.RUnlock()
+
+	if ok && !now.Before(replaced.graceUntil) {
+		revoked = true
+	}
+	if revoked || s.denylist == nil {
+		return revoked, nil
+	}
+	_, revoked, err := s.denylist.Get(context.Background(), denylistKey(id))
+	if revoked || err != nil {
+		return revoked, err
+	}
+
+	// Replaced through another replica: revoked once the grace period ends
+	value, ok, err := s.denylist.Get(context.Background(), replacedKey(id))
+	if err != nil || !ok {
+		return false, err
+	}
+	graceUntil, err := strconv.ParseInt(string(value), 10, 64)
+	return err == nil && !now.Before(time.Unix(graceUntil, 0)), nil
+}
+
+// denylistKey returns the Store key of a revoked session
+func denylistKey(id string) string {
+	return "denylist:" + id
+}
+
+// sessionOwnerKey returns the Store key of a session's owner record
+func sessionOwnerKey(id string) string {
+	return "session-owner:" + id
+}
+
+// replacedKey returns the Store key recording that a session was refreshed
+func replacedKey(id string) string {
+	return "session-replaced:" + id
+}
+
+// pruneLocked drops expired sessions and denylist and replacement entries for expired tokens
+func (s *MemorySessionStore) pruneLocked(now time.Time) {
+	for id, session := range s.sessions {
+		if !now.Before(session.ExpiresAt) {
+			delete(s.sessions, id)
+		}
+	}
+	for id, expiresAt := range s.revoked {
+		if !now.Before(expiresAt) {
+			delete(s.revoked, id)
+		}
+	}
+	for id, replaced := range s.replaced {
+		if !now.Before(replaced.expiresAt) {
+			delete(s.replaced, id)
+		}
+	}
+}
+
+// newSessionID generates a random session identifier (used as the JWT jti)
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,177 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains the per-service SLO tracker fed by the proxy and reported
+// by the admin API.
+//
+// Associated Frontend Files:
+//   - web/app/src/pages/AdminPage.tsx (service SLOs)
+//
+// Requests are kept in time slots covering a rolling window: slots older than
+// the window are dropped, so availability and latency percentiles always
+// describe the last Window of traffic. A 5xx response counts as an error.
+package handlers
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Defaults for unset SLOConfig fields
+const (
+	defaultSLOWindow = 5 * time.Minute
+	defaultSLOTarget = 0.999
+)
+
+// sloSlots is the number of slots the window is divided into
+const sloSlots = 10
+
+// maxSLOSlotSamples caps the latencies kept per slot for percentiles; further
+// requests still count towards availability
+const maxSLOSlotSamples = 10000
+
+// SLOConfig configures an SLOTracker
+type SLOConfig struct {
+	// Window is the rolling window SLOs are computed over (default 5m)
+	Window time.Duration
+	// Target is the availability objective, e.g. 0.999 (default 0.999)
+	Target float64
+	// ServiceTargets overrides Target by service name
+	ServiceTargets map[string]float64
+}
+
+// SLOReport is a service's SLO over the tracker window
+type SLOReport struct {
+	Requests             int     `json:"requests"`
+	Errors               int     `json:"errors"`
+	Availability         float64 `json:"availability"`
+	Target               float64 `json:"target"`
+	ErrorBudgetRemaining float64 `json:"error_budget_remaining"`
+	P50MS                float64 `json:"p50_ms"`
+	P95MS                float64 `json:"p95_ms"`
+	P99MS                float64 `json:"p99_ms"`
+}
+
+// SLOReporter reports per-service SLOs (implemented by SLOTracker)
+type SLOReporter interface {
+	SLOReport() map[string]SLOReport
+}
+
+// SLOTracker records per-service request outcomes in a rolling window
+type SLOTracker struct {
+	cfg      SLOConfig
+	slotSize time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	services map[string]*[sloSlots]sloSlot
+}
+
+// sloSlot holds the requests of one slot; id identifies the slot's time span
+type sloSlot struct {
+	id        int64
+	requests  int
+	errors    int
+	latencies []time.Duration
+}
+
+// NewSLOTracker creates an SLOTracker, filling in defaults for unset cfg fields
+func NewSLOTracker(cfg SLOConfig) *SLOTracker {
+	if cfg.Window <= 0 {
+		cfg.Window = defaultSLOWindow
+	}
+	if cfg.Target <= 0 || cfg.Target >= 1 {
+		cfg.Target = defaultSLOTarget
+	}
+	return &SLOTracker{
+		cfg:      cfg,
+		slotSize: max(cfg.Window/sloSlots, 1),
+		now:      time.Now,
+		services: make(map[string]*[sloSlots]sloSlot),
+	}
+}
+
+// Record counts a request to service that took latency (nil-safe)
+func (t *SLOTracker) Record(service string, latency time.Duration, failed bool) {
+	if t == nil {
+		return
+	}
+	id := t.now().UnixNano() / int64(t.slotSize)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	slots, ok := t.services[service]
+	if !ok {
+		slots = new([sloSlots]sloSlot)
+		t.services[service] = slots
+	}
+	slot := &slots[id%sloSlots]
+	if slot.id != id {
+		*slot = sloSlot{id: id, latencies: slot.latencies[:0]}
+	}
+	slot.requests++
+	if failed {
+		slot.errors++
+	}
+	if len(slot.latencies) < maxSLOSlotSamples {
+		slot.latencies = append(slot.latencies, latency)
+	}
+}
+
+// SLOReport computes the SLO of every service with requests in the window (nil-safe)
+func (t *SLOTracker) SLOReport() map[string]SLOReport {
+	if t == nil {
+		return map[string]SLOReport{}
+	}
+	oldest := t.now().UnixNano()/int64(t.slotSize) - sloSlots + 1
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	reports := make(map[string]SLOReport, len(t.services))
+	for service, slots := range t.services {
+		var report SLOReport
+		var latencies []time.Duration
+		for i := range slots {
+			if slots[i].id < oldest {
+				continue
+			}
+			report.Requests += slots[i].requests
+			report.Errors += slots[i].errors
+			latencies = append(latencies, slots[i].latencies...)
+		}
+		if report.Requests == 0 {
+			continue
+		}
+
+		report.Target = t.target(service)
+		report.Availability = 1 - float64(report.Errors)/float64(report.Requests)
+		// Share of the allowed errors (1 - Target) not yet spent; negative when overspent
+		report.ErrorBudgetRemaining = 1 - (1-report.Availability)/(1-report.Target)
+
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		report.P50MS = percentileMS(latencies, 0.50)
+		report.P95MS = percentileMS(latencies, 0.95)
+		report.P99MS = percentileMS(latencies, 0.99)
+		reports[service] = report
+	}
+	return reports
+}
+
+// target returns the availability objective of service
+func (t *SLOTracker) target(service string) float64 {
+	if target, ok := t.cfg.ServiceTargets[service]; ok && target > 0 && target < 1 {
+		return target
+	}
+	return t.cfg.Target
+}
+
+// percentileMS returns the nearest-rank percentile p of sorted latencies in milliseconds
+func percentileMS(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	rank = max(rank, 0)
+	return float64(sorted[rank]) / float64(time.Millisecond)
+}
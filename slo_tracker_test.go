@@ -0,0 +1,64 @@
+package handlers_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ugjb/api-gateway/handlers"
+)
+
+// TestSLOTrackerReport verifies availability, percentiles and error budget computed from synthetic requests
+func TestSLOTrackerReport(t *testing.T) {
+	tracker := handlers.NewSLOTracker(handlers.SLOConfig{
+		Window:         time.Minute,
+		Target:         0.98,
+		ServiceTargets: map[string]float64{"auth": 0.9},
+	})
+	now := time.Unix(1_700_000_000, 0)
+	tracker.SetClock(func() time.Time { return now })
+
+	// 100 requests of 1..100ms with one failure, spread over most of the window
+	for i := 1; i <= 100; i++ {
+		now = now.Add(500 * time.Millisecond)
+		tracker.Record("tasks", time.Duration(i)*time.Millisecond, i == 42)
+	}
+	for i := 0; i < 10; i++ {
+		tracker.Record("auth", 20*time.Millisecond, i < 2)
+	}
+
+	reports := tracker.SLOReport()
+	tasks := reports["tasks"]
+	if tasks.Requests != 100 || tasks.Errors != 1 {
+		t.Fatalf("Expected 100 requests and 1 error, got %+v", tasks)
+	}
+	for name, check := range map[string][2]float64{
+		"availability":           {tasks.Availability, 0.99},
+		"target":                 {tasks.Target, 0.98},
+		"error_budget_remaining": {tasks.ErrorBudgetRemaining, 0.5},
+		"p50_ms":                 {tasks.P50MS, 50},
+		"p95_ms":                 {tasks.P95MS, 95},
+		"p99_ms":                 {tasks.P99MS, 99},
+	} {
+		if math.Abs(check[0]-check[1]) > 1e-9 {
+			t.Errorf("Expected tasks %s %v, got %v", name, check[1], check[0])
+		}
+	}
+
+	// 2 errors in 10 requests overspends the 10% budget of the auth override
+	auth := reports["auth"]
+	if math.Abs(auth.Availability-0.8) > 1e-9 || math.Abs(auth.ErrorBudgetRemaining+1) > 1e-9 {
+		t.Errorf("Expected auth availability 0.8 and budget -1, got %+v", auth)
+	}
+
+	// Once a window (plus the partly expired oldest slot) has passed only newer requests count
+	now = now.Add(70 * time.Second)
+	tracker.Record("tasks", 7*time.Millisecond, false)
+	reports = tracker.SLOReport()
+	if tasks := reports["tasks"]; tasks.Requests != 1 || tasks.Availability != 1 || tasks.P99MS != 7 {
+		t.Errorf("Expected only the latest tasks request, got %+v", tasks)
+	}
+	if _, ok := reports["auth"]; ok {
+		t.Errorf("Expected auth to drop out of the report, got %+v", reports["auth"])
+	}
+}
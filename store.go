@@ -0,0 +1,130 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains the pluggable key-value Store shared by gateway features
+// that must agree across replicas (token denylist, rate limits, caches).
+//
+// Associated Frontend Files:
+//   - None (gateway-internal storage)
+//
+// Backends:
+//   - memory: TTLCache in the gateway process (single replica / development)
+//   - redis:  shared by every gateway replica (see store_redis.go)
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Store is a minimal TTL key-value store.
+// A ttl <= 0 stores the key without expiry.
+type Store interface {
+	// Get returns the value of key, with false if it does not exist
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetNX stores value only if key does not exist, reporting whether it was stored
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	// Incr atomically increments the integer at key (missing keys start at 0) and returns the new value
+	Incr(ctx context.Context, key string) (int64, error)
+	// Expire sets the TTL of an existing key
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+}
+
+// StoreConfig selects and configures the Store backend
+type StoreConfig struct {
+	// Backend is "memory" (default) or "redis"
+	Backend string
+	// RedisAddr is the Redis host:port (redis backend only)
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+	// KeyPrefix namespaces gateway keys (e.g. "gateway:")
+	KeyPrefix string
+	// MaxEntries bounds the memory backend (0 means unlimited)
+	MaxEntries int
+}
+
+// NewStore creates the Store selected by cfg.Backend
+func NewStore(cfg StoreConfig) (Store, error) {
+	switch cfg.Backend {
+	case "", "memory":
+		return NewMemoryStore(cfg.MaxEntries), nil
+	case "redis":
+		if cfg.RedisAddr == "" {
+			return nil, fmt.Errorf("store: redis backend requires RedisAddr")
+		}
+		return NewRedisStoreFromConfig(cfg), nil
+	default:
+		return nil, fmt.Errorf("store: unknown backend %q (expected memory or redis)", cfg.Backend)
+	}
+}
+
+// MemoryStore is a Store backed by a TTLCache (not shared across replicas)
+type MemoryStore struct {
+	// mu serializes read-modify-write operations (Incr, SetNX)
+	mu    sync.Mutex
+	cache *TTLCache
+}
+
+// NewMemoryStore creates a MemoryStore holding at most maxEntries keys (0 means unlimited)
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{cache: NewTTLCache(TTLCacheOptions{MaxEntries: maxEntries})}
+}
+
+// Get returns the value of key
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	value, ok := s.cache.Get(key)
+	return value, ok, nil
+}
+
+// Set stores value under key
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache.Set(key, value, ttl)
+	return nil
+}
+
+// SetNX stores value only if key does not exist
+func (s *MemoryStore) SetNX(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.cache.Get(key); ok {
+		return false, nil
+	}
+	s.cache.Set(key, value, ttl)
+	return true, nil
+}
+
+// Incr atomically increments the integer at key, keeping its TTL
+func (s *MemoryStore) Incr(_ context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	if value, ok := s.cache.Get(key); ok {
+		parsed, err := strconv.ParseInt(string(value), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("store: value at %q is not an integer", key)
+		}
+		n = parsed
+	}
+	n++
+
+	encoded := []byte(strconv.FormatInt(n, 10))
+	if !s.cache.Replace(key, encoded) {
+		s.cache.Set(key, encoded, 0)
+	}
+	return n, nil
+}
+
+// Expire sets the TTL of an existing key
+func (s *MemoryStore) Expire(_ context.Context, key string, ttl time.Duration) error {
+	s.cache.Expire(key, ttl)
+	return nil
+}
@@ -0,0 +1,79 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains the Redis Store backend, shared by all gateway replicas.
+//
+// Associated Frontend Files:
+//   - None (gateway-internal storage)
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore is a Store backed by Redis
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore creates a RedisStore using client; keys are namespaced with prefix
+func NewRedisStore(client redis.UniversalClient, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// NewRedisStoreFromConfig creates a RedisStore connected to cfg.RedisAddr
+func NewRedisStoreFromConfig(cfg StoreConfig) *RedisStore {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.RedisAddr,
+		Password: cfg.RedisPassword,
+		DB:       cfg.RedisDB,
+	})
+	return NewRedisStore(client, cfg.KeyPrefix)
+}
+
+// Get returns the value of key
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set stores value under key
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, s.prefix+key, value, redisTTL(ttl)).Err()
+}
+
+// SetNX stores value only if key does not exist
+func (s *RedisStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, s.prefix+key, value, redisTTL(ttl)).Result()
+}
+
+// Incr atomically increments the integer at key
+func (s *RedisStore) Incr(ctx context.Context, key string) (int64, error) {
+	return s.client.Incr(ctx, s.prefix+key).Result()
+}
+
+// Expire sets the TTL of an existing key
+func (s *RedisStore) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return s.client.Persist(ctx, s.prefix+key).Err()
+	}
+	return s.client.Expire(ctx, s.prefix+key, ttl).Err()
+}
+
+// redisTTL maps "no expiry" (ttl <= 0) to go-redis' 0 expiration
+func redisTTL(ttl time.Duration) time.Duration {
+	if ttl < 0 {
+		return 0
+	}
+	return ttl
+}
@@ -0,0 +1,204 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/ugjb/api-gateway/handlers"
+)
+
+// storeBackends returns the Store implementations under test, keyed by name
+func storeBackends(t *testing.T) map[string]handlers.Store {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return map[string]handlers.Store{
+		"memory": handlers.NewMemoryStore(0),
+		"redis":  handlers.NewRedisStore(client, "test:"),
+	}
+}
+
+// TestStoreOperations verifies Store semantics are identical across backends
+func TestStoreOperations(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range storeBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			if ok, _ := store.SetNX(ctx, "lock", []byte("a"), time.Minute); !ok {
+				t.Error("Expected first SetNX to succeed")
+			}
+			if ok, _ := store.SetNX(ctx, "lock", []byte("b"), time.Minute); ok {
+				t.Error("Expected second SetNX to fail")
+			}
+			if value, ok, _ := store.Get(ctx, "lock"); !ok || string(value) != "a" {
+				t.Errorf("Expected 'a', got %q %v", value, ok)
+			}
+
+			for want := int64(1); want <= 3; want++ {
+				if got, err := store.Incr(ctx, "counter"); err != nil || got != want {
+					t.Fatalf("Expected Incr to return %d, got %d (err: %v)", want, got, err)
+				}
+			}
+
+			if _, ok, _ := store.Get(ctx, "missing"); ok {
+				t.Error("Expected missing key to be absent")
+			}
+		})
+	}
+}
+
+// TestRateLimiterStoreBackends verifies the limiter enforces limits on each backend
+func TestRateLimiterStoreBackends(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range storeBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			limiter := handlers.NewRateLimiter(store, 3, time.Minute)
+			for i := 1; i <= 4; i++ {
+				allowed, retryAfter, err := limiter.Allow(ctx, "client-1")
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if allowed != (i <= 3) {
+					t.Errorf("Request %d: expected allowed=%v, got %v", i, i <= 3, allowed)
+				}
+				if retryAfter <= 0 || retryAfter > time.Minute {
+					t.Errorf("Expected retryAfter within the window, got %v", retryAfter)
+				}
+			}
+
+			if allowed, _, _ := limiter.Allow(ctx, "client-2"); !allowed {
+				t.Error("Expected a different key to have its own limit")
+			}
+		})
+	}
+}
+
+// TestSessionDenylistSharedAcrossReplicas verifies revocations work from and are seen by any replica
+func TestSessionDenylistSharedAcrossReplicas(t *testing.T) {
+	for name, store := range storeBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			replicaA := handlers.NewMemorySessionStoreWithDenylist(store)
+			replicaB := handlers.NewMemorySessionStoreWithDenylist(store)
+
+			session := handlers.Session{ID: "jti-" + name, UserID: "jane", ExpiresAt: time.Now().Add(time.Hour)}
+			replicaA.Save(session)
+
+			if revoked, _ := replicaB.IsRevoked(session.ID); revoked {
+				t.Fatal("Expected session not revoked before Revoke")
+			}
+			if ok, err := replicaA.Revoke("jane", session.ID); !ok || err != nil {
+				t.Fatalf("Expected revoke to succeed, got %v (err: %v)", ok, err)
+			}
+			if revoked, err := replicaB.IsRevoked(session.ID); !revoked || err != nil {
+				t.Errorf("Expected other replica to see the revocation, got %v (err: %v)", revoked, err)
+			}
+
+			// A session issued by replica A can be revoked through replica B, by its owner only
+			other := handlers.Session{ID: "jti-other-" + name, UserID: "jane", ExpiresAt: time.Now().Add(time.Hour)}
+			replicaA.Save(other)
+			if ok, err := replicaB.Revoke("mallory", other.ID); ok || err != nil {
+				t.Errorf("Expected revoke by another user to fail, got %v (err: %v)", ok, err)
+			}
+			if ok, err := replicaB.Revoke("jane", other.ID); !ok || err != nil {
+				t.Fatalf("Expected cross-replica revoke to succeed, got %v (err: %v)", ok, err)
+			}
+			if revoked, err := replicaA.IsRevoked(other.ID); !revoked || err != nil {
+				t.Errorf("Expected issuing replica to see the revocation, got %v (err: %v)", revoked, err)
+			}
+		})
+	}
+}
+
+// TestSessionReplaceOnce verifies a session is replaced at most once across replicas and revoked after its grace period
+func TestSessionReplaceOnce(t *testing.T) {
+	for name, store := range storeBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			replicaA := handlers.NewMemorySessionStoreWithDenylist(store)
+			replicaB := handlers.NewMemorySessionStoreWithDenylist(store)
+
+			session := handlers.Session{ID: "jti-replace-" + name, UserID: "jane", ExpiresAt: time.Now().Add(time.Hour)}
+			replicaA.Save(session)
+
+			if ok, err := replicaB.Replace("mallory", session.ID, time.Now().Add(time.Minute)); ok || err != nil {
+				t.Errorf("Expected replace by another user to fail, got %v (err: %v)", ok, err)
+			}
+			if ok, err := replicaB.Replace("jane", session.ID, time.Now().Add(time.Minute)); !ok || err != nil {
+				t.Fatalf("Expected first replace to succeed, got %v (err: %v)", ok, err)
+			}
+			if ok, err := replicaA.Replace("jane", session.ID, time.Now().Add(time.Minute)); ok || err != nil {
+				t.Errorf("Expected second replace to fail, got %v (err: %v)", ok, err)
+			}
+			if revoked, err := replicaA.IsRevoked(session.ID); revoked || err != nil {
+				t.Errorf("Expected replaced session valid during its grace period, got %v (err: %v)", revoked, err)
+			}
+
+			// Without a grace period the replaced token is revoked right away
+			local := handlers.NewMemorySessionStore()
+			local.Save(session)
+			if ok, _ := local.Replace("jane", session.ID, time.Now()); !ok {
+				t.Fatal("Expected replace to succeed")
+			}
+			if revoked, _ := local.IsRevoked(session.ID); !revoked {
+				t.Error("Expected replaced session revoked after its grace period")
+			}
+			if sessions, _ := local.ListByUser("jane"); len(sessions) != 0 {
+				t.Errorf("Expected replaced session no longer listed, got %+v", sessions)
+			}
+		})
+	}
+}
+
+// TestUserRateLimit verifies authenticated users get independent budgets while anonymous traffic shares the IP
+func TestUserRateLimit(t *testing.T) {
+	store := handlers.NewMemoryStore(0)
+	authenticated := handlers.NewRateLimiter(store, 2, time.Minute)
+	anonymous := handlers.NewRateLimiter(store, 1, time.Minute)
+
+	router := gin.New()
+	// Stand-in for the auth middleware, which must run before the limiter
+	router.Use(func(c *gin.Context) {
+		if user := c.GetHeader("X-Test-User"); user != "" {
+			c.Set("user_id", user)
+		}
+	})
+	router.Use(handlers.UserRateLimit(authenticated, anonymous))
+	router.GET("/api/v1/tasks", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	get := func(user string) int {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+		req.RemoteAddr = "203.0.113.7:1234"
+		if user != "" {
+			req.Header.Set("X-Test-User", user)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for _, user := range []string{"jane", "john"} {
+		for i := 1; i <= 3; i++ {
+			want := http.StatusOK
+			if i > 2 {
+				want = http.StatusTooManyRequests
+			}
+			if got := get(user); got != want {
+				t.Errorf("%s request %d: expected status %d, got %d", user, i, want, got)
+			}
+		}
+	}
+
+	if got := get(""); got != http.StatusOK {
+		t.Errorf("Expected first anonymous request allowed, got %d", got)
+	}
+	if got := get(""); got != http.StatusTooManyRequests {
+		t.Errorf("Expected anonymous requests to share the IP budget, got %d", got)
+	}
+}
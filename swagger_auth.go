@@ -0,0 +1,80 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file implements the optional authentication gate in front of the API
+// docs (Swagger UI and the OpenAPI spec).
+//
+// Associated Frontend Files:
+//   - None (consumed by API tooling, not the web app)
+//
+// The docs are open by default for development; production enables
+// DocsAuthConfig.Required so they are only served to admins.
+package handlers
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenValidator validates gateway JWTs (implemented by AutheliaHandler)
+type TokenValidator interface {
+	ValidateToken(tokenString string) (*Claims, error)
+}
+
+// DocsAuthConfig configures the DocsAuth middleware
+type DocsAuthConfig struct {
+	// Required toggles the gate; when false the docs are public
+	Required bool
+	// BasicUsername and BasicPassword, when both set, also grant access via HTTP basic auth
+	BasicUsername string
+	BasicPassword string
+}
+
+// DocsAuth returns a middleware guarding /swagger/* and /openapi.* when
+// cfg.Required is set. Access is granted to an admin session already
+// authenticated by the auth middleware, an admin Bearer JWT validated by
+// tokens (may be nil), or the configured basic auth credentials. Anything else
+// gets 401; an authenticated non-admin gets 403.
+func DocsAuth(cfg DocsAuthConfig, tokens TokenValidator) gin.HandlerFunc {
+	basicEnabled := cfg.BasicUsername != "" && cfg.BasicPassword != ""
+
+	return func(c *gin.Context) {
+		if !cfg.Required {
+			c.Next()
+			return
+		}
+
+		if c.GetString("user_id") == "" && tokens != nil {
+			if tokenString, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+				if claims, err := tokens.ValidateToken(tokenString); err == nil {
+					c.Set("user_id", claims.UserID)
+					c.Set("email", claims.Email)
+					c.Set("roles", claims.Roles)
+				}
+			}
+		}
+		if c.GetString("user_id") != "" {
+			if requireAdmin(c) {
+				c.Next()
+				return
+			}
+			c.Abort()
+			return
+		}
+
+		if basicEnabled {
+			if user, pass, ok := c.Request.BasicAuth(); ok &&
+				subtle.ConstantTimeCompare([]byte(user), []byte(cfg.BasicUsername)) == 1 &&
+				subtle.ConstantTimeCompare([]byte(pass), []byte(cfg.BasicPassword)) == 1 {
+				c.Next()
+				return
+			}
+			// Lets browsers prompt for the credentials
+			c.Header("WWW-Authenticate", `Basic realm="API docs", charset="UTF-8"`)
+		}
+
+		sendUnauthorizedError(c)
+		c.Abort()
+	}
+}
@@ -1,14 +1,19 @@
 package handlers_test
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-yaml"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/swaggo/swag"
 	_ "github.com/ugjb/api-gateway/docs"
+	"github.com/ugjb/api-gateway/handlers"
 )
 
 func init() {
@@ -19,8 +24,9 @@ func init() {
 func setupSwaggerRouter() *gin.Engine {
 	router := gin.New()
 
-	// Swagger documentation endpoint
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// Swagger documentation endpoint (with /swagger/doc.yaml)
+	router.GET("/swagger/*any", handlers.WithOpenAPIYAML(ginSwagger.WrapHandler(swaggerFiles.Handler)))
+	router.GET("/openapi.yaml", handlers.OpenAPIYAML())
 
 	// OpenAPI JSON redirect endpoint
 	router.GET("/openapi.json", func(c *gin.Context) {
@@ -80,3 +86,160 @@ func TestSwaggerStaticAssets(t *testing.T) {
 		t.Errorf("Expected status %d for swagger-ui-bundle.js, got %d", http.StatusOK, w.Code)
 	}
 }
+
+// TestOpenAPIYAMLEndpoints verifies the YAML spec parses and matches the JSON spec
+func TestOpenAPIYAMLEndpoints(t *testing.T) {
+	router := setupSwaggerRouter()
+
+	doc, err := swag.ReadDoc()
+	if err != nil {
+		t.Fatalf("Failed to read registered spec: %v", err)
+	}
+	var jsonSpec struct {
+		Info struct {
+			Title string `json:"title"`
+		} `json:"info"`
+	}
+	if err := json.Unmarshal([]byte(doc), &jsonSpec); err != nil {
+		t.Fatalf("Failed to parse JSON spec: %v", err)
+	}
+
+	for _, path := range []string{"/swagger/doc.yaml", "/openapi.yaml"} {
+		t.Run(path, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/x-yaml" {
+				t.Errorf("Expected Content-Type 'application/x-yaml', got '%s'", ct)
+			}
+
+			var spec struct {
+				Info struct {
+					Title string `yaml:"title"`
+				} `yaml:"info"`
+			}
+			if err := yaml.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+				t.Fatalf("Expected valid YAML, got error: %v", err)
+			}
+			if spec.Info.Title == "" || spec.Info.Title != jsonSpec.Info.Title {
+				t.Errorf("Expected info.title '%s', got '%s'", jsonSpec.Info.Title, spec.Info.Title)
+			}
+		})
+	}
+}
+
+// TestSpecCORS verifies allowed origins may fetch the spec while the UI stays same-origin
+func TestSpecCORS(t *testing.T) {
+	router := gin.New()
+	cors := handlers.SpecCORS(handlers.SpecCORSConfig{AllowedOrigins: []string{"https://docs.example.com"}})
+	router.GET("/swagger/*any", cors, handlers.WithOpenAPIYAML(ginSwagger.WrapHandler(swaggerFiles.Handler)))
+	router.OPTIONS("/swagger/*any", cors)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		origin     string
+		wantOrigin string
+	}{
+		{"allowed origin", http.MethodGet, "/swagger/doc.json", "https://docs.example.com", "https://docs.example.com"},
+		{"allowed origin yaml", http.MethodGet, "/swagger/doc.yaml", "https://docs.example.com", "https://docs.example.com"},
+		{"preflight", http.MethodOptions, "/swagger/doc.json", "https://docs.example.com", "https://docs.example.com"},
+		{"other origin", http.MethodGet, "/swagger/doc.json", "https://evil.test", ""},
+		{"swagger UI", http.MethodGet, "/swagger/index.html", "https://docs.example.com", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Origin", tt.origin)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Expected Access-Control-Allow-Origin '%s', got '%s'", tt.wantOrigin, got)
+			}
+			if tt.method == http.MethodOptions && w.Code != http.StatusNoContent {
+				t.Errorf("Expected status %d for preflight, got %d", http.StatusNoContent, w.Code)
+			}
+		})
+	}
+}
+
+// fakeTokenValidator accepts "admin-token" and "user-token"
+type fakeTokenValidator struct{}
+
+func (fakeTokenValidator) ValidateToken(tokenString string) (*handlers.Claims, error) {
+	switch tokenString {
+	case "admin-token":
+		return &handlers.Claims{UserID: "admin-1", Roles: []string{"admin"}}, nil
+	case "user-token":
+		return &handlers.Claims{UserID: "user-1", Roles: []string{"user"}}, nil
+	}
+	return nil, errors.New("invalid token")
+}
+
+// setupDocsAuthRouter creates a swagger router behind DocsAuth
+func setupDocsAuthRouter(cfg handlers.DocsAuthConfig) *gin.Engine {
+	router := gin.New()
+	docsAuth := handlers.DocsAuth(cfg, fakeTokenValidator{})
+	router.GET("/swagger/*any", docsAuth, handlers.WithOpenAPIYAML(ginSwagger.WrapHandler(swaggerFiles.Handler)))
+	router.GET("/openapi.json", docsAuth, func(c *gin.Context) {
+		c.Redirect(http.StatusMovedPermanently, "/swagger/doc.json")
+	})
+	return router
+}
+
+// TestDocsAuth verifies the docs require admin credentials when protected
+func TestDocsAuth(t *testing.T) {
+	protected := handlers.DocsAuthConfig{Required: true, BasicUsername: "docs", BasicPassword: "s3cret"}
+
+	tests := []struct {
+		name       string
+		cfg        handlers.DocsAuthConfig
+		path       string
+		setAuth    func(req *http.Request)
+		wantStatus int
+	}{
+		{"open mode", handlers.DocsAuthConfig{}, "/swagger/doc.yaml", nil, http.StatusOK},
+		{"open mode openapi.json", handlers.DocsAuthConfig{}, "/openapi.json", nil, http.StatusMovedPermanently},
+		{"no credentials", protected, "/swagger/doc.yaml", nil, http.StatusUnauthorized},
+		{"no credentials openapi.json", protected, "/openapi.json", nil, http.StatusUnauthorized},
+		{"basic auth", protected, "/swagger/doc.yaml", func(req *http.Request) {
+			req.SetBasicAuth("docs", "s3cret")
+		}, http.StatusOK},
+		{"wrong basic auth", protected, "/swagger/doc.yaml", func(req *http.Request) {
+			req.SetBasicAuth("docs", "wrong")
+		}, http.StatusUnauthorized},
+		{"admin token", protected, "/openapi.json", func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer admin-token")
+		}, http.StatusMovedPermanently},
+		{"invalid token", protected, "/swagger/doc.yaml", func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer bogus")
+		}, http.StatusUnauthorized},
+		{"non-admin token", protected, "/swagger/doc.yaml", func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer user-token")
+		}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupDocsAuthRouter(tt.cfg)
+			req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.setAuth != nil {
+				tt.setAuth(req)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected WWW-Authenticate header on 401")
+			}
+		})
+	}
+}
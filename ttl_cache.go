@@ -0,0 +1,260 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains TTLCache, the shared in-memory TTL + LRU store used as
+// the default backing store for gateway-local state (response caching,
+// idempotency keys, rate limits).
+//
+// Associated Frontend Files:
+//   - None (gateway-internal storage)
+//
+// TTLCache is per process and not shared across replicas.
+package handlers
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TTLCacheOptions configures a TTLCache
+type TTLCacheOptions struct {
+	// MaxEntries evicts least recently used entries beyond this count (0 means unlimited)
+	MaxEntries int
+	// MaxBytes evicts least recently used entries beyond this total key+value size (0 means unlimited)
+	MaxBytes int64
+	// DefaultTTL applies when Set is called with ttl <= 0 (0 means entries never expire)
+	DefaultTTL time.Duration
+}
+
+// TTLCacheStats reports cache effectiveness counters
+type TTLCacheStats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"`
+	Entries   int   `json:"entries"`
+	Bytes     int64 `json:"bytes"`
+}
+
+// TTLCache is a concurrency-safe byte cache with per-entry TTL and LRU eviction.
+// Expired entries are removed lazily on access and when making room.
+type TTLCache struct {
+	mu      sync.Mutex
+	options TTLCacheOptions
+	lru     *list.List // front = most recently used
+	items   map[string]*list.Element
+	bytes   int64
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+type ttlCacheEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // zero means no expiry
+}
+
+func (e *ttlCacheEntry) size() int64 {
+	return int64(len(e.key) + len(e.value))
+}
+
+func (e *ttlCacheEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// NewTTLCache creates an empty TTLCache
+func NewTTLCache(opts TTLCacheOptions) *TTLCache {
+	return &TTLCache{
+		options: opts,
+		lru:     list.New(),
+		items:   make(map[string]*list.Element),
+	}
+}
+
+// Get returns a copy of the value stored under key
+func (c *TTLCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	entry := elem.Value.(*ttlCacheEntry)
+	if entry.expired(time.Now()) {
+		c.removeLocked(elem)
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+	c.hits.Add(1)
+	return append([]byte(nil), entry.value...), true
+}
+
+// Set stores a copy of value under key for ttl (ttl <= 0 uses DefaultTTL).
+// Values larger than MaxBytes are not stored.
+func (c *TTLCache) Set(key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.options.DefaultTTL
+	}
+	entry := &ttlCacheEntry{key: key, value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeLocked(elem)
+	}
+	if c.options.MaxBytes > 0 && entry.size() > c.options.MaxBytes {
+		return
+	}
+
+	c.items[key] = c.lru.PushFront(entry)
+	c.bytes += entry.size()
+	c.evictLocked()
+}
+
+// Replace updates the value of an existing, unexpired key keeping its expiry.
+// Returns false if the key is not present.
+func (c *TTLCache) Replace(key string, value []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok || elem.Value.(*ttlCacheEntry).expired(time.Now()) {
+		return false
+	}
+
+	entry := elem.Value.(*ttlCacheEntry)
+	c.bytes += int64(len(value) - len(entry.value))
+	entry.value = append([]byte(nil), value...)
+	c.lru.MoveToFront(elem)
+	c.evictLocked()
+	return true
+}
+
+// Expire sets a new TTL on an existing, unexpired key (ttl <= 0 removes the expiry).
+// Returns false if the key is not present.
+func (c *TTLCache) Expire(key string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok || elem.Value.(*ttlCacheEntry).expired(time.Now()) {
+		return false
+	}
+
+	entry := elem.Value.(*ttlCacheEntry)
+	entry.expiresAt = time.Time{}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	return true
+}
+
+// Delete removes key, reporting whether it was present
+func (c *TTLCache) Delete(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if ok {
+		c.removeLocked(elem)
+	}
+	return ok
+}
+
+// DeleteFunc removes every entry whose key matches and returns how many were removed
+func (c *TTLCache) DeleteFunc(match func(key string) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for elem := c.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		if match(elem.Value.(*ttlCacheEntry).key) {
+			c.removeLocked(elem)
+			removed++
+		}
+		elem = prev
+	}
+	return removed
+}
+
+// Len returns the number of stored entries, including expired ones not yet removed
+func (c *TTLCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// DeleteExpired removes all expired entries and returns how many were removed
+func (c *TTLCache) DeleteExpired() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.deleteExpiredLocked(time.Now())
+}
+
+// Stats returns a snapshot of the cache counters
+func (c *TTLCache) Stats() TTLCacheStats {
+	c.mu.Lock()
+	entries, bytes := c.lru.Len(), c.bytes
+	c.mu.Unlock()
+
+	return TTLCacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Entries:   entries,
+		Bytes:     bytes,
+	}
+}
+
+// evictLocked drops expired entries, then least recently used ones, until within limits
+func (c *TTLCache) evictLocked() {
+	if !c.overLimitLocked() {
+		return
+	}
+	c.deleteExpiredLocked(time.Now())
+
+	for c.overLimitLocked() {
+		oldest := c.lru.Back()
+		if oldest == nil {
+			return
+		}
+		c.removeLocked(oldest)
+		c.evictions.Add(1)
+	}
+}
+
+func (c *TTLCache) overLimitLocked() bool {
+	return (c.options.MaxEntries > 0 && c.lru.Len() > c.options.MaxEntries) ||
+		(c.options.MaxBytes > 0 && c.bytes > c.options.MaxBytes)
+}
+
+func (c *TTLCache) deleteExpiredLocked(now time.Time) int {
+	removed := 0
+	for elem := c.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		if elem.Value.(*ttlCacheEntry).expired(now) {
+			c.removeLocked(elem)
+			removed++
+		}
+		elem = prev
+	}
+	return removed
+}
+
+func (c *TTLCache) removeLocked(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*ttlCacheEntry)
+	delete(c.items, entry.key)
+	c.bytes -= entry.size()
+}
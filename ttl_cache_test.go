@@ -0,0 +1,126 @@
+package handlers_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ugjb/api-gateway/handlers"
+)
+
+// TestTTLCacheExpiry verifies entries disappear after their TTL
+func TestTTLCacheExpiry(t *testing.T) {
+	cache := handlers.NewTTLCache(handlers.TTLCacheOptions{DefaultTTL: time.Hour})
+	cache.Set("short", []byte("a"), 20*time.Millisecond)
+	cache.Set("long", []byte("b"), 0)
+
+	if v, ok := cache.Get("short"); !ok || string(v) != "a" {
+		t.Fatalf("Expected 'short' before expiry, got %q %v", v, ok)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+
+	if _, ok := cache.Get("short"); ok {
+		t.Error("Expected 'short' to be expired")
+	}
+	if _, ok := cache.Get("long"); !ok {
+		t.Error("Expected 'long' to use the default TTL and still be present")
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("Expected 2 hits and 1 miss, got %+v", stats)
+	}
+}
+
+// TestTTLCacheLRUEviction verifies least recently used entries are evicted under size pressure
+func TestTTLCacheLRUEviction(t *testing.T) {
+	t.Run("max entries", func(t *testing.T) {
+		cache := handlers.NewTTLCache(handlers.TTLCacheOptions{MaxEntries: 2})
+		cache.Set("a", []byte("1"), 0)
+		cache.Set("b", []byte("2"), 0)
+		cache.Get("a") // "b" is now least recently used
+		cache.Set("c", []byte("3"), 0)
+
+		if _, ok := cache.Get("b"); ok {
+			t.Error("Expected 'b' evicted as least recently used")
+		}
+		for _, key := range []string{"a", "c"} {
+			if _, ok := cache.Get(key); !ok {
+				t.Errorf("Expected '%s' to remain", key)
+			}
+		}
+		if got := cache.Stats().Evictions; got != 1 {
+			t.Errorf("Expected 1 eviction, got %d", got)
+		}
+	})
+
+	t.Run("max bytes", func(t *testing.T) {
+		cache := handlers.NewTTLCache(handlers.TTLCacheOptions{MaxBytes: 25})
+		cache.Set("k1", make([]byte, 10), 0)
+		cache.Set("k2", make([]byte, 10), 0)
+		cache.Set("k3", make([]byte, 10), 0)
+
+		stats := cache.Stats()
+		if stats.Entries != 2 || stats.Bytes > 25 {
+			t.Errorf("Expected 2 entries within 25 bytes, got %+v", stats)
+		}
+		if _, ok := cache.Get("k1"); ok {
+			t.Error("Expected 'k1' evicted")
+		}
+
+		cache.Set("huge", make([]byte, 100), 0)
+		if _, ok := cache.Get("huge"); ok {
+			t.Error("Expected value larger than MaxBytes not to be stored")
+		}
+	})
+}
+
+// TestTTLCacheConcurrentAccess exercises the cache from many goroutines (run with -race)
+func TestTTLCacheConcurrentAccess(t *testing.T) {
+	cache := handlers.NewTTLCache(handlers.TTLCacheOptions{MaxEntries: 50, DefaultTTL: time.Second})
+
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := fmt.Sprintf("key-%d", (g*i)%100)
+				cache.Set(key, []byte(key), 0)
+				if v, ok := cache.Get(key); ok && string(v) != key {
+					t.Errorf("Expected value %q, got %q", key, v)
+				}
+				if i%10 == 0 {
+					cache.Delete(key)
+				}
+				if i%100 == 0 {
+					cache.DeleteFunc(func(k string) bool { return k == key })
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if got := cache.Len(); got > 50 {
+		t.Errorf("Expected at most 50 entries, got %d", got)
+	}
+}
+
+// TestTTLCacheDeleteFunc verifies only matching keys are removed and counted
+func TestTTLCacheDeleteFunc(t *testing.T) {
+	cache := handlers.NewTTLCache(handlers.TTLCacheOptions{})
+	for _, key := range []string{"tasks/1", "tasks/2", "projects/1"} {
+		cache.Set(key, []byte("v"), 0)
+	}
+
+	removed := cache.DeleteFunc(func(key string) bool { return strings.HasPrefix(key, "tasks/") })
+	if removed != 2 {
+		t.Errorf("Expected 2 removed entries, got %d", removed)
+	}
+	if _, ok := cache.Get("projects/1"); !ok || cache.Len() != 1 {
+		t.Errorf("Expected only projects/1 to remain, got %d entries", cache.Len())
+	}
+}
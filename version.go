@@ -0,0 +1,57 @@
+// Package handlers provides HTTP request handlers for the API Gateway.
+//
+// This file contains build metadata injected at link time.
+//
+// Associated Frontend Files:
+//   - None (build info for operators and uptime tooling)
+//
+// Build with:
+//   go build -ldflags "\
+//     -X github.com/ugjb/api-gateway/handlers.Version=1.4.0 \
+//     -X github.com/ugjb/api-gateway/handlers.Commit=$(git rev-parse --short HEAD) \
+//     -X github.com/ugjb/api-gateway/handlers.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package handlers
+
+import (
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Build metadata, set via -ldflags "-X ..." at build time
+var (
+	Version   = "unknown"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// BuildInfo describes the running gateway binary
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// GetBuildInfo returns the build metadata of the running binary
+func GetBuildInfo() BuildInfo {
+	return BuildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// Version returns build information
+// @Summary Build version
+// @Description Returns version, commit, build time and Go version of the API Gateway
+// @Tags Health
+// @Accept json
+// @Produce json
+// @Success 200 {object} BuildInfo "Build information"
+// @Router /api/v1/public/version [get]
+func (h *HealthHandler) Version(c *gin.Context) {
+	c.JSON(http.StatusOK, GetBuildInfo())
+}